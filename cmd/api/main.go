@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"time"
+	// Event time zones must resolve even where the system has no tz database.
+	_ "time/tzdata"
 
 	"EventsAPI/docs"
 	"EventsAPI/internal/config"
 	"EventsAPI/internal/delivery/http/handlers"
 	"EventsAPI/internal/delivery/http/routes"
+	"EventsAPI/internal/domain/services"
 	"EventsAPI/internal/infrastructure/database"
+	"EventsAPI/internal/infrastructure/exports"
+	"EventsAPI/internal/infrastructure/invoices"
+	"EventsAPI/internal/infrastructure/notifications"
+	"EventsAPI/internal/infrastructure/oidc"
+	"EventsAPI/internal/infrastructure/payments"
 	"EventsAPI/internal/infrastructure/repositories"
 	"EventsAPI/internal/usecases"
+	"EventsAPI/pkg/utils"
 
 	"gorm.io/gorm"
 )
@@ -33,8 +45,14 @@ import (
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+// @description Type "ApiKey" followed by a space and the API key.
 var configs *config.Config
 var db *gorm.DB
+var jwtManager *utils.JWTManager
 
 func init() {
 	// Load configuration
@@ -48,6 +66,12 @@ func init() {
 	docs.SwaggerInfo.Host = "localhost:" + configs.Server.Port
 	docs.SwaggerInfo.BasePath = "/api/v1"
 
+	// Load JWT signing and verification keys
+	jwtManager, err = newJWTManager(configs.JWT)
+	if err != nil {
+		log.Fatal("Failed to load JWT keys:", err)
+	}
+
 	// Initialize database
 	db, err = database.NewPostgresConnection(configs)
 	if err != nil {
@@ -55,25 +79,174 @@ func init() {
 	}
 }
 
+func newJWTManager(cfg config.JWTConfig) (*utils.JWTManager, error) {
+	expiration, err := time.ParseDuration(cfg.Expiration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_EXPIRATION: %w", err)
+	}
+
+	signingKey, err := utils.LoadJWTKey(cfg.SigningKeyID, cfg.SigningKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var verificationKeys []*utils.JWTKey
+	for kid, path := range cfg.VerificationKeyFiles {
+		key, err := utils.LoadJWTKey(kid, path)
+		if err != nil {
+			return nil, err
+		}
+		verificationKeys = append(verificationKeys, key)
+	}
+
+	return utils.NewJWTManager(utils.JWTOptions{
+		SigningKey:       signingKey,
+		VerificationKeys: verificationKeys,
+		Algorithms:       cfg.Algorithms,
+		Issuer:           cfg.Issuer,
+		Audience:         cfg.Audience,
+		Expiration:       expiration,
+	})
+}
+
+func newPaymentProvider(cfg config.PaymentConfig) (services.PaymentProvider, error) {
+	switch cfg.Provider {
+	case "fake":
+		return payments.NewFakeProvider(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown PAYMENT_PROVIDER %q", cfg.Provider)
+	}
+}
+
+// failInterruptedImports marks the attendee imports that stopped making
+// progress as failed at startup and then every interval.
+func failInterruptedImports(importUseCase *usecases.AttendeeImportUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := time.Now(); ; now = <-ticker.C {
+		interrupted, err := importUseCase.FailInterruptedImports(context.Background(), now)
+		if err != nil {
+			log.Printf("Failed to check for interrupted attendee imports: %v", err)
+		} else if interrupted > 0 {
+			log.Printf("Marked %d interrupted attendee imports as failed", interrupted)
+		}
+	}
+}
+
+// expireReservations releases the expired orders every interval.
+func expireReservations(orderUseCase *usecases.OrderUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		expired, err := orderUseCase.ExpireReservations(context.Background(), now)
+		if err != nil {
+			log.Printf("Failed to expire order reservations: %v", err)
+			continue
+		}
+		if expired > 0 {
+			log.Printf("Released the seats of %d expired orders", expired)
+		}
+	}
+}
+
 func main() {
 	// Initialize repositories
 	userRepo := repositories.NewPostgresUserRepository(db)
 	eventRepo := repositories.NewPostgresEventRepository(db)
 	attendeeRepo := repositories.NewPostgresAttendeeRepository(db)
+	identityRepo := repositories.NewPostgresUserIdentityRepository(db)
+	oidcStateRepo := repositories.NewPostgresOIDCLoginStateRepository(db)
+	apiKeyRepo := repositories.NewPostgresAPIKeyRepository(db)
+	organizationRepo := repositories.NewPostgresOrganizationRepository(db)
+	organizationInvitationRepo := repositories.NewPostgresOrganizationInvitationRepository(db)
+	collaboratorRepo := repositories.NewPostgresEventCollaboratorRepository(db)
+	inviteLinkRepo := repositories.NewPostgresEventInviteLinkRepository(db)
+	eventInvitationRepo := repositories.NewPostgresEventInvitationRepository(db)
+	registrationQuestionRepo := repositories.NewPostgresRegistrationQuestionRepository(db)
+	venueRepo := repositories.NewPostgresVenueRepository(db)
+	categoryRepo := repositories.NewPostgresCategoryRepository(db)
+	ticketTypeRepo := repositories.NewPostgresTicketTypeRepository(db)
+	orderRepo := repositories.NewPostgresOrderRepository(db)
+	refundRepo := repositories.NewPostgresRefundRepository(db)
+	promoCodeRepo := repositories.NewPostgresPromoCodeRepository(db)
+	invoiceRepo := repositories.NewPostgresInvoiceRepository(db)
+	attendeeImportRepo := repositories.NewPostgresAttendeeImportRepository(db)
+
+	// Initialize services
+	notifier := notifications.NewLogNotifier()
+	invoiceRenderer := invoices.NewPDFRenderer()
+	paymentProvider, err := newPaymentProvider(configs.Payment)
+	if err != nil {
+		log.Fatal("Failed to set up payments:", err)
+	}
+
+	// Initialize identity providers
+	var identityProviders []services.IdentityProvider
+	for _, providerConfig := range configs.OIDC {
+		identityProviders = append(identityProviders, oidc.NewProvider(providerConfig, nil))
+	}
 
 	// Initialize use cases
-	authUseCase := usecases.NewAuthUseCase(userRepo, configs)
-	eventUseCase := usecases.NewEventUseCase(eventRepo, userRepo)
-	attendeeUseCase := usecases.NewAttendeeUseCase(attendeeRepo, eventRepo)
+	authUseCase := usecases.NewAuthUseCase(userRepo, jwtManager)
+	oidcUseCase := usecases.NewOIDCUseCase(userRepo, identityRepo, oidcStateRepo, identityProviders, jwtManager)
+	eventAuthorizer := usecases.NewEventAuthorizer(organizationRepo, collaboratorRepo)
+	eventInvitationUseCase := usecases.NewEventInvitationUseCase(inviteLinkRepo, eventInvitationRepo, eventRepo, userRepo, attendeeRepo, eventAuthorizer, jwtManager, notifier)
+	venueUseCase := usecases.NewVenueUseCase(venueRepo, eventAuthorizer)
+	categoryUseCase := usecases.NewCategoryUseCase(categoryRepo, userRepo)
+	invoiceUseCase := usecases.NewInvoiceUseCase(invoiceRepo, orderRepo, organizationRepo, userRepo, invoiceRenderer)
+	refundUseCase := usecases.NewRefundUseCase(refundRepo, orderRepo, eventAuthorizer, invoiceUseCase, paymentProvider, notifier)
+	eventUseCase := usecases.NewEventUseCase(eventRepo, ticketTypeRepo, attendeeRepo, userRepo, eventAuthorizer, eventInvitationUseCase, venueUseCase, categoryUseCase, refundUseCase)
+	registrationFormUseCase := usecases.NewRegistrationFormUseCase(registrationQuestionRepo, eventRepo, eventAuthorizer, eventInvitationUseCase)
+	ticketTypeUseCase := usecases.NewTicketTypeUseCase(ticketTypeRepo, attendeeRepo, eventRepo, eventAuthorizer, eventInvitationUseCase, venueUseCase)
+	attendeeUseCase := usecases.NewAttendeeUseCase(attendeeRepo, eventRepo, eventAuthorizer, eventInvitationUseCase, registrationFormUseCase, ticketTypeUseCase, refundUseCase, notifier)
+	attendeeExportUseCase := usecases.NewAttendeeExportUseCase(attendeeRepo, eventRepo, registrationQuestionRepo, eventAuthorizer, []services.TableFormat{
+		exports.NewCSVFormat(),
+		exports.NewXLSXFormat(),
+		exports.NewJSONFormat(),
+	})
+	attendeeImportUseCase := usecases.NewAttendeeImportUseCase(attendeeImportRepo, attendeeRepo, eventRepo, userRepo, eventAuthorizer, attendeeUseCase, ticketTypeUseCase, oidcUseCase, notifier)
+	promoCodeUseCase := usecases.NewPromoCodeUseCase(promoCodeRepo, eventRepo, ticketTypeUseCase, eventAuthorizer)
+	orderUseCase := usecases.NewOrderUseCase(orderRepo, eventRepo, attendeeUseCase, eventAuthorizer, refundUseCase, promoCodeUseCase, invoiceUseCase, paymentProvider, notifier, time.Duration(configs.Payment.ReservationMinutes)*time.Minute)
+	apiKeyUseCase := usecases.NewAPIKeyUseCase(apiKeyRepo)
+	organizationUseCase := usecases.NewOrganizationUseCase(organizationRepo, organizationInvitationRepo, eventRepo, userRepo, notifier)
+	collaboratorUseCase := usecases.NewEventCollaboratorUseCase(collaboratorRepo, eventRepo, userRepo, eventAuthorizer, notifier)
+	userUseCase := usecases.NewUserUseCase(userRepo)
+
+	// Promote the configured site administrators
+	admins, err := userUseCase.SeedAdmins(context.Background(), configs.AdminEmails)
+	if err != nil {
+		log.Fatal("Failed to seed site administrators:", err)
+	}
+	if admins > 0 {
+		log.Printf("Made %d accounts site administrators", admins)
+	}
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authUseCase)
+	oidcHandler := handlers.NewOIDCHandler(oidcUseCase)
 	eventHandler := handlers.NewEventHandler(eventUseCase)
-	attendeeHandler := handlers.NewAttendeeHandler(attendeeUseCase)
+	attendeeHandler := handlers.NewAttendeeHandler(attendeeUseCase, attendeeExportUseCase, attendeeImportUseCase)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyUseCase)
+	organizationHandler := handlers.NewOrganizationHandler(organizationUseCase)
+	collaboratorHandler := handlers.NewEventCollaboratorHandler(collaboratorUseCase)
+	eventInvitationHandler := handlers.NewEventInvitationHandler(eventInvitationUseCase)
+	registrationFormHandler := handlers.NewRegistrationFormHandler(registrationFormUseCase)
+	venueHandler := handlers.NewVenueHandler(venueUseCase)
+	categoryHandler := handlers.NewCategoryHandler(categoryUseCase)
+	ticketTypeHandler := handlers.NewTicketTypeHandler(ticketTypeUseCase)
+	orderHandler := handlers.NewOrderHandler(orderUseCase, refundUseCase)
+	promoCodeHandler := handlers.NewPromoCodeHandler(promoCodeUseCase)
+	userHandler := handlers.NewUserHandler(userUseCase)
 	healthHandler := handlers.NewHealthHandler()
 
 	// Setup routes
-	router := routes.SetupRoutes(configs, authHandler, eventHandler, attendeeHandler, healthHandler)
+	router := routes.SetupRoutes(configs, jwtManager, apiKeyUseCase, authHandler, oidcHandler, eventHandler, attendeeHandler, apiKeyHandler, organizationHandler, collaboratorHandler, eventInvitationHandler, registrationFormHandler, venueHandler, categoryHandler, ticketTypeHandler, orderHandler, promoCodeHandler, userHandler, healthHandler)
+
+	// Release the seats of the orders left unpaid
+	go expireReservations(orderUseCase, time.Minute)
+
+	// Fail the attendee imports that stopped, e.g. with a previous run
+	go failInterruptedImports(attendeeImportUseCase, 5*time.Minute)
 
 	// Start server
 	log.Printf("🚀 Server starting on port %s", configs.Server.Port)
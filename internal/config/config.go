@@ -3,14 +3,20 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DB     DatabaseConfig
-	Server ServerConfig
-	JWT    JWTConfig
+	DB      DatabaseConfig
+	Server  ServerConfig
+	JWT     JWTConfig
+	OIDC    []OIDCProviderConfig
+	Payment PaymentConfig
+	// AdminEmails are the accounts made site administrators at startup.
+	AdminEmails []string
 }
 
 type DatabaseConfig struct {
@@ -27,8 +33,34 @@ type ServerConfig struct {
 }
 
 type JWTConfig struct {
-	Secret     string
-	Expiration string
+	SigningKeyID   string
+	SigningKeyFile string
+	// VerificationKeyFiles maps a key id to a PEM file holding a key that is
+	// still accepted for validation (e.g. the previous signing key).
+	VerificationKeyFiles map[string]string
+	Algorithms           []string
+	Issuer               string
+	Audience             []string
+	Expiration           string
+}
+
+// OIDCProviderConfig describes an external OpenID Connect identity provider.
+// Each provider listed in OIDC_PROVIDERS is read from OIDC_<NAME>_* variables.
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	RedirectURL  string
+}
+
+// PaymentConfig selects the payment provider of paid tickets. Orders hold
+// their seats for ReservationMinutes while awaiting payment.
+type PaymentConfig struct {
+	Provider           string
+	WebhookSecret      string
+	ReservationMinutes int
 }
 
 func LoadConfig() (*Config, error) {
@@ -50,10 +82,108 @@ func LoadConfig() (*Config, error) {
 			Mode: os.Getenv("SERVER_MODE"),
 		},
 		JWT: JWTConfig{
-			Secret:     os.Getenv("JWT_SECRET"),
-			Expiration: os.Getenv("JWT_EXPIRATION"),
+			SigningKeyID:   os.Getenv("JWT_SIGNING_KEY_ID"),
+			SigningKeyFile: os.Getenv("JWT_SIGNING_KEY_FILE"),
+			Algorithms:     splitList(os.Getenv("JWT_ALGORITHMS")),
+			Issuer:         os.Getenv("JWT_ISSUER"),
+			Audience:       splitList(os.Getenv("JWT_AUDIENCE")),
+			Expiration:     os.Getenv("JWT_EXPIRATION"),
 		},
+		AdminEmails: splitList(strings.ToLower(os.Getenv("ADMIN_EMAILS"))),
+	}
+
+	config.JWT.VerificationKeyFiles, err = parseKeyFiles(os.Getenv("JWT_VERIFICATION_KEYS"))
+	if err != nil {
+		return nil, err
+	}
+
+	config.OIDC, err = loadOIDCProviders(splitList(os.Getenv("OIDC_PROVIDERS")))
+	if err != nil {
+		return nil, err
+	}
+
+	config.Payment, err = loadPaymentConfig(config.Server.Mode)
+	if err != nil {
+		return nil, err
 	}
 
 	return config, nil
 }
+
+func loadOIDCProviders(names []string) ([]OIDCProviderConfig, error) {
+	var providers []OIDCProviderConfig
+	for _, name := range names {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			Name:         strings.ToLower(name),
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       splitList(os.Getenv(prefix + "SCOPES")),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			return nil, fmt.Errorf("OIDC provider %q requires %sISSUER, %sCLIENT_ID and %sREDIRECT_URL", name, prefix, prefix, prefix)
+		}
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"openid", "email", "profile"}
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+// loadPaymentConfig reads the payment settings. The fake provider confirms
+// any order whose webhook is signed with the secret, so it is only the
+// default, and only allowed, outside release mode.
+func loadPaymentConfig(mode string) (PaymentConfig, error) {
+	payment := PaymentConfig{
+		Provider:           strings.ToLower(os.Getenv("PAYMENT_PROVIDER")),
+		WebhookSecret:      os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		ReservationMinutes: 15,
+	}
+	release := mode == "release"
+	switch {
+	case payment.Provider == "" && release:
+		return payment, fmt.Errorf("PAYMENT_PROVIDER is required in release mode")
+	case payment.Provider == "":
+		payment.Provider = "fake"
+	case payment.Provider == "fake" && release:
+		return payment, fmt.Errorf("the fake payment provider can't be used in release mode")
+	}
+	if payment.Provider == "fake" && payment.WebhookSecret == "" {
+		return payment, fmt.Errorf("PAYMENT_WEBHOOK_SECRET is required by the fake payment provider")
+	}
+	if value := os.Getenv("ORDER_RESERVATION_MINUTES"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes <= 0 {
+			return payment, fmt.Errorf("invalid ORDER_RESERVATION_MINUTES %q, expected a positive number of minutes", value)
+		}
+		payment.ReservationMinutes = minutes
+	}
+	return payment, nil
+}
+
+// splitList splits a comma separated value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseKeyFiles parses a comma separated list of "kid:path" pairs.
+func parseKeyFiles(value string) (map[string]string, error) {
+	files := make(map[string]string)
+	for _, item := range splitList(value) {
+		kid, path, ok := strings.Cut(item, ":")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid JWT verification key %q, expected kid:path", item)
+		}
+		files[kid] = path
+	}
+	return files, nil
+}
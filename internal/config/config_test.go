@@ -0,0 +1,42 @@
+package config
+
+import "testing"
+
+func TestLoadPaymentConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		provider     string
+		secret       string
+		wantProvider string
+		wantErr      bool
+	}{
+		{name: "fake by default", mode: "debug", secret: "s3cret", wantProvider: "fake"},
+		{name: "fake explicitly", mode: "debug", provider: "Fake", secret: "s3cret", wantProvider: "fake"},
+		{name: "fake without secret", mode: "debug", wantErr: true},
+		{name: "provider required in release", mode: "release", secret: "s3cret", wantErr: true},
+		{name: "fake refused in release", mode: "release", provider: "fake", secret: "s3cret", wantErr: true},
+		{name: "other provider in release", mode: "release", provider: "acme", wantProvider: "acme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PAYMENT_PROVIDER", tt.provider)
+			t.Setenv("PAYMENT_WEBHOOK_SECRET", tt.secret)
+			t.Setenv("ORDER_RESERVATION_MINUTES", "")
+
+			payment, err := loadPaymentConfig(tt.mode)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("loadPaymentConfig = %+v, want an error", payment)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadPaymentConfig: %v", err)
+			}
+			if payment.Provider != tt.wantProvider {
+				t.Errorf("Provider = %q, want %q", payment.Provider, tt.wantProvider)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyUseCase *usecases.APIKeyUseCase
+}
+
+func NewAPIKeyHandler(apiKeyUseCase *usecases.APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyUseCase: apiKeyUseCase}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create a personal API key. The key is only returned in this response.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param apiKey body entities.APIKeyRequest true "API key data"
+// @Success 201 {object} entities.APIKeyCreatedResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api-keys [post]
+// @Security Bearer
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req entities.APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	apiKey, err := h.apiKeyUseCase.CreateAPIKey(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, apiKey)
+}
+
+// ListAPIKeys godoc
+// @Summary List my API keys
+// @Description List the API keys of the authenticated user, without the secret part
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} entities.APIKeyResponse
+// @Failure 401 {object} map[string]string
+// @Router /api-keys [get]
+// @Security Bearer
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	apiKeys, err := h.apiKeyUseCase.ListAPIKeys(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKeys)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke one of the authenticated user's API keys
+// @Tags api-keys
+// @Param id path string true "API key ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api-keys/{id} [delete]
+// @Security Bearer
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	var id uint
+	if _, err := fmt.Sscan(c.Param("id"), &id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	err := h.apiKeyUseCase.RevokeAPIKey(c.Request.Context(), userID.(uint), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
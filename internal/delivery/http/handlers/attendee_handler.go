@@ -1,32 +1,41 @@
 package handlers
 
 import (
+	"EventsAPI/internal/domain/entities"
 	"EventsAPI/internal/usecases"
 	"fmt"
+	"net/http"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AttendeeHandler struct {
 	attendeeUseCase *usecases.AttendeeUseCase
+	exportUseCase   *usecases.AttendeeExportUseCase
+	importUseCase   *usecases.AttendeeImportUseCase
 }
 
-func NewAttendeeHandler(attendeeUseCase *usecases.AttendeeUseCase) *AttendeeHandler {
-	return &AttendeeHandler{attendeeUseCase: attendeeUseCase}
+func NewAttendeeHandler(attendeeUseCase *usecases.AttendeeUseCase, exportUseCase *usecases.AttendeeExportUseCase, importUseCase *usecases.AttendeeImportUseCase) *AttendeeHandler {
+	return &AttendeeHandler{attendeeUseCase: attendeeUseCase, exportUseCase: exportUseCase, importUseCase: importUseCase}
 }
 
 // RegisterForEvent godoc
 // @Summary Register for an event
-// @Description Register the authenticated user for a specific event
+// @Description Register the authenticated user for a specific event. Private events require an email invitation or a valid invite token. Events that require approval get a pending registration. Overlapping registrations are listed in conflicts, or refused with block_on_conflict.
 // @Tags attendees
 // @Accept json
 // @Produce json
 // @Param eventId path string true "Event ID"
+// @Param invite query string false "Invite token"
+// @Param registration body entities.AttendeeRequest false "Guests and answers to the event's registration questions"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /attendees/register/{eventId} [post]
 
 func (h *AttendeeHandler) RegisterForEvent(c *gin.Context) {
@@ -45,18 +54,115 @@ func (h *AttendeeHandler) RegisterForEvent(c *gin.Context) {
 		return
 	}
 
-	err = h.attendeeUseCase.RegisterForEvent(c.Request.Context(), userID.(uint), uint(eventIDUint))
+	var req entities.AttendeeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	attendee, conflicts, err := h.attendeeUseCase.RegisterForEvent(c.Request.Context(), uint(eventIDUint), userID.(uint), c.Query("invite"), &req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "Registered for event successfully", "status": attendee.Status}
+	if attendee.Status == entities.AttendeeStatusPending {
+		response["message"] = "Registration pending approval"
+	}
+	if len(conflicts) > 0 {
+		conflictResponses := make([]entities.ConflictingEventResponse, len(conflicts))
+		for i, event := range conflicts {
+			conflictResponses[i] = newConflictingEventResponse(event)
+		}
+		response["conflicts"] = conflictResponses
+	}
+	c.JSON(200, response)
+}
+
+// UpdateGuests godoc
+// @Summary Update my guests
+// @Description Change the number of guests, and optionally their names, of the authenticated user's registration. Adding guests needs free seats.
+// @Tags attendees
+// @Accept json
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Param guests body entities.AttendeeGuestsRequest true "Guests"
+// @Success 200 {object} entities.AttendeeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /attendees/register/{eventId}/guests [put]
+func (h *AttendeeHandler) UpdateGuests(c *gin.Context) {
+	var req entities.AttendeeGuestsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "eventId", "event")
+	if !ok {
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Registered for event successfully"})
+	attendee, err := h.attendeeUseCase.UpdateGuests(c.Request.Context(), eventID, userID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, newAttendeeResponse(attendee))
+}
+
+// UpdateRSVP godoc
+// @Summary Change my RSVP
+// @Description Answer going, maybe or not_going for the authenticated user's registration. Only going takes up seats.
+// @Tags attendees
+// @Accept json
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Param rsvp body entities.RSVPRequest true "RSVP"
+// @Success 200 {object} entities.AttendeeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /attendees/register/{eventId}/rsvp [put]
+func (h *AttendeeHandler) UpdateRSVP(c *gin.Context) {
+	var req entities.RSVPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "eventId", "event")
+	if !ok {
+		return
+	}
+
+	attendee, err := h.attendeeUseCase.UpdateRSVP(c.Request.Context(), eventID, userID, req.RSVP)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, newAttendeeResponse(attendee))
 }
 
 // UnregisterFromEvent godoc
 // @Summary Unregister from an event
-// @Description Unregister the authenticated user from a specific event
+// @Description Cancel the authenticated user's registration for a specific event
 // @Tags attendees
 // @Accept json
 // @Produce json
@@ -65,6 +171,7 @@ func (h *AttendeeHandler) RegisterForEvent(c *gin.Context) {
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /attendees/unregister/{eventId} [post]
 func (h *AttendeeHandler) UnregisterFromEvent(c *gin.Context) {
 	eventIDStr := c.Param("eventId")
@@ -81,9 +188,9 @@ func (h *AttendeeHandler) UnregisterFromEvent(c *gin.Context) {
 		return
 	}
 
-	err = h.attendeeUseCase.UnregisterFromEvent(c.Request.Context(), userID.(uint), uint(eventIDUint))
+	err = h.attendeeUseCase.UnregisterFromEvent(c.Request.Context(), uint(eventIDUint), userID.(uint))
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -96,7 +203,7 @@ func (h *AttendeeHandler) UnregisterFromEvent(c *gin.Context) {
 // @Tags attendees
 // @Accept json
 // @Produce json
-// @Success 200 {array} entities.EventResponse
+// @Success 200 {array} entities.AttendeeResponse
 // @Failure 401 {object} map[string]string
 // @Router /attendees/my [get]
 func (h *AttendeeHandler) GetMyRegistrations(c *gin.Context) {
@@ -112,24 +219,34 @@ func (h *AttendeeHandler) GetMyRegistrations(c *gin.Context) {
 		return
 	}
 
-	c.JSON(200, registrations)
+	response := make([]entities.AttendeeResponse, 0, len(registrations))
+	for _, registration := range registrations {
+		attendee := newAttendeeResponse(registration)
+		event := newEventResponse(&registration.Event)
+		attendee.Event = &event
+		response = append(response, attendee)
+	}
+
+	c.JSON(200, response)
 }
 
 // GetEventAttendees godoc
 // @Summary Get event attendees
-// @Description Retrieve a list of users registered for a specific event
+// @Description Retrieve the users registered for an event. Organizers get every registration with its answers (entities.AttendeeResponse), optionally filtered by status; other users get a redacted list of the confirmed attendees (entities.PublicAttendeeResponse) when the event's attendee_visibility allows it, without the users who chose to hide themselves.
 // @Tags attendees
 // @Accept json
 // @Produce json
 // @Param eventId path string true "Event ID"
-// @Success 200 {array} entities.UserResponse
+// @Param status query string false "Registration status (organizers only)" Enums(pending, confirmed, rejected, cancelled)
+// @Success 200 {array} entities.AttendeeResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /attendees/event/{eventId} [get]
 func (h *AttendeeHandler) GetEventAttendees(c *gin.Context) {
 	eventIDStr := c.Param("eventId")
-	_, exists := c.Get("userID")
+	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(401, gin.H{"error": "Unauthorized"})
 		return
@@ -142,11 +259,375 @@ func (h *AttendeeHandler) GetEventAttendees(c *gin.Context) {
 		return
 	}
 
-	attendees, err := h.attendeeUseCase.GetEventAttendees(c.Request.Context(), uint(eventIDUint), 10, 0)
+	attendees, full, err := h.attendeeUseCase.GetEventAttendees(c.Request.Context(), userID.(uint), uint(eventIDUint), c.Query("status"), 10, 0)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if !full {
+		response := make([]entities.PublicAttendeeResponse, 0, len(attendees))
+		for _, attendee := range attendees {
+			response = append(response, newPublicAttendeeResponse(attendee))
+		}
+		c.JSON(200, response)
 		return
 	}
 
-	c.JSON(200, attendees)
+	response := make([]entities.AttendeeResponse, 0, len(attendees))
+	for _, attendee := range attendees {
+		full := newAttendeeResponse(attendee)
+		full.User = newUserResponse(&attendee.User)
+		for _, answer := range attendee.Answers {
+			full.Answers = append(full.Answers, entities.RegistrationAnswerResponse{
+				QuestionID: answer.QuestionID,
+				Label:      answer.Question.Label,
+				Value:      answer.JSONValue(),
+			})
+		}
+		response = append(response, full)
+	}
+
+	c.JSON(200, response)
+}
+
+// ExportAttendees godoc
+// @Summary Export event attendees
+// @Description Download every registration of an event, optionally filtered by status, with the attendee's name, email, status, RSVP, guests, registration and check-in times and a column per registration question. Rows are streamed, so events with tens of thousands of attendees export in one request. Requires the attendees:manage permission, which check-in staff lack.
+// @Tags attendees
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param format query string false "File format" Enums(csv, xlsx, json) default(csv)
+// @Param status query string false "Registration status" Enums(pending, confirmed, awaiting_payment, rejected, cancelled)
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/attendees/export [get]
+// @Security Bearer
+func (h *AttendeeHandler) ExportAttendees(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	export, err := h.exportUseCase.ExportAttendees(c.Request.Context(), userID, eventID, c.DefaultQuery("format", "csv"), c.Query("status"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename))
+	c.Status(http.StatusOK)
+	if err := export.Stream(c.Writer); err != nil {
+		// Part of the file was already sent, so the status can't change;
+		// record the error for the request log.
+		_ = c.Error(err)
+	}
+}
+
+// ImportAttendees godoc
+// @Summary Import attendees from a CSV file
+// @Description Register the people listed in a CSV file, with a header row naming an email column and optional first_name and last_name (or name) columns. Existing users are matched by email and the others get an account and an email. Registrations are confirmed within the event's capacity. Files of up to 200 rows are imported before responding with 200; larger ones are imported in the background and the response is 202 with the running import, to follow with GET /events/{id}/attendees/import/{importId}. A dry run reports what would happen without changing anything.
+// @Tags attendees
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param file formData file true "CSV file"
+// @Param dry_run formData bool false "Report without registering anyone"
+// @Param ticket_type_id formData int false "Free ticket type, required when the event has ticket types"
+// @Success 200 {object} entities.AttendeeImport
+// @Success 202 {object} entities.AttendeeImport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/attendees/import [post]
+// @Security Bearer
+func (h *AttendeeHandler) ImportAttendees(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	var req entities.AttendeeImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	upload, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A CSV file is required in the file field"})
+		return
+	}
+	file, err := upload.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	attendeeImport, err := h.importUseCase.ImportAttendees(c.Request.Context(), userID, eventID, file, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	status := http.StatusOK
+	if attendeeImport.Status == entities.AttendeeImportRunning {
+		status = http.StatusAccepted
+	}
+	c.JSON(status, attendeeImport)
+}
+
+// GetAttendeeImport godoc
+// @Summary Get an attendee import
+// @Description Retrieve an import of attendees with its progress and, once completed, the result of every row: created, already_registered, invalid_email, capacity_exceeded or failed.
+// @Tags attendees
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param importId path string true "Import ID"
+// @Success 200 {object} entities.AttendeeImport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/attendees/import/{importId} [get]
+// @Security Bearer
+func (h *AttendeeHandler) GetAttendeeImport(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+	importID, ok := uintParam(c, "importId", "import")
+	if !ok {
+		return
+	}
+
+	attendeeImport, err := h.importUseCase.GetAttendeeImport(c.Request.Context(), userID, eventID, importID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, attendeeImport)
+}
+
+// CheckIn godoc
+// @Summary Check in an attendee
+// @Description Mark a registered user as present at the event. Available to organizers and check-in staff.
+// @Tags attendees
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Param userId path string true "Attendee user ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /attendees/event/{eventId}/check-in/{userId} [post]
+func (h *AttendeeHandler) CheckIn(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(401, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	eventIDUint, err := strconv.ParseUint(c.Param("eventId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid event ID"})
+		return
+	}
+	attendeeUserID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	err = h.attendeeUseCase.CheckIn(c.Request.Context(), userID.(uint), uint(eventIDUint), uint(attendeeUserID))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Attendee checked in successfully"})
+}
+
+// ApproveRegistrations godoc
+// @Summary Approve pending registrations
+// @Description Confirm the pending registrations of the given users, within the event capacity, and notify them with an optional message. A user whose notification fails keeps the new status and gets the error
+// @Tags attendees
+// @Accept json
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Param decision body entities.AttendeeDecisionRequest true "Users to approve"
+// @Success 200 {array} entities.AttendeeDecisionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /attendees/event/{eventId}/approve [post]
+func (h *AttendeeHandler) ApproveRegistrations(c *gin.Context) {
+	h.decideRegistrations(c, true)
+}
+
+// RejectRegistrations godoc
+// @Summary Reject pending registrations
+// @Description Reject the pending registrations of the given users and notify them with an optional message. A user whose notification fails keeps the new status and gets the error
+// @Tags attendees
+// @Accept json
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Param decision body entities.AttendeeDecisionRequest true "Users to reject"
+// @Success 200 {array} entities.AttendeeDecisionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /attendees/event/{eventId}/reject [post]
+func (h *AttendeeHandler) RejectRegistrations(c *gin.Context) {
+	h.decideRegistrations(c, false)
+}
+
+func (h *AttendeeHandler) decideRegistrations(c *gin.Context, approve bool) {
+	var req entities.AttendeeDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "eventId", "event")
+	if !ok {
+		return
+	}
+
+	results, err := h.attendeeUseCase.DecideRegistrations(c.Request.Context(), userID, eventID, &req, approve)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, results)
+}
+
+// MessageAttendees godoc
+// @Summary Message attendees by RSVP
+// @Description Send a message to the confirmed attendees that answered going, maybe or not_going
+// @Tags attendees
+// @Accept json
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Param message body entities.AttendeeMessageRequest true "Message"
+// @Success 200 {object} entities.AttendeeMessageResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /attendees/event/{eventId}/message [post]
+func (h *AttendeeHandler) MessageAttendees(c *gin.Context) {
+	var req entities.AttendeeMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "eventId", "event")
+	if !ok {
+		return
+	}
+
+	result, err := h.attendeeUseCase.MessageAttendees(c.Request.Context(), userID, eventID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// ListMyConflicts godoc
+// @Summary List my schedule conflicts
+// @Description List the pairs of the authenticated user's upcoming registrations whose events overlap
+// @Tags users
+// @Produce json
+// @Success 200 {array} entities.ScheduleConflictResponse
+// @Failure 401 {object} map[string]string
+// @Router /users/me/conflicts [get]
+func (h *AttendeeHandler) ListMyConflicts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	conflicts, err := h.attendeeUseCase.GetConflicts(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]entities.ScheduleConflictResponse, len(conflicts))
+	for i, conflict := range conflicts {
+		response[i] = entities.ScheduleConflictResponse{
+			Event:         newConflictingEventResponse(conflict.Event),
+			ConflictsWith: newConflictingEventResponse(conflict.ConflictsWith),
+		}
+	}
+	c.JSON(200, response)
+}
+
+func newConflictingEventResponse(event *entities.Event) entities.ConflictingEventResponse {
+	return entities.ConflictingEventResponse{
+		ID:       event.ID,
+		Title:    event.Title,
+		Location: event.Location,
+		DateTime: event.DateTime,
+		EndsAt:   event.EndsAt,
+	}
+}
+
+func newAttendeeResponse(attendee *entities.Attendee) entities.AttendeeResponse {
+	return entities.AttendeeResponse{
+		ID:            attendee.ID,
+		EventID:       attendee.EventID,
+		UserID:        attendee.UserID,
+		Status:        attendee.Status,
+		StatusMessage: attendee.StatusMessage,
+		RSVP:          attendee.RSVP,
+		TicketTypeID:  attendee.TicketTypeID,
+		GuestCount:    attendee.GuestCount,
+		GuestNames:    attendee.GuestNames,
+		CheckedInAt:   attendee.CheckedInAt,
+		CreatedAt:     attendee.CreatedAt,
+	}
+}
+
+// newPublicAttendeeResponse only exposes the first name and last name initial.
+func newPublicAttendeeResponse(attendee *entities.Attendee) entities.PublicAttendeeResponse {
+	response := entities.PublicAttendeeResponse{FirstName: attendee.User.FirstName}
+	if initial, _ := utf8.DecodeRuneInString(attendee.User.LastName); initial != utf8.RuneError {
+		response.LastInitial = string(initial) + "."
+	}
+	return response
 }
@@ -81,3 +81,22 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		"user":    user,
 	})
 }
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys used to verify the tokens issued by this API
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.JWKSet
+// @Failure 500 {object} map[string]string
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	jwks, err := h.authUseCase.JWKS()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
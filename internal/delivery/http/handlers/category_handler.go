@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	categoryUseCase *usecases.CategoryUseCase
+}
+
+func NewCategoryHandler(categoryUseCase *usecases.CategoryUseCase) *CategoryHandler {
+	return &CategoryHandler{categoryUseCase: categoryUseCase}
+}
+
+// ListCategories godoc
+// @Summary List categories
+// @Description List the category tree: root categories with their subcategories in children
+// @Tags categories
+// @Produce json
+// @Success 200 {array} entities.Category
+// @Router /categories [get]
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	categories, err := h.categoryUseCase.ListCategories(c.Request.Context())
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// CreateCategory godoc
+// @Summary Create a category
+// @Description Create a category, optionally under a parent. The slug is generated from the name when missing. Administrators only.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body entities.CategoryRequest true "Category data"
+// @Success 201 {object} entities.Category
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /categories [post]
+// @Security Bearer
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req entities.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryUseCase.CreateCategory(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory godoc
+// @Summary Update a category
+// @Description Rename, move or reorder a category. Administrators only.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param category body entities.CategoryRequest true "Category data"
+// @Success 200 {object} entities.Category
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /categories/{id} [put]
+// @Security Bearer
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	var req entities.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	categoryID, ok := uintParam(c, "id", "category")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryUseCase.UpdateCategory(c.Request.Context(), userID, categoryID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category
+// @Description Delete a category without subcategories or events. Administrators only.
+// @Tags categories
+// @Param id path string true "Category ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /categories/{id} [delete]
+// @Security Bearer
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	categoryID, ok := uintParam(c, "id", "category")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.categoryUseCase.DeleteCategory(c.Request.Context(), userID, categoryID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
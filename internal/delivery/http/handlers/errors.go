@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"EventsAPI/internal/usecases"
+)
+
+// errorStatus maps use case errors to HTTP status codes. Unknown errors are
+// treated as internal errors.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrForbidden),
+		errors.Is(err, usecases.ErrInviteRequired):
+		return http.StatusForbidden
+	case errors.Is(err, usecases.ErrEventNotFound),
+		errors.Is(err, usecases.ErrAttendeeNotFound),
+		errors.Is(err, usecases.ErrOrganizationNotFound),
+		errors.Is(err, usecases.ErrMemberNotFound),
+		errors.Is(err, usecases.ErrAPIKeyNotFound),
+		errors.Is(err, usecases.ErrUserNotFound),
+		errors.Is(err, usecases.ErrCollaboratorNotFound),
+		errors.Is(err, usecases.ErrInviteLinkNotFound),
+		errors.Is(err, usecases.ErrEventInvitationNotFound),
+		errors.Is(err, usecases.ErrQuestionNotFound),
+		errors.Is(err, usecases.ErrVenueNotFound),
+		errors.Is(err, usecases.ErrRoomNotFound),
+		errors.Is(err, usecases.ErrCategoryNotFound),
+		errors.Is(err, usecases.ErrTicketTypeNotFound),
+		errors.Is(err, usecases.ErrOrderNotFound),
+		errors.Is(err, usecases.ErrRefundNotFound),
+		errors.Is(err, usecases.ErrPromoCodeNotFound),
+		errors.Is(err, usecases.ErrAttendeeImportNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrPaymentRequired):
+		return http.StatusPaymentRequired
+	case errors.Is(err, usecases.ErrAlreadyRegistered),
+		errors.Is(err, usecases.ErrEventFull),
+		errors.Is(err, usecases.ErrAlreadyMember),
+		errors.Is(err, usecases.ErrEventInvitationAnswered),
+		errors.Is(err, usecases.ErrRegistrationRejected),
+		errors.Is(err, usecases.ErrRegistrationNotPending),
+		errors.Is(err, usecases.ErrRegistrationNotOpen),
+		errors.Is(err, usecases.ErrRegistrationClosed),
+		errors.Is(err, usecases.ErrCancellationClosed),
+		errors.Is(err, usecases.ErrScheduleConflict),
+		errors.Is(err, usecases.ErrVenueConflict),
+		errors.Is(err, usecases.ErrCategoryExists),
+		errors.Is(err, usecases.ErrCategoryInUse),
+		errors.Is(err, usecases.ErrTicketTypeInUse),
+		errors.Is(err, usecases.ErrLastTicketType),
+		errors.Is(err, usecases.ErrTicketNotOnSale),
+		errors.Is(err, usecases.ErrTicketSoldOut),
+		errors.Is(err, usecases.ErrPaymentPending),
+		errors.Is(err, usecases.ErrPaidRegistration),
+		errors.Is(err, usecases.ErrPromoCodeExists),
+		errors.Is(err, usecases.ErrPromoCodeUnavailable),
+		errors.Is(err, usecases.ErrInvoiceNotAvailable):
+		return http.StatusConflict
+	case errors.Is(err, usecases.ErrInvalidCapacity),
+		errors.Is(err, usecases.ErrInvalidInvitation),
+		errors.Is(err, usecases.ErrOwnerRoleChange),
+		errors.Is(err, usecases.ErrCollaboratorIsOwner),
+		errors.Is(err, usecases.ErrAPIKeyExpiryPast),
+		errors.Is(err, usecases.ErrInviteLinkExpiryPast),
+		errors.Is(err, usecases.ErrEventNotPrivate),
+		errors.Is(err, usecases.ErrInvalidQuestion),
+		errors.Is(err, usecases.ErrInvalidAnswers),
+		errors.Is(err, usecases.ErrTooManyGuests),
+		errors.Is(err, usecases.ErrEventInPast),
+		errors.Is(err, usecases.ErrInvalidSchedule),
+		errors.Is(err, usecases.ErrInvalidEndTime),
+		errors.Is(err, usecases.ErrInvalidTimeZone),
+		errors.Is(err, usecases.ErrRoomTooSmall),
+		errors.Is(err, usecases.ErrInvalidNearby),
+		errors.Is(err, usecases.ErrInvalidSearch),
+		errors.Is(err, usecases.ErrInvalidTags),
+		errors.Is(err, usecases.ErrInvalidRefunds),
+		errors.Is(err, usecases.ErrInvalidCategory),
+		errors.Is(err, usecases.ErrInvalidTicketType),
+		errors.Is(err, usecases.ErrTicketTypeRequired),
+		errors.Is(err, usecases.ErrInvalidTicketQuantity),
+		errors.Is(err, usecases.ErrInvalidOrder),
+		errors.Is(err, usecases.ErrInvalidWebhook),
+		errors.Is(err, usecases.ErrInvalidRefund),
+		errors.Is(err, usecases.ErrInvalidPromoCode),
+		errors.Is(err, usecases.ErrUnsupportedExportFormat),
+		errors.Is(err, usecases.ErrInvalidImport):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
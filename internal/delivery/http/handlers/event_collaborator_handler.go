@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventCollaboratorHandler struct {
+	collaboratorUseCase *usecases.EventCollaboratorUseCase
+}
+
+func NewEventCollaboratorHandler(collaboratorUseCase *usecases.EventCollaboratorUseCase) *EventCollaboratorHandler {
+	return &EventCollaboratorHandler{collaboratorUseCase: collaboratorUseCase}
+}
+
+// AddCollaborator godoc
+// @Summary Add an event collaborator
+// @Description Grant a user, by email, the co_organizer role (edit the event and see attendees) or the staff role (check people in)
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param collaborator body entities.EventCollaboratorRequest true "Collaborator data"
+// @Success 201 {object} entities.EventCollaboratorResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/collaborators [post]
+// @Security Bearer
+func (h *EventCollaboratorHandler) AddCollaborator(c *gin.Context) {
+	var req entities.EventCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	collaborator, err := h.collaboratorUseCase.AddCollaborator(c.Request.Context(), userID, eventID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, collaborator)
+}
+
+// ListCollaborators godoc
+// @Summary List event collaborators
+// @Description Retrieve the users with a per-event role
+// @Tags events
+// @Produce json
+// @Param id path string true "Event ID"
+// @Success 200 {array} entities.EventCollaboratorResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/collaborators [get]
+// @Security Bearer
+func (h *EventCollaboratorHandler) ListCollaborators(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	collaborators, err := h.collaboratorUseCase.ListCollaborators(c.Request.Context(), userID, eventID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collaborators)
+}
+
+// RemoveCollaborator godoc
+// @Summary Remove an event collaborator
+// @Description Revoke a user's per-event role
+// @Tags events
+// @Param id path string true "Event ID"
+// @Param userId path string true "Collaborator user ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/collaborators/{userId} [delete]
+// @Security Bearer
+func (h *EventCollaboratorHandler) RemoveCollaborator(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+	collaboratorUserID, ok := uintParam(c, "userId", "user")
+	if !ok {
+		return
+	}
+
+	err := h.collaboratorUseCase.RemoveCollaborator(c.Request.Context(), userID, eventID, collaboratorUserID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -4,6 +4,11 @@ import (
 	"EventsAPI/internal/domain/entities"
 	"EventsAPI/internal/usecases"
 	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,6 +31,7 @@ func NewEventHandler(eventUseCase *usecases.EventUseCase) *EventHandler {
 // @Success 201 {object} entities.EventResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /events [post]
 // @Security Bearer
 func (h *EventHandler) CreateEvent(c *gin.Context) {
@@ -42,17 +48,38 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 	}
 
 	newEvent := &entities.Event{
-		Title:       req.Title,
-		Description: req.Description,
-		Location:    req.Location,
-		DateTime:    req.DateTime,
-		MaxCapacity: req.MaxCapacity,
-		UserID:      userID.(uint),
+		Title:                req.Title,
+		Description:          req.Description,
+		Location:             req.Location,
+		VenueID:              req.VenueID,
+		RoomID:               req.RoomID,
+		Latitude:             req.Latitude,
+		Longitude:            req.Longitude,
+		DateTime:             req.DateTime,
+		EndsAt:               req.EndsAt,
+		TimeZone:             req.TimeZone,
+		AllDay:               req.AllDay,
+		MaxCapacity:          req.MaxCapacity,
+		RequiresApproval:     req.RequiresApproval,
+		MaxGuests:            req.MaxGuests,
+		RegistrationOpensAt:  req.RegistrationOpensAt,
+		RegistrationClosesAt: req.RegistrationClosesAt,
+		CancellationDeadline: req.CancellationDeadline,
+		UserID:               userID.(uint),
+		OrganizationID:       req.OrganizationID,
+		Visibility:           req.Visibility,
+		AttendeeVisibility:   req.AttendeeVisibility,
+		CategoryID:           req.CategoryID,
+		Tags:                 req.Tags,
+		RefundPolicy:         req.RefundPolicy,
+		TaxRate:              req.TaxRate,
+		TaxName:              strings.TrimSpace(req.TaxName),
+		Language:             req.Language,
 	}
 
 	err := h.eventUseCase.CreateEvent(c.Request.Context(), newEvent)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -63,45 +90,149 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 
 // ListEvents godoc
 // @Summary List all events
-// @Description Retrieve a list of all events
+// @Description Retrieve the public events. Unlisted and private events are not listed. With near, only the upcoming events within radius_km of the point are listed, nearest first, with their distance_km. category_id also matches its subcategories and every tag must match. With facets=true the response is an object with the events and the upcoming event counts per category and tag.
 // @Tags events
 // @Accept json
 // @Produce json
+// @Param near query string false "Point to search around, as lat,lng"
+// @Param radius_km query number false "Search radius in km (default 10)"
+// @Param category_id query int false "Category ID"
+// @Param tag query []string false "Tag, may be repeated" collectionFormat(multi)
+// @Param facets query bool false "Return entities.EventListResponse with facet counts"
 // @Success 200 {array} entities.EventResponse
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /events [get]
 func (h *EventHandler) ListEvents(c *gin.Context) {
-	events, err := h.eventUseCase.ListEvents(c.Request.Context(), 100, 0)
+	filter, ok := eventFilter(c)
+	if !ok {
+		return
+	}
+	if near := c.Query("near"); near != "" {
+		h.listEventsNear(c, near, filter)
+		return
+	}
+
+	events, err := h.eventUseCase.ListEvents(c.Request.Context(), filter, 100, 0)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	var response []entities.EventResponse
 	for _, event := range events {
-		response = append(response, entities.EventResponse{
-			ID:             event.ID,
-			Title:          event.Title,
-			Description:    event.Description,
-			Location:       event.Location,
-			DateTime:       event.DateTime,
-			MaxCapacity:    event.MaxCapacity,
-			UserID:         event.UserID,
-			AttendeesCount: len(event.Attendees),
-			CreatedAt:      event.CreatedAt,
-		})
+		response = append(response, newEventResponse(event))
 	}
 
-	c.JSON(200, response)
+	if c.Query("facets") != "true" {
+		c.JSON(200, response)
+		return
+	}
+	facets, err := h.eventUseCase.EventFacets(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if response == nil {
+		response = []entities.EventResponse{}
+	}
+	c.JSON(http.StatusOK, entities.EventListResponse{Events: response, Facets: *facets})
+}
+
+// eventFilter reads the category_id and tag query parameters. It writes a 400
+// response and returns false when they are invalid.
+func eventFilter(c *gin.Context) (entities.EventFilter, bool) {
+	filter := entities.EventFilter{Tags: c.QueryArray("tag")}
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		id, err := strconv.ParseUint(categoryID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category_id"})
+			return filter, false
+		}
+		value := uint(id)
+		filter.CategoryID = &value
+	}
+	return filter, true
+}
+
+func (h *EventHandler) listEventsNear(c *gin.Context, near string, filter entities.EventFilter) {
+	latText, lngText, found := strings.Cut(near, ",")
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+	lng, lngErr := strconv.ParseFloat(strings.TrimSpace(lngText), 64)
+	if !found || latErr != nil || lngErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "near must be lat,lng"})
+		return
+	}
+
+	radiusKm := 10.0
+	if radius := c.Query("radius_km"); radius != "" {
+		var err error
+		if radiusKm, err = strconv.ParseFloat(radius, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid radius_km"})
+			return
+		}
+	}
+
+	nearby, err := h.eventUseCase.ListEventsNear(c.Request.Context(), lat, lng, radiusKm, filter, 100, 0)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]entities.EventResponse, len(nearby))
+	for i, result := range nearby {
+		response[i] = newEventResponse(result.Event)
+		distanceKm := math.Round(result.DistanceKm*1000) / 1000
+		response[i].DistanceKm = &distanceKm
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchEvents godoc
+// @Summary Search events
+// @Description Full-text search over the title, description and location of the public events, ranked by relevance. q supports "quoted phrases", OR, -excluded words and prefix* words. Matches are wrapped in <mark> tags in title_highlight and snippet; the rest of the text is HTML-escaped.
+// @Tags events
+// @Produce json
+// @Param q query string true "Search query"
+// @Param language query string false "Only events in this language (spanish or english)"
+// @Param category_id query int false "Category ID, subcategories included"
+// @Param tag query []string false "Tag, may be repeated" collectionFormat(multi)
+// @Success 200 {array} entities.EventSearchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /events/search [get]
+func (h *EventHandler) SearchEvents(c *gin.Context) {
+	filter, ok := eventFilter(c)
+	if !ok {
+		return
+	}
+
+	results, err := h.eventUseCase.SearchEvents(c.Request.Context(), c.Query("q"), c.Query("language"), filter, 100, 0)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]entities.EventSearchResponse, len(results))
+	for i, result := range results {
+		response[i] = entities.EventSearchResponse{
+			EventResponse:  newEventResponse(result.Event),
+			Rank:           result.Rank,
+			TitleHighlight: result.TitleHighlight,
+			Snippet:        result.Snippet,
+		}
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // GetEvent godoc
 // @Summary Get event by ID
-// @Description Retrieve a specific event by its ID
+// @Description Retrieve a specific event by its ID. Private events are only returned to invited users or with a valid invite token.
 // @Tags events
 // @Accept json
 // @Produce json
 // @Param id path string true "Event ID"
+// @Param invite query string false "Invite token"
 // @Success 200 {object} entities.EventResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -115,24 +246,20 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 		return
 	}
 
-	event, err := h.eventUseCase.GetEventByID(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(404, gin.H{"error": "Event not found"})
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(401, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	response := entities.EventResponse{
-		ID:             event.ID,
-		Title:          event.Title,
-		Description:    event.Description,
-		Location:       event.Location,
-		DateTime:       event.DateTime,
-		MaxCapacity:    event.MaxCapacity,
-		UserID:         event.UserID,
-		AttendeesCount: len(event.Attendees),
-		CreatedAt:      event.CreatedAt,
+	event, err := h.eventUseCase.GetEventForUser(c.Request.Context(), userID.(uint), id, c.Query("invite"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
 	}
 
+	response := newEventResponse(event)
+
 	c.JSON(200, response)
 }
 
@@ -147,7 +274,9 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 // @Success 200 {object} entities.EventResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /events/{id} [put]
 // @Security Bearer
 func (h *EventHandler) UpdateEvent(c *gin.Context) {
@@ -171,40 +300,13 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 		return
 	}
 
-	event, err := h.eventUseCase.GetEventByID(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(404, gin.H{"error": "Event not found"})
-		return
-	}
-
-	if event.UserID != userID.(uint) {
-		c.JSON(401, gin.H{"error": "You are not the owner of this event"})
-		return
-	}
-
-	event.Title = req.Title
-	event.Description = req.Description
-	event.Location = req.Location
-	event.DateTime = req.DateTime
-	event.MaxCapacity = req.MaxCapacity
-
-	err = h.eventUseCase.UpdateEvent(c.Request.Context(), event)
+	event, err := h.eventUseCase.UpdateEvent(c.Request.Context(), userID.(uint), id, &req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	response := entities.EventResponse{
-		ID:             event.ID,
-		Title:          event.Title,
-		Description:    event.Description,
-		Location:       event.Location,
-		DateTime:       event.DateTime,
-		MaxCapacity:    event.MaxCapacity,
-		UserID:         event.UserID,
-		AttendeesCount: len(event.Attendees),
-		CreatedAt:      event.CreatedAt,
-	}
+	response := newEventResponse(event)
 
 	c.JSON(200, response)
 }
@@ -219,6 +321,7 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 // @Success 204 {object} nil
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /events/{id} [delete]
 // @Security Bearer
@@ -237,20 +340,9 @@ func (h *EventHandler) DeleteEvent(c *gin.Context) {
 		return
 	}
 
-	event, err := h.eventUseCase.GetEventByID(c.Request.Context(), id)
+	err = h.eventUseCase.DeleteEvent(c.Request.Context(), userID.(uint), id)
 	if err != nil {
-		c.JSON(404, gin.H{"error": "Event not found"})
-		return
-	}
-
-	if event.UserID != userID.(uint) {
-		c.JSON(401, gin.H{"error": "You are not the owner of this event"})
-		return
-	}
-
-	err = h.eventUseCase.DeleteEvent(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -259,7 +351,7 @@ func (h *EventHandler) DeleteEvent(c *gin.Context) {
 
 // GetMyEvents godoc
 // @Summary Get my events
-// @Description Retrieve events created by the authenticated user
+// @Description Retrieve events created by the authenticated user or by their organizations
 // @Tags events
 // @Accept json
 // @Produce json
@@ -282,18 +374,69 @@ func (h *EventHandler) GetMyEvents(c *gin.Context) {
 
 	var response []entities.EventResponse
 	for _, event := range events {
-		response = append(response, entities.EventResponse{
-			ID:             event.ID,
-			Title:          event.Title,
-			Description:    event.Description,
-			Location:       event.Location,
-			DateTime:       event.DateTime,
-			MaxCapacity:    event.MaxCapacity,
-			UserID:         event.UserID,
-			AttendeesCount: len(event.Attendees),
-			CreatedAt:      event.CreatedAt,
-		})
+		response = append(response, newEventResponse(event))
 	}
 
 	c.JSON(200, response)
 }
+
+func newEventResponse(event *entities.Event) entities.EventResponse {
+	// Attendees only holds the confirmed registrations.
+	var rsvpCounts entities.RSVPCounts
+	seatsTaken := 0
+	for _, attendee := range event.Attendees {
+		switch attendee.RSVP {
+		case entities.RSVPGoing:
+			rsvpCounts.Going++
+			seatsTaken += attendee.Seats()
+		case entities.RSVPMaybe:
+			rsvpCounts.Maybe++
+		case entities.RSVPNotGoing:
+			rsvpCounts.NotGoing++
+		}
+	}
+
+	loc := event.Zone()
+	var endsAt, localEndsAt *time.Time
+	if event.EndsAt != nil {
+		utcEnd, localEnd := event.EndsAt.UTC(), event.EndsAt.In(loc)
+		endsAt, localEndsAt = &utcEnd, &localEnd
+	}
+
+	return entities.EventResponse{
+		ID:                   event.ID,
+		Title:                event.Title,
+		Description:          event.Description,
+		Location:             event.Location,
+		VenueID:              event.VenueID,
+		RoomID:               event.RoomID,
+		Latitude:             event.Latitude,
+		Longitude:            event.Longitude,
+		DateTime:             event.DateTime.UTC(),
+		EndsAt:               endsAt,
+		TimeZone:             event.TimeZone,
+		AllDay:               event.AllDay,
+		LocalDateTime:        event.DateTime.In(loc),
+		LocalEndsAt:          localEndsAt,
+		MaxCapacity:          event.MaxCapacity,
+		RequiresApproval:     event.RequiresApproval,
+		MaxGuests:            event.MaxGuests,
+		RegistrationOpensAt:  event.RegistrationOpensAt,
+		RegistrationClosesAt: event.RegistrationClosesAt,
+		CancellationDeadline: event.CancellationDeadline,
+		SeatsTaken:           seatsTaken,
+		RSVPCounts:           rsvpCounts,
+		UserID:               event.UserID,
+		OrganizationID:       event.OrganizationID,
+		Visibility:           event.Visibility,
+		AttendeeVisibility:   event.AttendeeVisibility,
+		CategoryID:           event.CategoryID,
+		Tags:                 event.Tags,
+		RefundPolicy:         event.RefundPolicy,
+		TaxRate:              event.TaxRate,
+		TaxName:              event.TaxName,
+		Language:             event.Language,
+		AttendeesCount:       rsvpCounts.Going,
+		CreatedAt:            event.CreatedAt,
+	}
+}
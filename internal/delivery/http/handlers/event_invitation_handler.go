@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"net/http"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventInvitationHandler struct {
+	invitationUseCase *usecases.EventInvitationUseCase
+}
+
+func NewEventInvitationHandler(invitationUseCase *usecases.EventInvitationUseCase) *EventInvitationHandler {
+	return &EventInvitationHandler{invitationUseCase: invitationUseCase}
+}
+
+// CreateInviteLink godoc
+// @Summary Create an invite link
+// @Description Create a signed invite token for the event, optionally limited in uses and time. Share it as the invite query parameter of the event and registration endpoints.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param link body entities.EventInviteLinkRequest true "Invite link limits"
+// @Success 201 {object} entities.EventInviteLinkResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/invite-links [post]
+// @Security Bearer
+func (h *EventInvitationHandler) CreateInviteLink(c *gin.Context) {
+	var req entities.EventInviteLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	link, err := h.invitationUseCase.CreateInviteLink(c.Request.Context(), userID, eventID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// ListInviteLinks godoc
+// @Summary List invite links
+// @Description Retrieve the invite links of an event with their usage
+// @Tags events
+// @Produce json
+// @Param id path string true "Event ID"
+// @Success 200 {array} entities.EventInviteLinkResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/invite-links [get]
+// @Security Bearer
+func (h *EventInvitationHandler) ListInviteLinks(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	links, err := h.invitationUseCase.ListInviteLinks(c.Request.Context(), userID, eventID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// RevokeInviteLink godoc
+// @Summary Revoke an invite link
+// @Description Stop an invite link from admitting anyone else
+// @Tags events
+// @Param id path string true "Event ID"
+// @Param linkId path string true "Invite link ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/invite-links/{linkId} [delete]
+// @Security Bearer
+func (h *EventInvitationHandler) RevokeInviteLink(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+	linkID, ok := uintParam(c, "linkId", "invite link")
+	if !ok {
+		return
+	}
+
+	err := h.invitationUseCase.RevokeInviteLink(c.Request.Context(), userID, eventID, linkID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Invite godoc
+// @Summary Invite someone to a private event
+// @Description Send an invitation to an email address, which the recipient can accept or decline
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param invitation body entities.EventInvitationRequest true "Invitation data"
+// @Success 201 {object} entities.EventInvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/invitations [post]
+// @Security Bearer
+func (h *EventInvitationHandler) Invite(c *gin.Context) {
+	var req entities.EventInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	invitation, err := h.invitationUseCase.Invite(c.Request.Context(), userID, eventID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListInvitations godoc
+// @Summary List event invitations
+// @Description Retrieve the email invitations of an event and their answers
+// @Tags events
+// @Produce json
+// @Param id path string true "Event ID"
+// @Success 200 {array} entities.EventInvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/invitations [get]
+// @Security Bearer
+func (h *EventInvitationHandler) ListInvitations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	invitations, err := h.invitationUseCase.ListInvitations(c.Request.Context(), userID, eventID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// ListMyInvitations godoc
+// @Summary List my event invitations
+// @Description Retrieve the event invitations sent to the authenticated user's email address
+// @Tags users
+// @Produce json
+// @Success 200 {array} entities.EventInvitationResponse
+// @Failure 401 {object} map[string]string
+// @Router /users/me/invitations [get]
+// @Security Bearer
+func (h *EventInvitationHandler) ListMyInvitations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.invitationUseCase.ListMyInvitations(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an event invitation
+// @Description Accept an invitation to a private event, which allows registering for it
+// @Tags users
+// @Produce json
+// @Param invitationId path string true "Invitation ID"
+// @Success 200 {object} entities.EventInvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /users/me/invitations/{invitationId}/accept [post]
+// @Security Bearer
+func (h *EventInvitationHandler) AcceptInvitation(c *gin.Context) {
+	h.respond(c, true)
+}
+
+// DeclineInvitation godoc
+// @Summary Decline an event invitation
+// @Description Decline an invitation to a private event
+// @Tags users
+// @Produce json
+// @Param invitationId path string true "Invitation ID"
+// @Success 200 {object} entities.EventInvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /users/me/invitations/{invitationId}/decline [post]
+// @Security Bearer
+func (h *EventInvitationHandler) DeclineInvitation(c *gin.Context) {
+	h.respond(c, false)
+}
+
+func (h *EventInvitationHandler) respond(c *gin.Context, accept bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	invitationID, ok := uintParam(c, "invitationId", "invitation")
+	if !ok {
+		return
+	}
+
+	invitation, err := h.invitationUseCase.RespondToInvitation(c.Request.Context(), userID, invitationID, accept)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
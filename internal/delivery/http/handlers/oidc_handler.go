@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"EventsAPI/internal/domain/services"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	oidcUseCase *usecases.OIDCUseCase
+}
+
+func NewOIDCHandler(oidcUseCase *usecases.OIDCUseCase) *OIDCHandler {
+	return &OIDCHandler{oidcUseCase: oidcUseCase}
+}
+
+// StartLogin godoc
+// @Summary Start SSO login
+// @Description Redirects to the identity provider using the authorization code flow with PKCE
+// @Tags auth
+// @Param provider path string true "Identity provider name"
+// @Success 302
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /auth/oidc/{provider}/start [get]
+func (h *OIDCHandler) StartLogin(c *gin.Context) {
+	authURL, err := h.oidcUseCase.StartLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		c.JSON(oidcErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback godoc
+// @Summary Complete SSO login
+// @Description Handles the identity provider redirect and returns an API token
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Identity provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "Login state"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": providerErr, "error_description": c.Query("error_description")})
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code and state are required"})
+		return
+	}
+
+	token, user, err := h.oidcUseCase.CompleteLogin(c.Request.Context(), c.Param("provider"), state, code)
+	if err != nil {
+		c.JSON(oidcErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Login successful",
+		"token":   token,
+		"user":    user,
+	})
+}
+
+// oidcErrorStatus maps SSO login errors to HTTP status codes. Only a bad login
+// state or a rejected login is a 401; provider failures are a 502 and the rest
+// goes through errorStatus.
+func oidcErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrUnknownIdentityProvider):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrInvalidLoginState),
+		errors.Is(err, services.ErrLoginRejected):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecases.ErrEmailNotVerified):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrIdentityProviderUnavailable):
+		return http.StatusBadGateway
+	default:
+		return errorStatus(err)
+	}
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+// paymentSignatureHeader carries the provider's signature of a webhook body.
+const paymentSignatureHeader = "X-Payment-Signature"
+
+type OrderHandler struct {
+	orderUseCase  *usecases.OrderUseCase
+	refundUseCase *usecases.RefundUseCase
+}
+
+func NewOrderHandler(orderUseCase *usecases.OrderUseCase, refundUseCase *usecases.RefundUseCase) *OrderHandler {
+	return &OrderHandler{orderUseCase: orderUseCase, refundUseCase: refundUseCase}
+}
+
+// Checkout godoc
+// @Summary Buy tickets
+// @Description Register for an event with a paid ticket type. The seats of the user and their guests are reserved until the order expires; pay at the order's checkout_url to confirm the registration. Overlapping registrations are returned as conflicts.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param invite query string false "Invite token"
+// @Param registration body entities.AttendeeRequest true "Registration with a paid ticket type"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /events/{id}/orders [post]
+// @Security Bearer
+func (h *OrderHandler) Checkout(c *gin.Context) {
+	var req entities.AttendeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	order, conflicts, err := h.orderUseCase.Checkout(c.Request.Context(), eventID, userID, c.Query("invite"), &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"order": newOrderResponse(order)}
+	if len(conflicts) > 0 {
+		conflictResponses := make([]entities.ConflictingEventResponse, len(conflicts))
+		for i, event := range conflicts {
+			conflictResponses[i] = newConflictingEventResponse(event)
+		}
+		response["conflicts"] = conflictResponses
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListMyOrders godoc
+// @Summary List my orders
+// @Description Retrieve the orders of the authenticated user, newest first.
+// @Tags orders
+// @Produce json
+// @Success 200 {array} entities.OrderResponse
+// @Failure 401 {object} map[string]string
+// @Router /orders [get]
+// @Security Bearer
+func (h *OrderHandler) ListMyOrders(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	orders, err := h.orderUseCase.ListMyOrders(c.Request.Context(), userID, 100, 0)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]entities.OrderResponse, len(orders))
+	for i, order := range orders {
+		response[i] = newOrderResponse(order)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetOrder godoc
+// @Summary Get an order
+// @Description Retrieve an order of the authenticated user, or of an event they organize.
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} entities.OrderResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id} [get]
+// @Security Bearer
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	orderID, ok := uintParam(c, "id", "order")
+	if !ok {
+		return
+	}
+
+	order, err := h.orderUseCase.GetOrder(c.Request.Context(), userID, orderID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := newOrderResponse(order)
+	if order.UserID != userID {
+		response.User = newUserResponse(&order.User)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CancelOrder godoc
+// @Summary Cancel an order
+// @Description Give up a pending order of the authenticated user, releasing its reserved seats.
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} entities.OrderResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/cancel [post]
+// @Security Bearer
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	orderID, ok := uintParam(c, "id", "order")
+	if !ok {
+		return
+	}
+
+	order, err := h.orderUseCase.CancelOrder(c.Request.Context(), userID, orderID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newOrderResponse(order))
+}
+
+// OrderInvoice godoc
+// @Summary Download an order's invoice
+// @Description Download the invoice of a paid order as a PDF, followed by a credit note for each of its refunds. Available to the buyer and the event's organizers.
+// @Tags orders
+// @Produce application/pdf
+// @Param id path string true "Order ID"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /orders/{id}/invoice.pdf [get]
+// @Security Bearer
+func (h *OrderHandler) OrderInvoice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	orderID, ok := uintParam(c, "id", "order")
+	if !ok {
+		return
+	}
+
+	document, contentType, err := h.orderUseCase.OrderInvoice(c.Request.Context(), userID, orderID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="invoice-order-%d.pdf"`, orderID))
+	c.Data(http.StatusOK, contentType, document)
+}
+
+// RefundOrder godoc
+// @Summary Refund an order
+// @Description Refund part or all of a paid order regardless of the event's refund policy. Without an amount, whatever hasn't been refunded yet is refunded. The registration is kept. Only available to the event owners and administrators.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param refund body entities.OrderRefundRequest false "Amount in minor units and reason"
+// @Success 201 {object} entities.RefundResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/refunds [post]
+// @Security Bearer
+func (h *OrderHandler) RefundOrder(c *gin.Context) {
+	var req entities.OrderRefundRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	orderID, ok := uintParam(c, "id", "order")
+	if !ok {
+		return
+	}
+
+	refund, err := h.refundUseCase.IssueRefund(c.Request.Context(), userID, orderID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, newRefundResponse(refund))
+}
+
+// ListEventOrders godoc
+// @Summary List event orders
+// @Description Retrieve the orders of an event, newest first, optionally filtered by status. Only available to the event organizers.
+// @Tags orders
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param status query string false "Order status" Enums(pending, paid, failed, expired, cancelled, unfulfilled)
+// @Success 200 {array} entities.OrderResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/orders [get]
+// @Security Bearer
+func (h *OrderHandler) ListEventOrders(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	orders, err := h.orderUseCase.ListEventOrders(c.Request.Context(), userID, eventID, c.Query("status"), 100, 0)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]entities.OrderResponse, len(orders))
+	for i, order := range orders {
+		response[i] = newOrderResponse(order)
+		response[i].User = newUserResponse(&order.User)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// EventRevenue godoc
+// @Summary Get event revenue
+// @Description Sum the paid orders of an event, in total and per ticket type. Amounts are in minor units of the currency. Only available to the event organizers.
+// @Tags orders
+// @Produce json
+// @Param id path string true "Event ID"
+// @Success 200 {object} entities.EventRevenueResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/revenue [get]
+// @Security Bearer
+func (h *OrderHandler) EventRevenue(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	revenue, err := h.orderUseCase.EventRevenue(c.Request.Context(), userID, eventID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, revenue)
+}
+
+// PaymentWebhook godoc
+// @Summary Payment provider webhook
+// @Description Receive a payment notification signed by the payment provider in the X-Payment-Signature header. Repeated notifications are ignored.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param X-Payment-Signature header string true "Signature of the request body"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /payments/webhook [post]
+func (h *OrderHandler) PaymentWebhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.orderUseCase.HandlePaymentWebhook(c.Request.Context(), payload, c.GetHeader(paymentSignatureHeader))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "received"})
+}
+
+func newOrderResponse(order *entities.Order) entities.OrderResponse {
+	response := entities.OrderResponse{
+		ID:             order.ID,
+		EventID:        order.EventID,
+		EventTitle:     order.Event.Title,
+		UserID:         order.UserID,
+		AttendeeID:     order.AttendeeID,
+		TicketTypeID:   order.TicketTypeID,
+		TicketTypeName: order.TicketType.Name,
+		Quantity:       order.Quantity,
+		UnitPrice:      order.UnitPrice,
+		Discount:       order.Discount,
+		PromoCode:      order.PromoCode,
+		Billing:        order.Billing,
+		Amount:         order.Amount,
+		Currency:       order.Currency,
+		Status:         order.Status,
+		ExpiresAt:      order.ExpiresAt,
+		PaidAt:         order.PaidAt,
+		RefundedAmount: order.RefundedAmount,
+		RefundStatus:   order.RefundStatus,
+		CreatedAt:      order.CreatedAt,
+	}
+	if order.Status == entities.OrderStatusPending {
+		response.CheckoutURL = order.CheckoutURL
+	}
+	for i := range order.Refunds {
+		response.Refunds = append(response.Refunds, newRefundResponse(&order.Refunds[i]))
+	}
+	return response
+}
+
+func newRefundResponse(refund *entities.Refund) entities.RefundResponse {
+	return entities.RefundResponse{
+		ID:             refund.ID,
+		Amount:         refund.Amount,
+		Currency:       refund.Currency,
+		Status:         refund.Status,
+		Reason:         refund.Reason,
+		Manual:         refund.RequestedByID != nil,
+		FailureMessage: refund.FailureMessage,
+		CreatedAt:      refund.CreatedAt,
+	}
+}
@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"net/http"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	organizationUseCase *usecases.OrganizationUseCase
+}
+
+func NewOrganizationHandler(organizationUseCase *usecases.OrganizationUseCase) *OrganizationHandler {
+	return &OrganizationHandler{organizationUseCase: organizationUseCase}
+}
+
+// CreateOrganization godoc
+// @Summary Create an organization
+// @Description Create an organization owned by the authenticated user
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param organization body entities.OrganizationRequest true "Organization data"
+// @Success 201 {object} entities.OrganizationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /organizations [post]
+// @Security Bearer
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req entities.OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	organization, err := h.organizationUseCase.CreateOrganization(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, organization)
+}
+
+// ListMyOrganizations godoc
+// @Summary List my organizations
+// @Description List the organizations the authenticated user is a member of, with their role
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} entities.OrganizationResponse
+// @Failure 401 {object} map[string]string
+// @Router /organizations [get]
+// @Security Bearer
+func (h *OrganizationHandler) ListMyOrganizations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	organizations, err := h.organizationUseCase.ListMyOrganizations(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, organizations)
+}
+
+// GetOrganization godoc
+// @Summary Get an organization
+// @Description Retrieve an organization the authenticated user is a member of
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} entities.OrganizationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/{id} [get]
+// @Security Bearer
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	organizationID, ok := uintParam(c, "id", "organization")
+	if !ok {
+		return
+	}
+
+	organization, err := h.organizationUseCase.GetOrganization(c.Request.Context(), userID, organizationID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// UpdateOrganizationBilling godoc
+// @Summary Update organization billing details
+// @Description Set the legal name, tax ID and address shown as the seller on the invoices of the organization's events. Invoices already issued keep the previous details. Only available to the owner and admins.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param billing body entities.OrganizationBillingRequest true "Billing details"
+// @Success 200 {object} entities.OrganizationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/{id}/billing [put]
+// @Security Bearer
+func (h *OrganizationHandler) UpdateOrganizationBilling(c *gin.Context) {
+	var req entities.OrganizationBillingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	organizationID, ok := uintParam(c, "id", "organization")
+	if !ok {
+		return
+	}
+
+	organization, err := h.organizationUseCase.UpdateBilling(c.Request.Context(), userID, organizationID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// ListOrganizationEvents godoc
+// @Summary List organization events
+// @Description Retrieve the events owned by an organization
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {array} entities.EventResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/{id}/events [get]
+// @Security Bearer
+func (h *OrganizationHandler) ListOrganizationEvents(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	organizationID, ok := uintParam(c, "id", "organization")
+	if !ok {
+		return
+	}
+
+	events, err := h.organizationUseCase.ListEvents(c.Request.Context(), userID, organizationID, 100, 0)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]entities.EventResponse, 0, len(events))
+	for _, event := range events {
+		response = append(response, newEventResponse(event))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListMembers godoc
+// @Summary List organization members
+// @Description Retrieve the members of an organization and their roles
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {array} entities.OrganizationMemberResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/{id}/members [get]
+// @Security Bearer
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	organizationID, ok := uintParam(c, "id", "organization")
+	if !ok {
+		return
+	}
+
+	members, err := h.organizationUseCase.ListMembers(c.Request.Context(), userID, organizationID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// UpdateMemberRole godoc
+// @Summary Change a member's role
+// @Description Change the role of an organization member. Only the owner can grant or revoke the admin role.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param userId path string true "Member user ID"
+// @Param role body entities.OrganizationMemberRoleRequest true "New role"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/{id}/members/{userId} [put]
+// @Security Bearer
+func (h *OrganizationHandler) UpdateMemberRole(c *gin.Context) {
+	var req entities.OrganizationMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	organizationID, ok := uintParam(c, "id", "organization")
+	if !ok {
+		return
+	}
+	memberUserID, ok := uintParam(c, "userId", "user")
+	if !ok {
+		return
+	}
+
+	err := h.organizationUseCase.UpdateMemberRole(c.Request.Context(), userID, organizationID, memberUserID, req.Role)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member role updated successfully"})
+}
+
+// RemoveMember godoc
+// @Summary Remove a member
+// @Description Remove a member from the organization, or leave it when removing yourself
+// @Tags organizations
+// @Param id path string true "Organization ID"
+// @Param userId path string true "Member user ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/{id}/members/{userId} [delete]
+// @Security Bearer
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	organizationID, ok := uintParam(c, "id", "organization")
+	if !ok {
+		return
+	}
+	memberUserID, ok := uintParam(c, "userId", "user")
+	if !ok {
+		return
+	}
+
+	err := h.organizationUseCase.RemoveMember(c.Request.Context(), userID, organizationID, memberUserID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// InviteMember godoc
+// @Summary Invite a member
+// @Description Send an invitation to join the organization to an email address
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param invitation body entities.OrganizationInvitationRequest true "Invitation data"
+// @Success 201 {object} entities.OrganizationInvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /organizations/{id}/invitations [post]
+// @Security Bearer
+func (h *OrganizationHandler) InviteMember(c *gin.Context) {
+	var req entities.OrganizationInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	organizationID, ok := uintParam(c, "id", "organization")
+	if !ok {
+		return
+	}
+
+	invitation, err := h.organizationUseCase.InviteMember(c.Request.Context(), userID, organizationID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListInvitations godoc
+// @Summary List pending invitations
+// @Description Retrieve the pending invitations of an organization
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {array} entities.OrganizationInvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/{id}/invitations [get]
+// @Security Bearer
+func (h *OrganizationHandler) ListInvitations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	organizationID, ok := uintParam(c, "id", "organization")
+	if !ok {
+		return
+	}
+
+	invitations, err := h.organizationUseCase.ListInvitations(c.Request.Context(), userID, organizationID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an invitation
+// @Description Join an organization using the token received by email
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param invitation body entities.AcceptInvitationRequest true "Invitation token"
+// @Success 200 {object} entities.OrganizationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /organizations/invitations/accept [post]
+// @Security Bearer
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	var req entities.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	organization, err := h.organizationUseCase.AcceptInvitation(c.Request.Context(), userID, req.Token)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// TransferOwnership godoc
+// @Summary Transfer ownership
+// @Description Make another member the owner of the organization. The current owner becomes an admin.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param transfer body entities.TransferOwnershipRequest true "New owner"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/{id}/transfer [post]
+// @Security Bearer
+func (h *OrganizationHandler) TransferOwnership(c *gin.Context) {
+	var req entities.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	organizationID, ok := uintParam(c, "id", "organization")
+	if !ok {
+		return
+	}
+
+	err := h.organizationUseCase.TransferOwnership(c.Request.Context(), userID, organizationID, req.UserID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ownership transferred successfully"})
+}
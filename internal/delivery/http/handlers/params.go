@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// uintParam parses the named path parameter, responding with 400 when it is
+// not a valid ID.
+func uintParam(c *gin.Context, name, label string) (uint, bool) {
+	value, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + label + " ID"})
+		return 0, false
+	}
+	return uint(value), true
+}
+
+// currentUserID returns the authenticated user, responding with 401 when the
+// request is not authenticated.
+func currentUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, false
+	}
+	return userID.(uint), true
+}
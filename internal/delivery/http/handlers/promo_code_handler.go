@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromoCodeHandler struct {
+	promoCodeUseCase *usecases.PromoCodeUseCase
+}
+
+func NewPromoCodeHandler(promoCodeUseCase *usecases.PromoCodeUseCase) *PromoCodeHandler {
+	return &PromoCodeHandler{promoCodeUseCase: promoCodeUseCase}
+}
+
+// CreatePromoCode godoc
+// @Summary Create a promo code
+// @Description Create a promo code for an event or for every event of an organization. Percentage discounts go from 1 to 100; fixed discounts are in minor units of the currency. Limits of 0 are unlimited, and an empty ticket_type_ids applies to every ticket type.
+// @Tags promo-codes
+// @Accept json
+// @Produce json
+// @Param promoCode body entities.PromoCodeRequest true "Promo code"
+// @Success 201 {object} entities.PromoCodeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /promo-codes [post]
+// @Security Bearer
+func (h *PromoCodeHandler) CreatePromoCode(c *gin.Context) {
+	var req entities.PromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	promoCode, err := h.promoCodeUseCase.CreatePromoCode(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, newPromoCodeResponse(promoCode, 0))
+}
+
+// ListPromoCodes godoc
+// @Summary List promo codes
+// @Description Retrieve the promo codes of an event or of an organization with how many times each one was redeemed. An event's list doesn't include its organization's codes.
+// @Tags promo-codes
+// @Produce json
+// @Param event_id query int false "Event ID"
+// @Param organization_id query int false "Organization ID"
+// @Success 200 {array} entities.PromoCodeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /promo-codes [get]
+// @Security Bearer
+func (h *PromoCodeHandler) ListPromoCodes(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := optionalUintQuery(c, "event_id")
+	if !ok {
+		return
+	}
+	organizationID, ok := optionalUintQuery(c, "organization_id")
+	if !ok {
+		return
+	}
+
+	promoCodes, redemptions, err := h.promoCodeUseCase.ListPromoCodes(c.Request.Context(), userID, eventID, organizationID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]entities.PromoCodeResponse, len(promoCodes))
+	for i, promoCode := range promoCodes {
+		response[i] = newPromoCodeResponse(promoCode, redemptions[promoCode.ID])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPromoCode godoc
+// @Summary Get a promo code
+// @Description Retrieve a promo code with how many times it was redeemed by pending or paid orders.
+// @Tags promo-codes
+// @Produce json
+// @Param id path string true "Promo code ID"
+// @Success 200 {object} entities.PromoCodeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /promo-codes/{id} [get]
+// @Security Bearer
+func (h *PromoCodeHandler) GetPromoCode(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := uintParam(c, "id", "promo code")
+	if !ok {
+		return
+	}
+
+	promoCode, redemptions, err := h.promoCodeUseCase.GetPromoCode(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newPromoCodeResponse(promoCode, redemptions))
+}
+
+// UpdatePromoCode godoc
+// @Summary Update a promo code
+// @Description Replace the definition of a promo code. Its event or organization can't change, and orders that already used it keep their discount.
+// @Tags promo-codes
+// @Accept json
+// @Produce json
+// @Param id path string true "Promo code ID"
+// @Param promoCode body entities.PromoCodeRequest true "Promo code"
+// @Success 200 {object} entities.PromoCodeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /promo-codes/{id} [put]
+// @Security Bearer
+func (h *PromoCodeHandler) UpdatePromoCode(c *gin.Context) {
+	var req entities.PromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := uintParam(c, "id", "promo code")
+	if !ok {
+		return
+	}
+
+	promoCode, redemptions, err := h.promoCodeUseCase.UpdatePromoCode(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newPromoCodeResponse(promoCode, redemptions))
+}
+
+// DeletePromoCode godoc
+// @Summary Delete a promo code
+// @Description Delete a promo code so it can't be used anymore. Orders that used it keep their discount.
+// @Tags promo-codes
+// @Param id path string true "Promo code ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /promo-codes/{id} [delete]
+// @Security Bearer
+func (h *PromoCodeHandler) DeletePromoCode(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := uintParam(c, "id", "promo code")
+	if !ok {
+		return
+	}
+
+	if err := h.promoCodeUseCase.DeletePromoCode(c.Request.Context(), userID, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// PromoCodeReport godoc
+// @Summary Get a promo code report
+// @Description Retrieve the redemptions of a promo code, newest first, with the redemptions of paid and pending orders and the discount and revenue of paid orders. Amounts are in minor units of the currency.
+// @Tags promo-codes
+// @Produce json
+// @Param id path string true "Promo code ID"
+// @Success 200 {object} entities.PromoCodeReport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /promo-codes/{id}/redemptions [get]
+// @Security Bearer
+func (h *PromoCodeHandler) PromoCodeReport(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := uintParam(c, "id", "promo code")
+	if !ok {
+		return
+	}
+
+	promoCode, redemptions, err := h.promoCodeUseCase.GetRedemptions(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	report := entities.PromoCodeReport{Redemptions: make([]entities.PromoRedemptionResponse, len(redemptions))}
+	for i, redemption := range redemptions {
+		order := &redemption.Order
+		switch order.Status {
+		case entities.OrderStatusPaid:
+			report.Paid++
+			report.TotalDiscount += redemption.Discount
+			report.Revenue += order.Amount - order.RefundedAmount
+		case entities.OrderStatusPending:
+			report.Pending++
+		}
+		report.Redemptions[i] = entities.PromoRedemptionResponse{
+			OrderID:     order.ID,
+			OrderStatus: order.Status,
+			EventID:     order.EventID,
+			UserID:      redemption.UserID,
+			Discount:    redemption.Discount,
+			Amount:      order.Amount,
+			Currency:    order.Currency,
+			CreatedAt:   redemption.CreatedAt,
+		}
+	}
+	report.PromoCode = newPromoCodeResponse(promoCode, report.Paid+report.Pending)
+	c.JSON(http.StatusOK, report)
+}
+
+// optionalUintQuery parses the named query parameter when present, responding
+// with 400 when it is not a valid ID.
+func optionalUintQuery(c *gin.Context, name string) (*uint, bool) {
+	text := c.Query(name)
+	if text == "" {
+		return nil, true
+	}
+	value, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+		return nil, false
+	}
+	id := uint(value)
+	return &id, true
+}
+
+func newPromoCodeResponse(promoCode *entities.PromoCode, redemptions int) entities.PromoCodeResponse {
+	return entities.PromoCodeResponse{
+		ID:             promoCode.ID,
+		Code:           promoCode.Code,
+		EventID:        promoCode.EventID,
+		OrganizationID: promoCode.OrganizationID,
+		DiscountType:   promoCode.DiscountType,
+		DiscountValue:  promoCode.DiscountValue,
+		Currency:       promoCode.Currency,
+		MaxRedemptions: promoCode.MaxRedemptions,
+		MaxPerUser:     promoCode.MaxPerUser,
+		ValidFrom:      promoCode.ValidFrom,
+		ValidUntil:     promoCode.ValidUntil,
+		TicketTypeIDs:  promoCode.TicketTypeIDs,
+		Redemptions:    redemptions,
+		CreatedAt:      promoCode.CreatedAt,
+	}
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"net/http"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RegistrationFormHandler struct {
+	formUseCase *usecases.RegistrationFormUseCase
+}
+
+func NewRegistrationFormHandler(formUseCase *usecases.RegistrationFormUseCase) *RegistrationFormHandler {
+	return &RegistrationFormHandler{formUseCase: formUseCase}
+}
+
+// ListQuestions godoc
+// @Summary List registration questions
+// @Description Retrieve the registration form of an event, to be answered when registering
+// @Tags events
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param invite query string false "Invite token"
+// @Success 200 {array} entities.RegistrationQuestion
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/questions [get]
+// @Security Bearer
+func (h *RegistrationFormHandler) ListQuestions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	questions, err := h.formUseCase.ListQuestions(c.Request.Context(), userID, eventID, c.Query("invite"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+// CreateQuestion godoc
+// @Summary Add a registration question
+// @Description Add a text, single_choice, multi_choice, checkbox or number question to the event's registration form
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param question body entities.RegistrationQuestionRequest true "Question data"
+// @Success 201 {object} entities.RegistrationQuestion
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/questions [post]
+// @Security Bearer
+func (h *RegistrationFormHandler) CreateQuestion(c *gin.Context) {
+	var req entities.RegistrationQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	question, err := h.formUseCase.CreateQuestion(c.Request.Context(), userID, eventID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, question)
+}
+
+// UpdateQuestion godoc
+// @Summary Update a registration question
+// @Description Replace the definition of a registration question
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param questionId path string true "Question ID"
+// @Param question body entities.RegistrationQuestionRequest true "Question data"
+// @Success 200 {object} entities.RegistrationQuestion
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/questions/{questionId} [put]
+// @Security Bearer
+func (h *RegistrationFormHandler) UpdateQuestion(c *gin.Context) {
+	var req entities.RegistrationQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+	questionID, ok := uintParam(c, "questionId", "question")
+	if !ok {
+		return
+	}
+
+	question, err := h.formUseCase.UpdateQuestion(c.Request.Context(), userID, eventID, questionID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// DeleteQuestion godoc
+// @Summary Delete a registration question
+// @Description Remove a question from the registration form together with its answers
+// @Tags events
+// @Param id path string true "Event ID"
+// @Param questionId path string true "Question ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/questions/{questionId} [delete]
+// @Security Bearer
+func (h *RegistrationFormHandler) DeleteQuestion(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+	questionID, ok := uintParam(c, "questionId", "question")
+	if !ok {
+		return
+	}
+
+	if err := h.formUseCase.DeleteQuestion(c.Request.Context(), userID, eventID, questionID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AnswerStats godoc
+// @Summary Registration answer statistics
+// @Description Aggregate the answers of the pending and confirmed registrations: option counts for choice and checkbox questions, min, max and average for number questions
+// @Tags events
+// @Produce json
+// @Param id path string true "Event ID"
+// @Success 200 {array} entities.QuestionStatsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/questions/stats [get]
+// @Security Bearer
+func (h *RegistrationFormHandler) AnswerStats(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	stats, err := h.formUseCase.AnswerStats(c.Request.Context(), userID, eventID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketTypeHandler struct {
+	ticketTypeUseCase *usecases.TicketTypeUseCase
+}
+
+func NewTicketTypeHandler(ticketTypeUseCase *usecases.TicketTypeUseCase) *TicketTypeHandler {
+	return &TicketTypeHandler{ticketTypeUseCase: ticketTypeUseCase}
+}
+
+// ListTicketTypes godoc
+// @Summary List ticket types
+// @Description Retrieve the ticket types of an event with the seats left. Hidden ticket types are only listed for organizers or with their unlock code.
+// @Tags events
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param invite query string false "Invite token"
+// @Param code query string false "Unlock code of a hidden ticket type"
+// @Success 200 {array} entities.TicketTypeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/ticket-types [get]
+// @Security Bearer
+func (h *TicketTypeHandler) ListTicketTypes(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	availability, organizer, err := h.ticketTypeUseCase.ListTicketTypes(c.Request.Context(), userID, eventID, c.Query("invite"), c.Query("code"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	response := make([]entities.TicketTypeResponse, len(availability))
+	for i, ticketType := range availability {
+		response[i] = newTicketTypeResponse(ticketType, organizer, now)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateTicketType godoc
+// @Summary Create a ticket type
+// @Description Add a ticket type to an event. The price is in minor units of the currency, and every ticket type of an event uses the same currency.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param ticketType body entities.TicketTypeRequest true "Ticket type"
+// @Success 201 {object} entities.TicketTypeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/ticket-types [post]
+// @Security Bearer
+func (h *TicketTypeHandler) CreateTicketType(c *gin.Context) {
+	var req entities.TicketTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+
+	ticketType, err := h.ticketTypeUseCase.CreateTicketType(c.Request.Context(), userID, eventID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	availability := entities.TicketTypeAvailability{TicketType: ticketType}
+	c.JSON(http.StatusCreated, newTicketTypeResponse(availability, true, time.Now()))
+}
+
+// UpdateTicketType godoc
+// @Summary Update a ticket type
+// @Description Replace the definition of a ticket type. The quantity can't be lower than the seats already taken.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param ticketTypeId path string true "Ticket type ID"
+// @Param ticketType body entities.TicketTypeRequest true "Ticket type"
+// @Success 200 {object} entities.TicketTypeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /events/{id}/ticket-types/{ticketTypeId} [put]
+// @Security Bearer
+func (h *TicketTypeHandler) UpdateTicketType(c *gin.Context) {
+	var req entities.TicketTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+	ticketTypeID, ok := uintParam(c, "ticketTypeId", "ticket type")
+	if !ok {
+		return
+	}
+
+	ticketType, err := h.ticketTypeUseCase.UpdateTicketType(c.Request.Context(), userID, eventID, ticketTypeID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	availability := entities.TicketTypeAvailability{TicketType: ticketType}
+	c.JSON(http.StatusOK, newTicketTypeResponse(availability, true, time.Now()))
+}
+
+// DeleteTicketType godoc
+// @Summary Delete a ticket type
+// @Description Delete a ticket type without pending or confirmed registrations. Events without max_capacity keep their last ticket type
+// @Tags events
+// @Param id path string true "Event ID"
+// @Param ticketTypeId path string true "Ticket type ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /events/{id}/ticket-types/{ticketTypeId} [delete]
+// @Security Bearer
+func (h *TicketTypeHandler) DeleteTicketType(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	eventID, ok := uintParam(c, "id", "event")
+	if !ok {
+		return
+	}
+	ticketTypeID, ok := uintParam(c, "ticketTypeId", "ticket type")
+	if !ok {
+		return
+	}
+
+	if err := h.ticketTypeUseCase.DeleteTicketType(c.Request.Context(), userID, eventID, ticketTypeID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// newTicketTypeResponse only includes the unlock code for organizers.
+func newTicketTypeResponse(availability entities.TicketTypeAvailability, organizer bool, now time.Time) entities.TicketTypeResponse {
+	ticketType := availability.TicketType
+	response := entities.TicketTypeResponse{
+		ID:           ticketType.ID,
+		EventID:      ticketType.EventID,
+		Name:         ticketType.Name,
+		Description:  ticketType.Description,
+		Price:        ticketType.Price,
+		Currency:     ticketType.Currency,
+		Quantity:     ticketType.Quantity,
+		Remaining:    availability.Remaining(),
+		SalesStartAt: ticketType.SalesStartAt,
+		SalesEndAt:   ticketType.SalesEndAt,
+		OnSale:       ticketType.OnSale(now),
+		MinPerOrder:  ticketType.MinPerOrder,
+		MaxPerOrder:  ticketType.MaxPerOrder,
+		Hidden:       ticketType.Hidden,
+		Position:     ticketType.Position,
+	}
+	if organizer {
+		response.UnlockCode = ticketType.UnlockCode
+	}
+	return response
+}
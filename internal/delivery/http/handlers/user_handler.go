@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	userUseCase *usecases.UserUseCase
+}
+
+func NewUserHandler(userUseCase *usecases.UserUseCase) *UserHandler {
+	return &UserHandler{userUseCase: userUseCase}
+}
+
+// GetPreferences godoc
+// @Summary Get my preferences
+// @Description Retrieve the privacy preferences of the authenticated user
+// @Tags users
+// @Produce json
+// @Success 200 {object} entities.UserPreferencesResponse
+// @Failure 401 {object} map[string]string
+// @Router /users/me/preferences [get]
+// @Security Bearer
+func (h *UserHandler) GetPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.userUseCase.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences godoc
+// @Summary Update my preferences
+// @Description Update the privacy preferences of the authenticated user, e.g. hiding them from attendee lists
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param preferences body entities.UserPreferencesRequest true "Preferences"
+// @Success 200 {object} entities.UserPreferencesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /users/me/preferences [put]
+// @Security Bearer
+func (h *UserHandler) UpdatePreferences(c *gin.Context) {
+	var req entities.UserPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.userUseCase.UpdatePreferences(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+func newUserResponse(user *entities.User) *entities.UserResponse {
+	return &entities.UserResponse{
+		ID:        user.ID,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		CreatedAt: user.CreatedAt,
+	}
+}
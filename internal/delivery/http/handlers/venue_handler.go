@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"net/http"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VenueHandler struct {
+	venueUseCase *usecases.VenueUseCase
+}
+
+func NewVenueHandler(venueUseCase *usecases.VenueUseCase) *VenueHandler {
+	return &VenueHandler{venueUseCase: venueUseCase}
+}
+
+// CreateVenue godoc
+// @Summary Create a venue
+// @Description Create a reusable venue, personal or owned by an organization
+// @Tags venues
+// @Accept json
+// @Produce json
+// @Param venue body entities.VenueRequest true "Venue data"
+// @Success 201 {object} entities.Venue
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /venues [post]
+// @Security Bearer
+func (h *VenueHandler) CreateVenue(c *gin.Context) {
+	var req entities.VenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	venue, err := h.venueUseCase.CreateVenue(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, venue)
+}
+
+// ListVenues godoc
+// @Summary List venues
+// @Description List the venues with their rooms
+// @Tags venues
+// @Produce json
+// @Success 200 {array} entities.Venue
+// @Failure 401 {object} map[string]string
+// @Router /venues [get]
+// @Security Bearer
+func (h *VenueHandler) ListVenues(c *gin.Context) {
+	venues, err := h.venueUseCase.ListVenues(c.Request.Context(), 100, 0)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, venues)
+}
+
+// GetVenue godoc
+// @Summary Get a venue
+// @Description Retrieve a venue with its rooms
+// @Tags venues
+// @Produce json
+// @Param id path string true "Venue ID"
+// @Success 200 {object} entities.Venue
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /venues/{id} [get]
+// @Security Bearer
+func (h *VenueHandler) GetVenue(c *gin.Context) {
+	venueID, ok := uintParam(c, "id", "venue")
+	if !ok {
+		return
+	}
+
+	venue, err := h.venueUseCase.GetVenue(c.Request.Context(), venueID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, venue)
+}
+
+// UpdateVenue godoc
+// @Summary Update a venue
+// @Description Replace the details of a venue
+// @Tags venues
+// @Accept json
+// @Produce json
+// @Param id path string true "Venue ID"
+// @Param venue body entities.VenueRequest true "Venue data"
+// @Success 200 {object} entities.Venue
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /venues/{id} [put]
+// @Security Bearer
+func (h *VenueHandler) UpdateVenue(c *gin.Context) {
+	var req entities.VenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	venueID, ok := uintParam(c, "id", "venue")
+	if !ok {
+		return
+	}
+
+	venue, err := h.venueUseCase.UpdateVenue(c.Request.Context(), userID, venueID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, venue)
+}
+
+// DeleteVenue godoc
+// @Summary Delete a venue
+// @Description Remove a venue together with its rooms
+// @Tags venues
+// @Param id path string true "Venue ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /venues/{id} [delete]
+// @Security Bearer
+func (h *VenueHandler) DeleteVenue(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	venueID, ok := uintParam(c, "id", "venue")
+	if !ok {
+		return
+	}
+
+	if err := h.venueUseCase.DeleteVenue(c.Request.Context(), userID, venueID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddRoom godoc
+// @Summary Add a room
+// @Description Add a room with its capacity to a venue
+// @Tags venues
+// @Accept json
+// @Produce json
+// @Param id path string true "Venue ID"
+// @Param room body entities.VenueRoomRequest true "Room data"
+// @Success 201 {object} entities.VenueRoom
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /venues/{id}/rooms [post]
+// @Security Bearer
+func (h *VenueHandler) AddRoom(c *gin.Context) {
+	var req entities.VenueRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	venueID, ok := uintParam(c, "id", "venue")
+	if !ok {
+		return
+	}
+
+	room, err := h.venueUseCase.AddRoom(c.Request.Context(), userID, venueID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, room)
+}
+
+// UpdateRoom godoc
+// @Summary Update a room
+// @Description Replace the details of a venue's room
+// @Tags venues
+// @Accept json
+// @Produce json
+// @Param id path string true "Venue ID"
+// @Param roomId path string true "Room ID"
+// @Param room body entities.VenueRoomRequest true "Room data"
+// @Success 200 {object} entities.VenueRoom
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /venues/{id}/rooms/{roomId} [put]
+// @Security Bearer
+func (h *VenueHandler) UpdateRoom(c *gin.Context) {
+	var req entities.VenueRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	venueID, ok := uintParam(c, "id", "venue")
+	if !ok {
+		return
+	}
+	roomID, ok := uintParam(c, "roomId", "room")
+	if !ok {
+		return
+	}
+
+	room, err := h.venueUseCase.UpdateRoom(c.Request.Context(), userID, venueID, roomID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, room)
+}
+
+// DeleteRoom godoc
+// @Summary Delete a room
+// @Description Remove a room from a venue
+// @Tags venues
+// @Param id path string true "Venue ID"
+// @Param roomId path string true "Room ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /venues/{id}/rooms/{roomId} [delete]
+// @Security Bearer
+func (h *VenueHandler) DeleteRoom(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	venueID, ok := uintParam(c, "id", "venue")
+	if !ok {
+		return
+	}
+	roomID, ok := uintParam(c, "roomId", "room")
+	if !ok {
+		return
+	}
+
+	if err := h.venueUseCase.DeleteRoom(c.Request.Context(), userID, venueID, roomID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -2,15 +2,19 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
-	"EventsAPI/internal/config"
+	"EventsAPI/internal/usecases"
 	"EventsAPI/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(config *config.Config) gin.HandlerFunc {
+// AuthMiddleware accepts either "Bearer {jwt}" or "ApiKey {key}". Requests
+// authenticated with an API key also get the key's scopes stored in the
+// context under "apiKeyScopes".
+func AuthMiddleware(jwtManager *utils.JWTManager, apiKeyUseCase *usecases.APIKeyUseCase) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -20,23 +24,69 @@ func AuthMiddleware(config *config.Config) gin.HandlerFunc {
 		}
 
 		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
+		if len(parts) != 2 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token} or ApiKey {key}"})
 			c.Abort()
 			return
 		}
 
-		token := parts[1]
-		claims, err := utils.ValidateJWT(token, config.JWT.Secret)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		switch strings.ToLower(parts[0]) {
+		case "bearer":
+			claims, err := jwtManager.ValidateJWT(parts[1])
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+				c.Abort()
+				return
+			}
+
+			// Store user info in context
+			c.Set("userID", claims.UserID)
+			c.Set("userEmail", claims.Email)
+		case "apikey":
+			apiKey, err := apiKeyUseCase.Authenticate(c.Request.Context(), parts[1])
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+				c.Abort()
+				return
+			}
+
+			c.Set("userID", apiKey.UserID)
+			c.Set("userEmail", apiKey.User.Email)
+			c.Set("apiKeyID", apiKey.ID)
+			c.Set("apiKeyScopes", apiKey.Scopes)
+		default:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token} or ApiKey {key}"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireScope rejects API key requests whose key lacks scope. Requests
+// authenticated with a JWT are not restricted.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, isAPIKey := c.Get("apiKeyScopes")
+		if isAPIKey && !slices.Contains(scopes.([]string), scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key is missing the " + scope + " scope"})
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
 
-		// Store user info in context
-		c.Set("userID", claims.UserID)
-		c.Set("userEmail", claims.Email)
+// RequireSession rejects requests authenticated with an API key, for
+// endpoints that must only be used by the user themselves.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isAPIKey := c.Get("apiKeyScopes"); isAPIKey {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint cannot be used with an API key"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -4,6 +4,9 @@ import (
 	"EventsAPI/internal/config"
 	"EventsAPI/internal/delivery/http/handlers"
 	"EventsAPI/internal/delivery/http/middleware"
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/usecases"
+	"EventsAPI/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -12,9 +15,23 @@ import (
 
 func SetupRoutes(
 	config *config.Config,
+	jwtManager *utils.JWTManager,
+	apiKeyUseCase *usecases.APIKeyUseCase,
 	authHandler *handlers.AuthHandler,
+	oidcHandler *handlers.OIDCHandler,
 	eventHandler *handlers.EventHandler,
 	attendeeHandler *handlers.AttendeeHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	organizationHandler *handlers.OrganizationHandler,
+	collaboratorHandler *handlers.EventCollaboratorHandler,
+	eventInvitationHandler *handlers.EventInvitationHandler,
+	registrationFormHandler *handlers.RegistrationFormHandler,
+	venueHandler *handlers.VenueHandler,
+	categoryHandler *handlers.CategoryHandler,
+	ticketTypeHandler *handlers.TicketTypeHandler,
+	orderHandler *handlers.OrderHandler,
+	promoCodeHandler *handlers.PromoCodeHandler,
+	userHandler *handlers.UserHandler,
 	healthHandler *handlers.HealthHandler,
 ) *gin.Engine {
 
@@ -45,41 +62,166 @@ func SetupRoutes(
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Public keys for verifying the API tokens
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	// API routes
 	api := router.Group("/api/v1")
 
 	// Health check
 	api.GET("/health", healthHandler.HealthCheck)
 
+	// Payment provider notifications (public, verified by their signature)
+	api.POST("/payments/webhook", orderHandler.PaymentWebhook)
+
 	// Auth routes (public)
 	auth := api.Group("/auth")
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
+		auth.GET("/oidc/:provider/start", oidcHandler.StartLogin)
+		auth.GET("/oidc/:provider/callback", oidcHandler.Callback)
 	}
 
 	// Protected routes
 	protected := api.Group("")
-	protected.Use(middleware.AuthMiddleware(config))
+	protected.Use(middleware.AuthMiddleware(jwtManager, apiKeyUseCase))
 	{
+		eventsRead := middleware.RequireScope(entities.ScopeEventsRead)
+		eventsWrite := middleware.RequireScope(entities.ScopeEventsWrite)
+		attendeesRead := middleware.RequireScope(entities.ScopeAttendeesRead)
+		attendeesWrite := middleware.RequireScope(entities.ScopeAttendeesWrite)
+
 		// Events routes
 		events := protected.Group("/events")
 		{
-			events.POST("", eventHandler.CreateEvent)
-			events.GET("", eventHandler.ListEvents)
-			events.GET("/my", eventHandler.GetMyEvents)
-			events.GET("/:id", eventHandler.GetEvent)
-			events.PUT("/:id", eventHandler.UpdateEvent)
-			events.DELETE("/:id", eventHandler.DeleteEvent)
+			events.POST("", eventsWrite, eventHandler.CreateEvent)
+			events.GET("", eventsRead, eventHandler.ListEvents)
+			events.GET("/my", eventsRead, eventHandler.GetMyEvents)
+			events.GET("/search", eventsRead, eventHandler.SearchEvents)
+			events.GET("/:id", eventsRead, eventHandler.GetEvent)
+			events.PUT("/:id", eventsWrite, eventHandler.UpdateEvent)
+			events.DELETE("/:id", eventsWrite, eventHandler.DeleteEvent)
+			events.GET("/:id/collaborators", eventsRead, collaboratorHandler.ListCollaborators)
+			events.POST("/:id/collaborators", eventsWrite, collaboratorHandler.AddCollaborator)
+			events.DELETE("/:id/collaborators/:userId", eventsWrite, collaboratorHandler.RemoveCollaborator)
+			events.GET("/:id/invite-links", eventsRead, eventInvitationHandler.ListInviteLinks)
+			events.POST("/:id/invite-links", eventsWrite, eventInvitationHandler.CreateInviteLink)
+			events.DELETE("/:id/invite-links/:linkId", eventsWrite, eventInvitationHandler.RevokeInviteLink)
+			events.GET("/:id/invitations", eventsRead, eventInvitationHandler.ListInvitations)
+			events.POST("/:id/invitations", eventsWrite, eventInvitationHandler.Invite)
+			events.GET("/:id/questions", eventsRead, registrationFormHandler.ListQuestions)
+			events.POST("/:id/questions", eventsWrite, registrationFormHandler.CreateQuestion)
+			events.GET("/:id/questions/stats", attendeesRead, registrationFormHandler.AnswerStats)
+			events.PUT("/:id/questions/:questionId", eventsWrite, registrationFormHandler.UpdateQuestion)
+			events.DELETE("/:id/questions/:questionId", eventsWrite, registrationFormHandler.DeleteQuestion)
+			events.GET("/:id/ticket-types", eventsRead, ticketTypeHandler.ListTicketTypes)
+			events.POST("/:id/ticket-types", eventsWrite, ticketTypeHandler.CreateTicketType)
+			events.PUT("/:id/ticket-types/:ticketTypeId", eventsWrite, ticketTypeHandler.UpdateTicketType)
+			events.DELETE("/:id/ticket-types/:ticketTypeId", eventsWrite, ticketTypeHandler.DeleteTicketType)
+			events.POST("/:id/orders", attendeesWrite, orderHandler.Checkout)
+			events.GET("/:id/orders", attendeesRead, orderHandler.ListEventOrders)
+			events.GET("/:id/revenue", attendeesRead, orderHandler.EventRevenue)
+			events.GET("/:id/attendees/export", attendeesRead, attendeeHandler.ExportAttendees)
+			events.POST("/:id/attendees/import", attendeesWrite, attendeeHandler.ImportAttendees)
+			events.GET("/:id/attendees/import/:importId", attendeesRead, attendeeHandler.GetAttendeeImport)
+		}
+
+		// Venues routes
+		venues := protected.Group("/venues")
+		{
+			venues.POST("", eventsWrite, venueHandler.CreateVenue)
+			venues.GET("", eventsRead, venueHandler.ListVenues)
+			venues.GET("/:id", eventsRead, venueHandler.GetVenue)
+			venues.PUT("/:id", eventsWrite, venueHandler.UpdateVenue)
+			venues.DELETE("/:id", eventsWrite, venueHandler.DeleteVenue)
+			venues.POST("/:id/rooms", eventsWrite, venueHandler.AddRoom)
+			venues.PUT("/:id/rooms/:roomId", eventsWrite, venueHandler.UpdateRoom)
+			venues.DELETE("/:id/rooms/:roomId", eventsWrite, venueHandler.DeleteRoom)
+		}
+
+		// Categories routes; changes are reserved to administrators
+		categories := protected.Group("/categories")
+		{
+			categories.GET("", eventsRead, categoryHandler.ListCategories)
+			categories.POST("", middleware.RequireSession(), categoryHandler.CreateCategory)
+			categories.PUT("/:id", middleware.RequireSession(), categoryHandler.UpdateCategory)
+			categories.DELETE("/:id", middleware.RequireSession(), categoryHandler.DeleteCategory)
 		}
 
 		// Attendees routes
 		attendees := protected.Group("/attendees")
 		{
-			attendees.POST("/register/:eventId", attendeeHandler.RegisterForEvent)
-			attendees.POST("/unregister/:eventId", attendeeHandler.UnregisterFromEvent)
-			attendees.GET("/my", attendeeHandler.GetMyRegistrations)
-			attendees.GET("/event/:eventId", attendeeHandler.GetEventAttendees)
+			attendees.POST("/register/:eventId", attendeesWrite, attendeeHandler.RegisterForEvent)
+			attendees.PUT("/register/:eventId/guests", attendeesWrite, attendeeHandler.UpdateGuests)
+			attendees.PUT("/register/:eventId/rsvp", attendeesWrite, attendeeHandler.UpdateRSVP)
+			attendees.POST("/unregister/:eventId", attendeesWrite, attendeeHandler.UnregisterFromEvent)
+			attendees.GET("/my", attendeesRead, attendeeHandler.GetMyRegistrations)
+			attendees.GET("/event/:eventId", attendeesRead, attendeeHandler.GetEventAttendees)
+			attendees.POST("/event/:eventId/check-in/:userId", attendeesWrite, attendeeHandler.CheckIn)
+			attendees.POST("/event/:eventId/approve", attendeesWrite, attendeeHandler.ApproveRegistrations)
+			attendees.POST("/event/:eventId/reject", attendeesWrite, attendeeHandler.RejectRegistrations)
+			attendees.POST("/event/:eventId/message", attendeesWrite, attendeeHandler.MessageAttendees)
+		}
+
+		// Orders routes
+		orders := protected.Group("/orders")
+		{
+			orders.GET("", attendeesRead, orderHandler.ListMyOrders)
+			orders.GET("/:id", attendeesRead, orderHandler.GetOrder)
+			orders.GET("/:id/invoice.pdf", attendeesRead, orderHandler.OrderInvoice)
+			orders.POST("/:id/cancel", attendeesWrite, orderHandler.CancelOrder)
+			orders.POST("/:id/refunds", attendeesWrite, orderHandler.RefundOrder)
+		}
+
+		// Promo codes routes
+		promoCodes := protected.Group("/promo-codes")
+		{
+			promoCodes.POST("", eventsWrite, promoCodeHandler.CreatePromoCode)
+			promoCodes.GET("", eventsRead, promoCodeHandler.ListPromoCodes)
+			promoCodes.GET("/:id", eventsRead, promoCodeHandler.GetPromoCode)
+			promoCodes.PUT("/:id", eventsWrite, promoCodeHandler.UpdatePromoCode)
+			promoCodes.DELETE("/:id", eventsWrite, promoCodeHandler.DeletePromoCode)
+			promoCodes.GET("/:id/redemptions", eventsRead, promoCodeHandler.PromoCodeReport)
+		}
+
+		// Organizations routes
+		organizations := protected.Group("/organizations")
+		organizations.Use(middleware.RequireSession())
+		{
+			organizations.POST("", organizationHandler.CreateOrganization)
+			organizations.GET("", organizationHandler.ListMyOrganizations)
+			organizations.POST("/invitations/accept", organizationHandler.AcceptInvitation)
+			organizations.GET("/:id", organizationHandler.GetOrganization)
+			organizations.PUT("/:id/billing", organizationHandler.UpdateOrganizationBilling)
+			organizations.GET("/:id/events", organizationHandler.ListOrganizationEvents)
+			organizations.GET("/:id/members", organizationHandler.ListMembers)
+			organizations.PUT("/:id/members/:userId", organizationHandler.UpdateMemberRole)
+			organizations.DELETE("/:id/members/:userId", organizationHandler.RemoveMember)
+			organizations.POST("/:id/invitations", organizationHandler.InviteMember)
+			organizations.GET("/:id/invitations", organizationHandler.ListInvitations)
+			organizations.POST("/:id/transfer", organizationHandler.TransferOwnership)
+		}
+
+		// Current user routes
+		users := protected.Group("/users/me")
+		users.Use(middleware.RequireSession())
+		{
+			users.GET("/preferences", userHandler.GetPreferences)
+			users.PUT("/preferences", userHandler.UpdatePreferences)
+			users.GET("/invitations", eventInvitationHandler.ListMyInvitations)
+			users.POST("/invitations/:invitationId/accept", eventInvitationHandler.AcceptInvitation)
+			users.POST("/invitations/:invitationId/decline", eventInvitationHandler.DeclineInvitation)
+			users.GET("/conflicts", attendeeHandler.ListMyConflicts)
+		}
+
+		// API key management is only available to the user themselves
+		apiKeys := protected.Group("/api-keys")
+		apiKeys.Use(middleware.RequireSession())
+		{
+			apiKeys.POST("", apiKeyHandler.CreateAPIKey)
+			apiKeys.GET("", apiKeyHandler.ListAPIKeys)
+			apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
 		}
 	}
 
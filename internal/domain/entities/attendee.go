@@ -1,31 +1,152 @@
 package entities
 
 import (
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// Attendee statuses. Only confirmed registrations and the ones awaiting
+// payment of their order take up capacity.
+const (
+	AttendeeStatusPending         = "pending"
+	AttendeeStatusConfirmed       = "confirmed"
+	AttendeeStatusAwaitingPayment = "awaiting_payment"
+	AttendeeStatusRejected        = "rejected"
+	AttendeeStatusCancelled       = "cancelled"
+)
+
+// SeatHoldingStatuses are the attendee statuses that take up seats when the
+// RSVP is going.
+var SeatHoldingStatuses = []string{AttendeeStatusConfirmed, AttendeeStatusAwaitingPayment}
+
+// RSVP answers. Only going takes up seats.
+const (
+	RSVPGoing    = "going"
+	RSVPMaybe    = "maybe"
+	RSVPNotGoing = "not_going"
+)
+
 type Attendee struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	EventID   uint           `json:"event_id" gorm:"not null"`
-	UserID    uint           `json:"user_id" gorm:"not null"`
-	Event     Event          `json:"event" gorm:"foreignKey:EventID"`
-	User      User           `json:"user" gorm:"foreignKey:UserID"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID            uint                 `json:"id" gorm:"primaryKey"`
+	EventID       uint                 `json:"event_id" gorm:"not null"`
+	UserID        uint                 `json:"user_id" gorm:"not null"`
+	Status        string               `json:"status" gorm:"not null;default:confirmed;index"`
+	StatusMessage string               `json:"status_message"`
+	RSVP          string               `json:"rsvp" gorm:"column:rsvp;not null;default:going;index"`
+	TicketTypeID  *uint                `json:"ticket_type_id" gorm:"index"`
+	InviteLinkID  *uint                `json:"-" gorm:"index"`
+	Event         Event                `json:"event" gorm:"foreignKey:EventID"`
+	User          User                 `json:"user" gorm:"foreignKey:UserID"`
+	GuestCount    int                  `json:"guest_count" gorm:"not null;default:0"`
+	GuestNames    []string             `json:"guest_names" gorm:"serializer:json"`
+	CheckedInAt   *time.Time           `json:"checked_in_at"`
+	Answers       []RegistrationAnswer `json:"answers" gorm:"foreignKey:AttendeeID"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt       `json:"-" gorm:"index"`
+}
+
+// Seats is the capacity the registration takes up: the user and their guests.
+func (a *Attendee) Seats() int {
+	return 1 + a.GuestCount
+}
+
+// IsGoing reports whether the registration takes up seats.
+func (a *Attendee) IsGoing() bool {
+	return slices.Contains(SeatHoldingStatuses, a.Status) && a.RSVP == RSVPGoing
+}
+
+// IsActive reports whether the registration is pending or confirmed.
+func (a *Attendee) IsActive() bool {
+	return a.Status == AttendeeStatusPending || a.Status == AttendeeStatusConfirmed
 }
 
 // Constraint: unique combination of EventID and UserID
 // This should be added in database migration or GORM constraint
+
+// AttendeeRequest is the optional body of a registration. The event is taken
+// from the path, so EventID may be omitted.
 type AttendeeRequest struct {
-	EventID uint `json:"event_id" binding:"required"`
+	EventID    uint                        `json:"event_id"`
+	RSVP       string                      `json:"rsvp" binding:"omitempty,oneof=going maybe not_going"`
+	GuestCount int                         `json:"guest_count" binding:"min=0"`
+	GuestNames []string                    `json:"guest_names"`
+	Answers    []RegistrationAnswerRequest `json:"answers" binding:"dive"`
+	// TicketTypeID is required when the event has ticket types. UnlockCode
+	// unlocks a hidden ticket type.
+	TicketTypeID *uint  `json:"ticket_type_id"`
+	UnlockCode   string `json:"unlock_code"`
+	// PromoCode discounts an order; it doesn't apply to free registrations.
+	PromoCode string `json:"promo_code" binding:"max=40"`
+	// Billing is who to invoice for an order; it is ignored by free
+	// registrations.
+	Billing *BillingDetails `json:"billing"`
+	// BlockOnConflict refuses the registration when the user is already
+	// registered for an overlapping event, instead of only warning.
+	BlockOnConflict bool `json:"block_on_conflict"`
+}
+
+// AttendeeGuestsRequest changes the guests of an existing registration.
+// GuestNames may name some or all of the guests.
+type AttendeeGuestsRequest struct {
+	GuestCount int      `json:"guest_count" binding:"min=0"`
+	GuestNames []string `json:"guest_names"`
+}
+type RSVPRequest struct {
+	RSVP string `json:"rsvp" binding:"required,oneof=going maybe not_going"`
+}
+
+// AttendeeMessageRequest messages the confirmed attendees with one RSVP.
+type AttendeeMessageRequest struct {
+	RSVP    string `json:"rsvp" binding:"required,oneof=going maybe not_going"`
+	Subject string `json:"subject" binding:"required"`
+	Body    string `json:"body" binding:"required"`
+}
+
+// AttendeeMessageResponse counts the attendees that were messaged and lists
+// the ones the message couldn't be sent to.
+type AttendeeMessageResponse struct {
+	Recipients int                      `json:"recipients"`
+	Failures   []AttendeeMessageFailure `json:"failures,omitempty"`
+}
+
+type AttendeeMessageFailure struct {
+	UserID uint   `json:"user_id"`
+	Error  string `json:"error"`
+}
+type AttendeeDecisionRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"required,min=1"`
+	Message string `json:"message"`
 }
 type AttendeeResponse struct {
-	ID      uint          `json:"id"`
-	EventID uint          `json:"event_id"`
-	UserID  uint          `json:"user_id"`
-	Event   EventResponse `json:"event,omitempty"`
-	User    UserResponse  `json:"user,omitempty"`
+	ID            uint                         `json:"id"`
+	EventID       uint                         `json:"event_id"`
+	UserID        uint                         `json:"user_id"`
+	Status        string                       `json:"status"`
+	StatusMessage string                       `json:"status_message,omitempty"`
+	RSVP          string                       `json:"rsvp"`
+	TicketTypeID  *uint                        `json:"ticket_type_id"`
+	Event         *EventResponse               `json:"event,omitempty"`
+	User          *UserResponse                `json:"user,omitempty"`
+	GuestCount    int                          `json:"guest_count"`
+	GuestNames    []string                     `json:"guest_names,omitempty"`
+	CheckedInAt   *time.Time                   `json:"checked_in_at"`
+	Answers       []RegistrationAnswerResponse `json:"answers,omitempty"`
+	CreatedAt     time.Time                    `json:"created_at"`
+}
+
+// AttendeeDecisionResponse is the outcome of approving or rejecting one
+// registration in a bulk decision.
+type AttendeeDecisionResponse struct {
+	UserID uint   `json:"user_id"`
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// PublicAttendeeResponse is the redacted projection shown to non organizers.
+type PublicAttendeeResponse struct {
+	FirstName   string `json:"first_name"`
+	LastInitial string `json:"last_initial"`
 }
@@ -0,0 +1,97 @@
+package entities
+
+import "time"
+
+// Attendee import statuses.
+const (
+	AttendeeImportRunning   = "running"
+	AttendeeImportCompleted = "completed"
+	AttendeeImportFailed    = "failed"
+)
+
+// Results of an imported row.
+const (
+	ImportRowCreated           = "created"
+	ImportRowAlreadyRegistered = "already_registered"
+	ImportRowRejected          = "rejected"
+	ImportRowInvalidEmail      = "invalid_email"
+	ImportRowCapacityExceeded  = "capacity_exceeded"
+	ImportRowInvited           = "invited"
+	ImportRowFailed            = "failed"
+)
+
+// AttendeeImportRow is the result of a row of an imported file. Row is its
+// line in the file, the header being line 1. Invited is set when the email
+// had no account: one was created for it or, with the invited result, the
+// person was asked to sign up and register.
+type AttendeeImportRow struct {
+	Row     int    `json:"row"`
+	Email   string `json:"email"`
+	Result  string `json:"result"`
+	UserID  uint   `json:"user_id,omitempty"`
+	Invited bool   `json:"invited,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AttendeeImportSummary counts the rows of an import by result.
+type AttendeeImportSummary struct {
+	Created           int `json:"created" gorm:"not null;default:0"`
+	AlreadyRegistered int `json:"already_registered" gorm:"not null;default:0"`
+	Rejected          int `json:"rejected" gorm:"not null;default:0"`
+	InvalidEmail      int `json:"invalid_email" gorm:"not null;default:0"`
+	CapacityExceeded  int `json:"capacity_exceeded" gorm:"not null;default:0"`
+	InvitedToSignUp   int `json:"invited_to_sign_up" gorm:"not null;default:0"`
+	Failed            int `json:"failed" gorm:"not null;default:0"`
+	Invited           int `json:"invited" gorm:"not null;default:0"`
+}
+
+// Add counts a row's result.
+func (s *AttendeeImportSummary) Add(row *AttendeeImportRow) {
+	switch row.Result {
+	case ImportRowCreated:
+		s.Created++
+	case ImportRowAlreadyRegistered:
+		s.AlreadyRegistered++
+	case ImportRowRejected:
+		s.Rejected++
+	case ImportRowInvalidEmail:
+		s.InvalidEmail++
+	case ImportRowCapacityExceeded:
+		s.CapacityExceeded++
+	case ImportRowInvited:
+		s.InvitedToSignUp++
+	default:
+		s.Failed++
+	}
+	if row.Invited {
+		s.Invited++
+	}
+}
+
+// AttendeeImport is a bulk registration of attendees from a CSV file. Large
+// files are imported in the background, so the import is kept to report its
+// progress and, once completed, the result of every row. A dry run reports
+// what the import would do without changing anything.
+type AttendeeImport struct {
+	ID           uint                  `json:"id" gorm:"primaryKey"`
+	EventID      uint                  `json:"event_id" gorm:"not null;index"`
+	CreatedByID  uint                  `json:"created_by_id" gorm:"not null"`
+	TicketTypeID *uint                 `json:"ticket_type_id"`
+	DryRun       bool                  `json:"dry_run" gorm:"not null;default:false"`
+	Status       string                `json:"status" gorm:"not null"`
+	Error        string                `json:"error,omitempty"`
+	Total        int                   `json:"total" gorm:"not null"`
+	Processed    int                   `json:"processed" gorm:"not null;default:0"`
+	Summary      AttendeeImportSummary `json:"summary" gorm:"embedded"`
+	Rows         []AttendeeImportRow   `json:"rows" gorm:"type:jsonb;serializer:json"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+	CompletedAt  *time.Time            `json:"completed_at"`
+}
+
+// AttendeeImportRequest holds the options sent along with the file.
+// TicketTypeID is required when the event has ticket types, and must be free.
+type AttendeeImportRequest struct {
+	DryRun       bool  `form:"dry_run"`
+	TicketTypeID *uint `form:"ticket_type_id"`
+}
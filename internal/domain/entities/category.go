@@ -0,0 +1,56 @@
+package entities
+
+import "time"
+
+// Category is a node of the event taxonomy managed by administrators.
+// Filtering by a category also matches the events of its subcategories.
+type Category struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	Name      string      `json:"name" gorm:"not null"`
+	Slug      string      `json:"slug" gorm:"uniqueIndex;not null"`
+	ParentID  *uint       `json:"parent_id" gorm:"index"`
+	Position  int         `json:"position" gorm:"not null;default:0"`
+	Children  []*Category `json:"children,omitempty" gorm:"-"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type CategoryRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Slug     string `json:"slug"`
+	ParentID *uint  `json:"parent_id"`
+	Position int    `json:"position"`
+}
+
+// EventFilter narrows event lists and searches. Every tag must match.
+type EventFilter struct {
+	CategoryID *uint
+	Tags       []string
+}
+
+// CategoryFacet counts the upcoming events of a category, its subcategories
+// included.
+type CategoryFacet struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	ParentID *uint  `json:"parent_id"`
+	Count    int    `json:"count"`
+}
+
+// TagFacet counts the upcoming events with a tag.
+type TagFacet struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+type EventFacets struct {
+	Categories []CategoryFacet `json:"categories"`
+	Tags       []TagFacet      `json:"tags"`
+}
+
+// EventListResponse is returned by the event list when facets are requested.
+type EventListResponse struct {
+	Events []EventResponse `json:"events"`
+	Facets EventFacets     `json:"facets"`
+}
@@ -6,35 +6,237 @@ import (
 	"gorm.io/gorm"
 )
 
+// Event visibility. Unlisted events are only reachable by direct link and
+// private events also require an invitation.
+const (
+	EventVisibilityPublic   = "public"
+	EventVisibilityUnlisted = "unlisted"
+	EventVisibilityPrivate  = "private"
+)
+
+// Search languages. Each event's text is stemmed in its own language.
+const (
+	EventLanguageSpanish = "spanish"
+	EventLanguageEnglish = "english"
+)
+
+// Attendee list visibility
+const (
+	AttendeeVisibilityPublic     = "public"
+	AttendeeVisibilityAttendees  = "attendees"
+	AttendeeVisibilityOrganizers = "organizers"
+)
+
 type Event struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	Title       string         `json:"title" gorm:"not null"`
-	Description string         `json:"description"`
-	Location    string         `json:"location" gorm:"not null"`
-	DateTime    time.Time      `json:"date_time" gorm:"not null"`
-	MaxCapacity int            `json:"max_capacity" gorm:"default:0"`
-	UserID      uint           `json:"user_id" gorm:"not null"`
-	User        User           `json:"user" gorm:"foreignKey:UserID"`
-	Attendees   []Attendee     `json:"attendees" gorm:"foreignKey:EventID"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                   uint           `json:"id" gorm:"primaryKey"`
+	Title                string         `json:"title" gorm:"not null"`
+	Description          string         `json:"description"`
+	Location             string         `json:"location" gorm:"not null"`
+	VenueID              *uint          `json:"venue_id" gorm:"index"`
+	RoomID               *uint          `json:"room_id" gorm:"index"`
+	Latitude             *float64       `json:"latitude" gorm:"index:idx_events_coordinates"`
+	Longitude            *float64       `json:"longitude" gorm:"index:idx_events_coordinates"`
+	DateTime             time.Time      `json:"date_time" gorm:"not null"`
+	EndsAt               *time.Time     `json:"ends_at"`
+	TimeZone             string         `json:"time_zone" gorm:"not null;default:UTC"`
+	AllDay               bool           `json:"all_day" gorm:"not null;default:false"`
+	MaxCapacity          int            `json:"max_capacity" gorm:"default:0"`
+	RequiresApproval     bool           `json:"requires_approval" gorm:"not null;default:false"`
+	MaxGuests            int            `json:"max_guests_per_registration" gorm:"not null;default:0"`
+	RegistrationOpensAt  *time.Time     `json:"registration_opens_at"`
+	RegistrationClosesAt *time.Time     `json:"registration_closes_at"`
+	CancellationDeadline *time.Time     `json:"cancellation_deadline"`
+	UserID               uint           `json:"user_id" gorm:"not null"`
+	User                 User           `json:"user" gorm:"foreignKey:UserID"`
+	OrganizationID       *uint          `json:"organization_id" gorm:"index"`
+	Visibility           string         `json:"visibility" gorm:"not null;default:public;index"`
+	AttendeeVisibility   string         `json:"attendee_visibility" gorm:"not null;default:organizers"`
+	CategoryID           *uint          `json:"category_id" gorm:"index"`
+	Tags                 []string       `json:"tags" gorm:"type:jsonb;not null;default:'[]';serializer:json;index:idx_events_tags,type:gin"`
+	Language             string         `json:"language" gorm:"not null;default:spanish"`
+	RefundPolicy         []RefundRule   `json:"refund_policy" gorm:"type:jsonb;not null;default:'[]';serializer:json"`
+	TaxRate              float64        `json:"tax_rate" gorm:"not null;default:0"`
+	TaxName              string         `json:"tax_name"`
+	SearchVector         string         `json:"-" gorm:"->;type:tsvector GENERATED ALWAYS AS (CASE language WHEN 'english' THEN setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(description, '')), 'B') || setweight(to_tsvector('english', coalesce(location, '')), 'C') ELSE setweight(to_tsvector('spanish', coalesce(title, '')), 'A') || setweight(to_tsvector('spanish', coalesce(description, '')), 'B') || setweight(to_tsvector('spanish', coalesce(location, '')), 'C') END) STORED;index:idx_events_search,type:gin"`
+	Attendees            []Attendee     `json:"attendees" gorm:"foreignKey:EventID"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Zone returns the event's time zone, falling back to UTC.
+func (e *Event) Zone() *time.Location {
+	loc, err := time.LoadLocation(e.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// EndsAtOrStart returns when the event ends, or when it starts if it has no
+// end. All-day events always have an end: midnight after their last day in the
+// event's time zone.
+func (e *Event) EndsAtOrStart() time.Time {
+	if e.EndsAt != nil {
+		return *e.EndsAt
+	}
+	return e.DateTime
+}
+
+// Overlaps reports whether the two events overlap in time. Events without an
+// end are treated as an instant, which overlaps events starting at the same
+// time.
+func (e *Event) Overlaps(other *Event) bool {
+	if e.DateTime.Equal(other.DateTime) {
+		return true
+	}
+	return e.DateTime.Before(other.EndsAtOrStart()) && other.DateTime.Before(e.EndsAtOrStart())
+}
+
+// AddLocalDays adds days to t keeping its wall-clock time in the event's time
+// zone, so the result stays correct across daylight saving changes. Recurring
+// events and reminders should use it instead of adding 24h multiples.
+func (e *Event) AddLocalDays(t time.Time, days int) time.Time {
+	local := t.In(e.Zone())
+	return time.Date(local.Year(), local.Month(), local.Day()+days,
+		local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), local.Location())
+}
+
+// RefundRule refunds Percent of a paid order to the attendees that cancel at
+// least DaysBefore days before the event starts.
+type RefundRule struct {
+	DaysBefore int `json:"days_before" binding:"min=0"`
+	Percent    int `json:"percent" binding:"min=0,max=100"`
 }
+
+// RefundPercent returns the percentage of a paid order refunded to an attendee
+// cancelling at the given time: the best rule that still applies, or 0.
+func (e *Event) RefundPercent(at time.Time) int {
+	percent := 0
+	for _, rule := range e.RefundPolicy {
+		if at.After(e.AddLocalDays(e.DateTime, -rule.DaysBefore)) {
+			continue
+		}
+		percent = max(percent, rule.Percent)
+	}
+	return percent
+}
+
+// RegistrationClosesAtOrStart returns when registration closes, by default
+// when the event starts.
+func (e *Event) RegistrationClosesAtOrStart() time.Time {
+	if e.RegistrationClosesAt != nil {
+		return *e.RegistrationClosesAt
+	}
+	return e.DateTime
+}
+
+// CancellationDeadlineOrStart returns the last moment attendees can cancel.
+func (e *Event) CancellationDeadlineOrStart() time.Time {
+	if e.CancellationDeadline != nil {
+		return *e.CancellationDeadline
+	}
+	return e.DateTime
+}
+
 type EventRequest struct {
-	Title       string    `json:"title" binding:"required"`
-	Description string    `json:"description"`
-	Location    string    `json:"location" binding:"required"`
-	DateTime    time.Time `json:"date_time" binding:"required"`
-	MaxCapacity int       `json:"max_capacity" binding:"min=0"`
+	Title                string     `json:"title" binding:"required"`
+	Description          string     `json:"description"`
+	Location             string     `json:"location" binding:"required_without=VenueID"`
+	VenueID              *uint      `json:"venue_id" binding:"required_with=RoomID"`
+	RoomID               *uint      `json:"room_id"`
+	Latitude             *float64   `json:"latitude" binding:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude            *float64   `json:"longitude" binding:"required_with=Latitude,omitempty,min=-180,max=180"`
+	DateTime             time.Time  `json:"date_time" binding:"required"`
+	EndsAt               *time.Time `json:"ends_at"`
+	TimeZone             string     `json:"time_zone" binding:"omitempty,timezone"`
+	AllDay               bool       `json:"all_day"`
+	MaxCapacity          int        `json:"max_capacity" binding:"min=0"`
+	RequiresApproval     bool       `json:"requires_approval"`
+	MaxGuests            int        `json:"max_guests_per_registration" binding:"min=0"`
+	RegistrationOpensAt  *time.Time `json:"registration_opens_at"`
+	RegistrationClosesAt *time.Time `json:"registration_closes_at"`
+	CancellationDeadline *time.Time `json:"cancellation_deadline"`
+	OrganizationID       *uint      `json:"organization_id"`
+	Visibility           string     `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+	AttendeeVisibility   string     `json:"attendee_visibility" binding:"omitempty,oneof=public attendees organizers"`
+	CategoryID           *uint      `json:"category_id"`
+	Tags                 []string   `json:"tags" binding:"max=20"`
+	Language             string     `json:"language" binding:"omitempty,oneof=spanish english"`
+	// RefundPolicy sets how much of a paid order is refunded when an
+	// attendee cancels; without rules cancellations aren't refunded.
+	RefundPolicy []RefundRule `json:"refund_policy" binding:"max=10,dive"`
+	// TaxRate is the percentage of tax included in the ticket prices, shown
+	// on invoices as TaxName.
+	TaxRate float64 `json:"tax_rate" binding:"min=0,max=100"`
+	TaxName string  `json:"tax_name" binding:"max=20"`
+}
+
+// NearbyEvent is an event found by a proximity search with its distance from
+// the searched point.
+type NearbyEvent struct {
+	Event      *Event
+	DistanceKm float64
+}
+
+// EventSearchResult is an event matched by a full-text search, with its rank
+// and highlighted title and description snippet.
+type EventSearchResult struct {
+	Event          *Event
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
+
+// EventSearchResponse is an EventResponse with the search rank and
+// highlights. Matched words are wrapped in <mark> tags.
+type EventSearchResponse struct {
+	EventResponse
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+// RSVPCounts counts the confirmed registrations by RSVP answer.
+type RSVPCounts struct {
+	Going    int `json:"going"`
+	Maybe    int `json:"maybe"`
+	NotGoing int `json:"not_going"`
 }
 type EventResponse struct {
-	ID             uint      `json:"id"`
-	Title          string    `json:"title"`
-	Description    string    `json:"description"`
-	Location       string    `json:"location"`
-	DateTime       time.Time `json:"date_time"`
-	MaxCapacity    int       `json:"max_capacity"`
-	UserID         uint      `json:"user_id"`
-	AttendeesCount int       `json:"attendees_count"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID                   uint         `json:"id"`
+	Title                string       `json:"title"`
+	Description          string       `json:"description"`
+	Location             string       `json:"location"`
+	VenueID              *uint        `json:"venue_id"`
+	RoomID               *uint        `json:"room_id"`
+	Latitude             *float64     `json:"latitude"`
+	Longitude            *float64     `json:"longitude"`
+	DistanceKm           *float64     `json:"distance_km,omitempty"`
+	DateTime             time.Time    `json:"date_time"`
+	EndsAt               *time.Time   `json:"ends_at"`
+	TimeZone             string       `json:"time_zone"`
+	AllDay               bool         `json:"all_day"`
+	LocalDateTime        time.Time    `json:"local_date_time"`
+	LocalEndsAt          *time.Time   `json:"local_ends_at"`
+	MaxCapacity          int          `json:"max_capacity"`
+	RequiresApproval     bool         `json:"requires_approval"`
+	MaxGuests            int          `json:"max_guests_per_registration"`
+	RegistrationOpensAt  *time.Time   `json:"registration_opens_at"`
+	RegistrationClosesAt *time.Time   `json:"registration_closes_at"`
+	CancellationDeadline *time.Time   `json:"cancellation_deadline"`
+	SeatsTaken           int          `json:"seats_taken"`
+	RSVPCounts           RSVPCounts   `json:"rsvp_counts"`
+	UserID               uint         `json:"user_id"`
+	OrganizationID       *uint        `json:"organization_id"`
+	Visibility           string       `json:"visibility"`
+	AttendeeVisibility   string       `json:"attendee_visibility"`
+	CategoryID           *uint        `json:"category_id"`
+	Tags                 []string     `json:"tags"`
+	Language             string       `json:"language"`
+	RefundPolicy         []RefundRule `json:"refund_policy"`
+	TaxRate              float64      `json:"tax_rate"`
+	TaxName              string       `json:"tax_name,omitempty"`
+	AttendeesCount       int          `json:"attendees_count"`
+	CreatedAt            time.Time    `json:"created_at"`
 }
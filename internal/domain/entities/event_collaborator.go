@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+// Event collaborator roles
+const (
+	EventRoleCoOrganizer = "co_organizer"
+	EventRoleStaff       = "staff"
+)
+
+// EventCollaborator grants a user a role on a single event, independently of
+// organizations.
+type EventCollaborator struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	EventID     uint      `json:"event_id" gorm:"not null;uniqueIndex:idx_event_collaborator"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_event_collaborator;index"`
+	Role        string    `json:"role" gorm:"not null"`
+	InvitedByID uint      `json:"invited_by_id" gorm:"not null"`
+	User        User      `json:"user" gorm:"foreignKey:UserID"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type EventCollaboratorRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=co_organizer staff"`
+}
+type EventCollaboratorResponse struct {
+	UserID    uint      `json:"user_id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,75 @@
+package entities
+
+import "time"
+
+// Event invitation statuses
+const (
+	EventInvitationPending  = "pending"
+	EventInvitationAccepted = "accepted"
+	EventInvitationDeclined = "declined"
+)
+
+// EventInviteLink backs a shareable, signed invite token for a private event.
+// The token only identifies the link; expiry, usage limits and revocation are
+// checked against this record.
+type EventInviteLink struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	EventID     uint       `json:"event_id" gorm:"not null;index"`
+	CreatedByID uint       `json:"created_by_id" gorm:"not null"`
+	MaxUses     *int       `json:"max_uses"`
+	Uses        int        `json:"uses" gorm:"not null;default:0"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// IsUsable reports whether the link can still admit someone.
+func (l *EventInviteLink) IsUsable(now time.Time) bool {
+	if l.RevokedAt != nil {
+		return false
+	}
+	if l.ExpiresAt != nil && !now.Before(*l.ExpiresAt) {
+		return false
+	}
+	return l.MaxUses == nil || l.Uses < *l.MaxUses
+}
+
+// EventInvitation invites an email address to a private event.
+type EventInvitation struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	EventID     uint       `json:"event_id" gorm:"not null;uniqueIndex:idx_event_invitation"`
+	Email       string     `json:"email" gorm:"not null;uniqueIndex:idx_event_invitation;index"`
+	Status      string     `json:"status" gorm:"not null;default:pending"`
+	InvitedByID uint       `json:"invited_by_id" gorm:"not null"`
+	RespondedAt *time.Time `json:"responded_at"`
+	Event       Event      `json:"-" gorm:"foreignKey:EventID"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type EventInviteLinkRequest struct {
+	MaxUses   *int       `json:"max_uses" binding:"omitempty,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+type EventInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+type EventInviteLinkResponse struct {
+	ID        uint       `json:"id"`
+	Token     string     `json:"token"`
+	MaxUses   *int       `json:"max_uses"`
+	Uses      int        `json:"uses"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+type EventInvitationResponse struct {
+	ID          uint       `json:"id"`
+	EventID     uint       `json:"event_id"`
+	EventTitle  string     `json:"event_title"`
+	Email       string     `json:"email"`
+	Status      string     `json:"status"`
+	RespondedAt *time.Time `json:"responded_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
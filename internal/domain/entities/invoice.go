@@ -0,0 +1,87 @@
+package entities
+
+import (
+	"math"
+	"time"
+)
+
+// Invoice kinds. A credit note corrects an invoice by the amount of a refund.
+const (
+	InvoiceKindInvoice    = "invoice"
+	InvoiceKindCreditNote = "credit_note"
+)
+
+// BillingDetails identify the buyer on the invoice of an order.
+type BillingDetails struct {
+	Name       string `json:"name" binding:"required,max=200"`
+	Company    string `json:"company" binding:"max=200"`
+	TaxID      string `json:"tax_id" binding:"max=40"`
+	Address    string `json:"address" binding:"max=300"`
+	City       string `json:"city" binding:"max=100"`
+	PostalCode string `json:"postal_code" binding:"max=20"`
+	Country    string `json:"country" binding:"omitempty,iso3166_1_alpha2"`
+	Email      string `json:"email" binding:"omitempty,email"`
+}
+
+// InvoiceIssuer identifies the seller on an invoice: the event's
+// organization, or the organizer of a personal event.
+type InvoiceIssuer struct {
+	Name    string `json:"name"`
+	TaxID   string `json:"tax_id"`
+	Address string `json:"address"`
+}
+
+// InvoiceLine is a line of an invoice. Amounts include tax.
+type InvoiceLine struct {
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitPrice   int64  `json:"unit_price"`
+	Amount      int64  `json:"amount"`
+}
+
+// Invoice is an invoice of a paid order or a credit note of one of its
+// refunds. Numbers are sequential per issuer and kind, without gaps. The
+// issuer, buyer, lines and tax are copied when it is issued so it never
+// changes afterwards. Amounts are in the currency's minor units and are
+// positive for credit notes too.
+type Invoice struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Kind string `json:"kind" gorm:"not null;uniqueIndex:idx_invoice_number"`
+	// Series is the issuer the number belongs to, like "organization-3" or
+	// "user-5".
+	Series   string `json:"series" gorm:"not null;uniqueIndex:idx_invoice_number"`
+	Sequence int    `json:"sequence" gorm:"not null;uniqueIndex:idx_invoice_number"`
+	Number   string `json:"number" gorm:"not null"`
+	OrderID  uint   `json:"order_id" gorm:"not null;index;uniqueIndex:idx_invoice_order,where:kind = 'invoice'"`
+	// RefundID and CorrectsID are set on credit notes: the refund they
+	// document and the invoice they correct.
+	RefundID       *uint          `json:"refund_id" gorm:"uniqueIndex"`
+	CorrectsID     *uint          `json:"corrects_id"`
+	CorrectsNumber string         `json:"corrects_number,omitempty"`
+	Issuer         InvoiceIssuer  `json:"issuer" gorm:"type:jsonb;not null;serializer:json"`
+	Billing        BillingDetails `json:"billing" gorm:"type:jsonb;not null;serializer:json"`
+	Lines          []InvoiceLine  `json:"lines" gorm:"type:jsonb;not null;serializer:json"`
+	Currency       string         `json:"currency" gorm:"type:char(3);not null"`
+	Net            int64          `json:"net" gorm:"not null"`
+	TaxName        string         `json:"tax_name"`
+	TaxRate        float64        `json:"tax_rate" gorm:"not null;default:0"`
+	Tax            int64          `json:"tax" gorm:"not null"`
+	Total          int64          `json:"total" gorm:"not null"`
+	IssuedAt       time.Time      `json:"issued_at" gorm:"not null"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// InvoiceSequence holds the last number issued in a series for a kind of
+// invoice.
+type InvoiceSequence struct {
+	Series string `gorm:"primaryKey"`
+	Kind   string `gorm:"primaryKey"`
+	Last   int    `gorm:"not null"`
+}
+
+// SplitTax splits a total that includes tax at rate percent into its net
+// amount and tax, rounding the tax to the nearest minor unit.
+func SplitTax(total int64, rate float64) (net, tax int64) {
+	tax = int64(math.Round(float64(total) * rate / (100 + rate)))
+	return total - tax, tax
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// OIDCLoginState keeps the PKCE verifier and nonce of an in-flight
+// authorization code flow until the provider redirects back.
+type OIDCLoginState struct {
+	ID           uint      `gorm:"primaryKey"`
+	State        string    `gorm:"uniqueIndex;not null"`
+	Provider     string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	Nonce        string    `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time
+}
@@ -0,0 +1,109 @@
+package entities
+
+import "time"
+
+// Order statuses. A pending order holds its seats until it expires; an
+// unfulfilled order was paid after its seats had been released to others.
+const (
+	OrderStatusPending     = "pending"
+	OrderStatusPaid        = "paid"
+	OrderStatusFailed      = "failed"
+	OrderStatusExpired     = "expired"
+	OrderStatusCancelled   = "cancelled"
+	OrderStatusUnfulfilled = "unfulfilled"
+)
+
+// Refund states of an order, summarizing its refunds.
+const (
+	OrderRefundPending  = "pending"
+	OrderRefundPartial  = "partially_refunded"
+	OrderRefundRefunded = "refunded"
+	OrderRefundFailed   = "failed"
+)
+
+// Order is the purchase of paid tickets of one ticket type for a
+// registration: the buyer and their guests. Amounts are in the currency's
+// minor units.
+type Order struct {
+	ID           uint  `json:"id" gorm:"primaryKey"`
+	EventID      uint  `json:"event_id" gorm:"not null;index"`
+	UserID       uint  `json:"user_id" gorm:"not null;index"`
+	AttendeeID   uint  `json:"attendee_id" gorm:"not null;index"`
+	TicketTypeID uint  `json:"ticket_type_id" gorm:"not null;index"`
+	Quantity     int   `json:"quantity" gorm:"not null"`
+	UnitPrice    int64 `json:"unit_price" gorm:"not null"`
+	Amount       int64 `json:"amount" gorm:"not null"`
+	// Discount was taken off UnitPrice × Quantity by PromoCode to get
+	// Amount.
+	Discount  int64  `json:"discount" gorm:"not null;default:0"`
+	PromoCode string `json:"promo_code"`
+	// Billing is who the buyer wants invoiced; without it the invoice is
+	// made out to the buyer's name and email.
+	Billing     *BillingDetails `json:"billing" gorm:"type:jsonb;serializer:json"`
+	Currency    string          `json:"currency" gorm:"type:char(3);not null"`
+	Status      string          `json:"status" gorm:"not null;default:pending;index"`
+	ExpiresAt   time.Time       `json:"expires_at" gorm:"not null;index"`
+	Provider    string          `json:"provider" gorm:"not null"`
+	PaymentID   string          `json:"payment_id" gorm:"index"`
+	CheckoutURL string          `json:"checkout_url"`
+	PaidAt      *time.Time      `json:"paid_at"`
+	// RefundedAmount sums the succeeded refunds. RefundStatus is empty until
+	// a refund is requested.
+	RefundedAmount int64      `json:"refunded_amount" gorm:"not null;default:0"`
+	RefundStatus   string     `json:"refund_status"`
+	Refunds        []Refund   `json:"-" gorm:"foreignKey:OrderID"`
+	Event          Event      `json:"-" gorm:"foreignKey:EventID"`
+	User           User       `json:"-" gorm:"foreignKey:UserID"`
+	TicketType     TicketType `json:"-" gorm:"foreignKey:TicketTypeID"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type OrderResponse struct {
+	ID             uint             `json:"id"`
+	EventID        uint             `json:"event_id"`
+	EventTitle     string           `json:"event_title"`
+	UserID         uint             `json:"user_id"`
+	User           *UserResponse    `json:"user,omitempty"`
+	AttendeeID     uint             `json:"attendee_id"`
+	TicketTypeID   uint             `json:"ticket_type_id"`
+	TicketTypeName string           `json:"ticket_type_name"`
+	Quantity       int              `json:"quantity"`
+	UnitPrice      int64            `json:"unit_price"`
+	Discount       int64            `json:"discount"`
+	PromoCode      string           `json:"promo_code,omitempty"`
+	Billing        *BillingDetails  `json:"billing,omitempty"`
+	Amount         int64            `json:"amount"`
+	Currency       string           `json:"currency"`
+	Status         string           `json:"status"`
+	ExpiresAt      time.Time        `json:"expires_at"`
+	CheckoutURL    string           `json:"checkout_url,omitempty"`
+	PaidAt         *time.Time       `json:"paid_at"`
+	RefundedAmount int64            `json:"refunded_amount"`
+	RefundStatus   string           `json:"refund_status,omitempty"`
+	Refunds        []RefundResponse `json:"refunds,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+// TicketTypeRevenue sums the paid orders of a ticket type.
+type TicketTypeRevenue struct {
+	TicketTypeID uint   `json:"ticket_type_id"`
+	Name         string `json:"name"`
+	Currency     string `json:"currency"`
+	Orders       int    `json:"orders"`
+	TicketsSold  int    `json:"tickets_sold"`
+	Amount       int64  `json:"amount"`
+	Refunded     int64  `json:"refunded"`
+}
+
+// EventRevenueResponse sums the paid orders of an event. Amount is what was
+// charged and Refunded what was given back of it.
+type EventRevenueResponse struct {
+	EventID      uint                `json:"event_id"`
+	Currency     string              `json:"currency"`
+	Orders       int                 `json:"orders"`
+	TicketsSold  int                 `json:"tickets_sold"`
+	Amount       int64               `json:"amount"`
+	Refunded     int64               `json:"refunded"`
+	ByTicketType []TicketTypeRevenue `json:"by_ticket_type"`
+}
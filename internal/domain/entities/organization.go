@@ -0,0 +1,99 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Organization roles
+const (
+	OrganizationRoleOwner        = "owner"
+	OrganizationRoleAdmin        = "admin"
+	OrganizationRoleEditor       = "editor"
+	OrganizationRoleCheckInStaff = "check_in_staff"
+)
+
+type Organization struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"not null"`
+	// LegalName, TaxID and BillingAddress identify the organization as the
+	// seller on the invoices of its events.
+	LegalName      string               `json:"legal_name"`
+	TaxID          string               `json:"tax_id"`
+	BillingAddress string               `json:"billing_address"`
+	Members        []OrganizationMember `json:"members" gorm:"foreignKey:OrganizationID"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt       `json:"-" gorm:"index"`
+}
+
+type OrganizationMember struct {
+	ID             uint         `json:"id" gorm:"primaryKey"`
+	OrganizationID uint         `json:"organization_id" gorm:"not null;uniqueIndex:idx_organization_member"`
+	UserID         uint         `json:"user_id" gorm:"not null;uniqueIndex:idx_organization_member;index"`
+	Role           string       `json:"role" gorm:"not null"`
+	Organization   Organization `json:"-" gorm:"foreignKey:OrganizationID"`
+	User           User         `json:"user" gorm:"foreignKey:UserID"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// OrganizationInvitation invites an email address to join an organization.
+// Only the SHA-256 hash of the invitation token is stored.
+type OrganizationInvitation struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	OrganizationID uint       `json:"organization_id" gorm:"not null;index"`
+	Email          string     `json:"email" gorm:"not null"`
+	Role           string     `json:"role" gorm:"not null"`
+	TokenHash      string     `json:"-" gorm:"uniqueIndex;not null"`
+	InvitedByID    uint       `json:"invited_by_id" gorm:"not null"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"not null"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type OrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+type OrganizationBillingRequest struct {
+	LegalName      string `json:"legal_name" binding:"max=200"`
+	TaxID          string `json:"tax_id" binding:"max=40"`
+	BillingAddress string `json:"billing_address" binding:"max=300"`
+}
+type OrganizationInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=admin editor check_in_staff"`
+}
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+type OrganizationMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin editor check_in_staff"`
+}
+type TransferOwnershipRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+type OrganizationResponse struct {
+	ID             uint      `json:"id"`
+	Name           string    `json:"name"`
+	LegalName      string    `json:"legal_name,omitempty"`
+	TaxID          string    `json:"tax_id,omitempty"`
+	BillingAddress string    `json:"billing_address,omitempty"`
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+type OrganizationMemberResponse struct {
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Role      string `json:"role"`
+}
+type OrganizationInvitationResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
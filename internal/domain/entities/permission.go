@@ -0,0 +1,64 @@
+package entities
+
+import "slices"
+
+// Permission is an action on an event or organization that is checked against
+// the caller's role.
+type Permission string
+
+const (
+	PermissionCreateEvent       Permission = "event:create"
+	PermissionEditEvent         Permission = "event:edit"
+	PermissionDeleteEvent       Permission = "event:delete"
+	PermissionViewAttendees     Permission = "attendees:view"
+	PermissionManageAttendees   Permission = "attendees:manage"
+	PermissionCheckInAttendees  Permission = "attendees:check_in"
+	PermissionManageEventTeam   Permission = "event:manage_collaborators"
+	PermissionViewOrders        Permission = "orders:view"
+	PermissionRefundOrders      Permission = "orders:refund"
+	PermissionManagePromoCodes  Permission = "promo_codes:manage"
+	PermissionManageVenues      Permission = "venue:manage"
+	PermissionManageMembers     Permission = "organization:manage_members"
+	PermissionManageBilling     Permission = "organization:billing"
+	PermissionTransferOwnership Permission = "organization:transfer"
+)
+
+var organizationRolePermissions = map[string][]Permission{
+	OrganizationRoleOwner: {
+		PermissionCreateEvent, PermissionEditEvent, PermissionDeleteEvent,
+		PermissionViewAttendees, PermissionManageAttendees, PermissionCheckInAttendees, PermissionManageEventTeam,
+		PermissionViewOrders, PermissionRefundOrders, PermissionManagePromoCodes, PermissionManageVenues, PermissionManageMembers,
+		PermissionManageBilling, PermissionTransferOwnership,
+	},
+	OrganizationRoleAdmin: {
+		PermissionCreateEvent, PermissionEditEvent, PermissionDeleteEvent,
+		PermissionViewAttendees, PermissionManageAttendees, PermissionCheckInAttendees, PermissionManageEventTeam,
+		PermissionViewOrders, PermissionRefundOrders, PermissionManagePromoCodes, PermissionManageVenues, PermissionManageMembers,
+		PermissionManageBilling,
+	},
+	OrganizationRoleEditor: {
+		PermissionCreateEvent, PermissionEditEvent,
+		PermissionViewAttendees, PermissionManageAttendees, PermissionCheckInAttendees,
+		PermissionManagePromoCodes, PermissionManageVenues,
+	},
+	OrganizationRoleCheckInStaff: {
+		PermissionViewAttendees, PermissionCheckInAttendees,
+	},
+}
+
+// OrganizationRoleHasPermission reports whether an organization role grants
+// the permission.
+func OrganizationRoleHasPermission(role string, permission Permission) bool {
+	return slices.Contains(organizationRolePermissions[role], permission)
+}
+
+var eventRolePermissions = map[string][]Permission{
+	EventRoleCoOrganizer: {PermissionEditEvent, PermissionViewAttendees, PermissionManageAttendees, PermissionCheckInAttendees, PermissionViewOrders, PermissionManagePromoCodes},
+	EventRoleStaff:       {PermissionCheckInAttendees},
+}
+
+// EventRoleHasPermission reports whether a per-event collaborator role grants
+// the permission.
+func EventRoleHasPermission(role string, permission Permission) bool {
+	return slices.Contains(eventRolePermissions[role], permission)
+}
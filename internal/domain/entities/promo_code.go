@@ -0,0 +1,130 @@
+package entities
+
+import (
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Promo code discount types: a percentage of the order or a fixed amount in
+// minor units of the event's currency.
+const (
+	DiscountPercentage = "percentage"
+	DiscountFixed      = "fixed"
+)
+
+// PromoCode discounts the orders of one event, or of every event of an
+// organization. A redemption counts against its limits while its order is
+// pending or paid, so expired and cancelled orders give it back.
+type PromoCode struct {
+	ID             uint   `json:"id" gorm:"primaryKey"`
+	Code           string `json:"code" gorm:"not null;index"`
+	EventID        *uint  `json:"event_id" gorm:"index"`
+	OrganizationID *uint  `json:"organization_id" gorm:"index"`
+	DiscountType   string `json:"discount_type" gorm:"not null"`
+	// DiscountValue is a percentage from 1 to 100, or an amount in minor
+	// units of Currency.
+	DiscountValue int64  `json:"discount_value" gorm:"not null"`
+	Currency      string `json:"currency" gorm:"type:char(3)"`
+	// MaxRedemptions and MaxPerUser are unlimited when 0.
+	MaxRedemptions int        `json:"max_redemptions" gorm:"not null;default:0"`
+	MaxPerUser     int        `json:"max_per_user" gorm:"not null;default:0"`
+	ValidFrom      *time.Time `json:"valid_from"`
+	ValidUntil     *time.Time `json:"valid_until"`
+	// TicketTypeIDs restricts the code to some ticket types; empty means any.
+	TicketTypeIDs []uint         `json:"ticket_type_ids" gorm:"type:jsonb;not null;default:'[]';serializer:json"`
+	CreatedByID   uint           `json:"created_by_id" gorm:"not null"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ValidAt reports whether t is within the code's validity window.
+func (p *PromoCode) ValidAt(t time.Time) bool {
+	if p.ValidFrom != nil && t.Before(*p.ValidFrom) {
+		return false
+	}
+	return p.ValidUntil == nil || t.Before(*p.ValidUntil)
+}
+
+// AppliesTo reports whether the code can be used for the ticket type.
+func (p *PromoCode) AppliesTo(ticketTypeID uint) bool {
+	return len(p.TicketTypeIDs) == 0 || slices.Contains(p.TicketTypeIDs, ticketTypeID)
+}
+
+// Discount returns the discount on subtotal, never more than subtotal.
+func (p *PromoCode) Discount(subtotal int64) int64 {
+	if p.DiscountType == DiscountPercentage {
+		return subtotal * p.DiscountValue / 100
+	}
+	return min(p.DiscountValue, subtotal)
+}
+
+// PromoRedemption is the use of a promo code by an order.
+type PromoRedemption struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	PromoCodeID uint      `json:"promo_code_id" gorm:"not null;index"`
+	OrderID     uint      `json:"order_id" gorm:"not null;uniqueIndex"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	Discount    int64     `json:"discount" gorm:"not null"`
+	Order       Order     `json:"-" gorm:"foreignKey:OrderID"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PromoCodeRequest creates or replaces a promo code. Exactly one of EventID
+// and OrganizationID must be set.
+type PromoCodeRequest struct {
+	Code           string     `json:"code" binding:"required,max=40"`
+	EventID        *uint      `json:"event_id" binding:"required_without=OrganizationID,excluded_with=OrganizationID"`
+	OrganizationID *uint      `json:"organization_id"`
+	DiscountType   string     `json:"discount_type" binding:"required,oneof=percentage fixed"`
+	DiscountValue  int64      `json:"discount_value" binding:"required,min=1"`
+	Currency       string     `json:"currency" binding:"omitempty,iso4217"`
+	MaxRedemptions int        `json:"max_redemptions" binding:"min=0"`
+	MaxPerUser     int        `json:"max_per_user" binding:"min=0"`
+	ValidFrom      *time.Time `json:"valid_from"`
+	ValidUntil     *time.Time `json:"valid_until"`
+	TicketTypeIDs  []uint     `json:"ticket_type_ids"`
+}
+
+type PromoCodeResponse struct {
+	ID             uint       `json:"id"`
+	Code           string     `json:"code"`
+	EventID        *uint      `json:"event_id"`
+	OrganizationID *uint      `json:"organization_id"`
+	DiscountType   string     `json:"discount_type"`
+	DiscountValue  int64      `json:"discount_value"`
+	Currency       string     `json:"currency,omitempty"`
+	MaxRedemptions int        `json:"max_redemptions"`
+	MaxPerUser     int        `json:"max_per_user"`
+	ValidFrom      *time.Time `json:"valid_from"`
+	ValidUntil     *time.Time `json:"valid_until"`
+	TicketTypeIDs  []uint     `json:"ticket_type_ids"`
+	Redemptions    int        `json:"redemptions"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// PromoRedemptionResponse is a use of a promo code in its report.
+type PromoRedemptionResponse struct {
+	OrderID     uint      `json:"order_id"`
+	OrderStatus string    `json:"order_status"`
+	EventID     uint      `json:"event_id"`
+	UserID      uint      `json:"user_id"`
+	Discount    int64     `json:"discount"`
+	Amount      int64     `json:"amount"`
+	Currency    string    `json:"currency"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PromoCodeReport sums the redemptions of a promo code: the ones of paid
+// orders, the ones still awaiting payment, and the discount given on and the
+// revenue kept from paid orders, net of refunds.
+type PromoCodeReport struct {
+	PromoCode     PromoCodeResponse         `json:"promo_code"`
+	Paid          int                       `json:"paid"`
+	Pending       int                       `json:"pending"`
+	TotalDiscount int64                     `json:"total_discount"`
+	Revenue       int64                     `json:"revenue"`
+	Redemptions   []PromoRedemptionResponse `json:"redemptions"`
+}
@@ -0,0 +1,54 @@
+package entities
+
+import "time"
+
+// Refund statuses. Providers may complete a refund later through their
+// webhook.
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
+
+// Reasons of the automatic refunds. Manual refunds carry the organizer's
+// reason.
+const (
+	RefundReasonAttendeeCancelled = "attendee_cancelled"
+	RefundReasonEventCancelled    = "event_cancelled"
+	RefundReasonUnfulfilled       = "unfulfilled"
+)
+
+// Refund returns part or all of a paid order's amount through the payment
+// provider. Amounts are in the currency's minor units.
+type Refund struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	OrderID          uint      `json:"order_id" gorm:"not null;index"`
+	Amount           int64     `json:"amount" gorm:"not null"`
+	Currency         string    `json:"currency" gorm:"type:char(3);not null"`
+	Status           string    `json:"status" gorm:"not null;default:pending;index"`
+	Reason           string    `json:"reason"`
+	RequestedByID    *uint     `json:"requested_by_id"`
+	Provider         string    `json:"provider" gorm:"not null"`
+	ProviderRefundID string    `json:"provider_refund_id" gorm:"index"`
+	FailureMessage   string    `json:"failure_message"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// OrderRefundRequest issues a manual refund of an order. Without an amount
+// the rest of the order is refunded.
+type OrderRefundRequest struct {
+	Amount *int64 `json:"amount" binding:"omitempty,min=1"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type RefundResponse struct {
+	ID             uint      `json:"id"`
+	Amount         int64     `json:"amount"`
+	Currency       string    `json:"currency"`
+	Status         string    `json:"status"`
+	Reason         string    `json:"reason"`
+	Manual         bool      `json:"manual"`
+	FailureMessage string    `json:"failure_message,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,111 @@
+package entities
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Registration question types
+const (
+	QuestionTypeText         = "text"
+	QuestionTypeSingleChoice = "single_choice"
+	QuestionTypeMultiChoice  = "multi_choice"
+	QuestionTypeCheckbox     = "checkbox"
+	QuestionTypeNumber       = "number"
+)
+
+// QuestionRules are the optional validation rules of a question. Each rule
+// only applies to the question types it makes sense for.
+type QuestionRules struct {
+	MinLength     *int     `json:"min_length,omitempty"`
+	MaxLength     *int     `json:"max_length,omitempty"`
+	Pattern       string   `json:"pattern,omitempty"`
+	Min           *float64 `json:"min,omitempty"`
+	Max           *float64 `json:"max,omitempty"`
+	Integer       bool     `json:"integer,omitempty"`
+	MinSelections *int     `json:"min_selections,omitempty"`
+	MaxSelections *int     `json:"max_selections,omitempty"`
+}
+
+// RegistrationQuestion is a field of an event's registration form.
+type RegistrationQuestion struct {
+	ID        uint          `json:"id" gorm:"primaryKey"`
+	EventID   uint          `json:"event_id" gorm:"not null;index"`
+	Label     string        `json:"label" gorm:"not null"`
+	Type      string        `json:"type" gorm:"not null"`
+	Required  bool          `json:"required" gorm:"not null;default:false"`
+	Options   []string      `json:"options" gorm:"serializer:json"`
+	Rules     QuestionRules `json:"rules" gorm:"serializer:json"`
+	Position  int           `json:"position" gorm:"not null;default:0"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// RegistrationAnswer is an attendee's answer to a question. Value holds the
+// normalized answer: the text, the number, "true"/"false" for checkboxes or
+// the selected options.
+type RegistrationAnswer struct {
+	ID         uint                 `json:"id" gorm:"primaryKey"`
+	AttendeeID uint                 `json:"attendee_id" gorm:"not null;uniqueIndex:idx_registration_answer"`
+	QuestionID uint                 `json:"question_id" gorm:"not null;uniqueIndex:idx_registration_answer;index"`
+	Value      []string             `json:"value" gorm:"serializer:json"`
+	Question   RegistrationQuestion `json:"-" gorm:"foreignKey:QuestionID"`
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+}
+
+// JSONValue converts the stored answer back to the JSON shape it was submitted
+// in. Question must be loaded.
+func (a *RegistrationAnswer) JSONValue() any {
+	switch a.Question.Type {
+	case QuestionTypeMultiChoice:
+		return a.Value
+	case QuestionTypeNumber:
+		if len(a.Value) == 1 {
+			if number, err := strconv.ParseFloat(a.Value[0], 64); err == nil {
+				return number
+			}
+		}
+	case QuestionTypeCheckbox:
+		return len(a.Value) == 1 && a.Value[0] == "true"
+	}
+	return strings.Join(a.Value, ", ")
+}
+
+type RegistrationQuestionRequest struct {
+	Label    string        `json:"label" binding:"required"`
+	Type     string        `json:"type" binding:"required,oneof=text single_choice multi_choice checkbox number"`
+	Required bool          `json:"required"`
+	Options  []string      `json:"options"`
+	Rules    QuestionRules `json:"rules"`
+	Position int           `json:"position"`
+}
+
+// RegistrationAnswerRequest carries the answer as plain JSON: a string for
+// text and single choice questions, a number, a boolean for checkboxes or an
+// array of strings for multiple choice questions.
+type RegistrationAnswerRequest struct {
+	QuestionID uint            `json:"question_id" binding:"required"`
+	Value      json.RawMessage `json:"value" swaggertype:"object"`
+}
+type RegistrationAnswerResponse struct {
+	QuestionID uint   `json:"question_id"`
+	Label      string `json:"label"`
+	Value      any    `json:"value"`
+}
+
+// QuestionStatsResponse aggregates the answers of the active registrations to
+// a question. OptionCounts is set for choice and checkbox questions and the
+// numeric fields for number questions.
+type QuestionStatsResponse struct {
+	QuestionID   uint           `json:"question_id"`
+	Label        string         `json:"label"`
+	Type         string         `json:"type"`
+	Responses    int            `json:"responses"`
+	OptionCounts map[string]int `json:"option_counts,omitempty"`
+	Min          *float64       `json:"min,omitempty"`
+	Max          *float64       `json:"max,omitempty"`
+	Average      *float64       `json:"average,omitempty"`
+}
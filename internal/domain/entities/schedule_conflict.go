@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// ScheduleConflict pairs two events that overlap in time.
+type ScheduleConflict struct {
+	Event         *Event
+	ConflictsWith *Event
+}
+
+// ConflictingEventResponse is the short form of an event in a conflict.
+type ConflictingEventResponse struct {
+	ID       uint       `json:"id"`
+	Title    string     `json:"title"`
+	Location string     `json:"location"`
+	DateTime time.Time  `json:"date_time"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+// ScheduleConflictResponse is a pair of the user's registrations whose events
+// overlap.
+type ScheduleConflictResponse struct {
+	Event         ConflictingEventResponse `json:"event"`
+	ConflictsWith ConflictingEventResponse `json:"conflicts_with"`
+}
@@ -0,0 +1,96 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultCurrency is used for ticket types created without a currency.
+const DefaultCurrency = "EUR"
+
+// TicketType is a kind of ticket for an event. Price is in the currency's
+// minor units (cents for EUR). Hidden ticket types are only offered to
+// organizers and to users that know their UnlockCode.
+type TicketType struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	EventID      uint           `json:"event_id" gorm:"not null;index"`
+	Name         string         `json:"name" gorm:"not null"`
+	Description  string         `json:"description"`
+	Price        int64          `json:"price" gorm:"not null;default:0"`
+	Currency     string         `json:"currency" gorm:"type:char(3);not null;default:EUR"`
+	Quantity     int            `json:"quantity" gorm:"not null"`
+	SalesStartAt *time.Time     `json:"sales_start_at"`
+	SalesEndAt   *time.Time     `json:"sales_end_at"`
+	MinPerOrder  int            `json:"min_per_order" gorm:"not null;default:1"`
+	MaxPerOrder  int            `json:"max_per_order" gorm:"not null;default:0"`
+	Hidden       bool           `json:"hidden" gorm:"not null;default:false"`
+	UnlockCode   string         `json:"-"`
+	Position     int            `json:"position" gorm:"not null;default:0"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// IsFree reports whether the ticket type costs nothing.
+func (t *TicketType) IsFree() bool {
+	return t.Price == 0
+}
+
+// OnSale reports whether the ticket type's sales window contains now.
+func (t *TicketType) OnSale(now time.Time) bool {
+	if t.SalesStartAt != nil && now.Before(*t.SalesStartAt) {
+		return false
+	}
+	return t.SalesEndAt == nil || now.Before(*t.SalesEndAt)
+}
+
+// TicketTypeRequest creates or replaces a ticket type. A zero MaxPerOrder
+// means no limit per order.
+type TicketTypeRequest struct {
+	Name         string     `json:"name" binding:"required"`
+	Description  string     `json:"description"`
+	Price        int64      `json:"price" binding:"min=0"`
+	Currency     string     `json:"currency" binding:"omitempty,iso4217"`
+	Quantity     int        `json:"quantity" binding:"required,min=1"`
+	SalesStartAt *time.Time `json:"sales_start_at"`
+	SalesEndAt   *time.Time `json:"sales_end_at"`
+	MinPerOrder  int        `json:"min_per_order" binding:"min=0"`
+	MaxPerOrder  int        `json:"max_per_order" binding:"min=0"`
+	Hidden       bool       `json:"hidden"`
+	UnlockCode   string     `json:"unlock_code" binding:"required_if=Hidden true"`
+	Position     int        `json:"position"`
+}
+
+// TicketTypeResponse is a ticket type with the seats left. UnlockCode is only
+// shown to organizers.
+type TicketTypeResponse struct {
+	ID           uint       `json:"id"`
+	EventID      uint       `json:"event_id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	Price        int64      `json:"price"`
+	Currency     string     `json:"currency"`
+	Quantity     int        `json:"quantity"`
+	Remaining    int        `json:"remaining"`
+	SalesStartAt *time.Time `json:"sales_start_at"`
+	SalesEndAt   *time.Time `json:"sales_end_at"`
+	OnSale       bool       `json:"on_sale"`
+	MinPerOrder  int        `json:"min_per_order"`
+	MaxPerOrder  int        `json:"max_per_order"`
+	Hidden       bool       `json:"hidden"`
+	UnlockCode   string     `json:"unlock_code,omitempty"`
+	Position     int        `json:"position"`
+}
+
+// TicketTypeAvailability is a ticket type with the seats taken by the
+// attendees that are going, including the ones awaiting payment.
+type TicketTypeAvailability struct {
+	TicketType *TicketType
+	Taken      int
+}
+
+// Remaining returns the seats left, never negative.
+func (a TicketTypeAvailability) Remaining() int {
+	return max(a.TicketType.Quantity-a.Taken, 0)
+}
@@ -7,15 +7,17 @@ import (
 )
 
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null"`
-	Password  string         `json:"-" gorm:"not null"`
-	FirstName string         `json:"first_name" gorm:"not null"`
-	LastName  string         `json:"last_name" gorm:"not null"`
-	Events    []Event        `json:"events" gorm:"foreignKey:UserID"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                    uint           `json:"id" gorm:"primaryKey"`
+	Email                 string         `json:"email" gorm:"uniqueIndex;not null"`
+	Password              string         `json:"-" gorm:"not null"`
+	FirstName             string         `json:"first_name" gorm:"not null"`
+	LastName              string         `json:"last_name" gorm:"not null"`
+	HideFromAttendeeLists bool           `json:"hide_from_attendee_lists" gorm:"not null;default:false"`
+	IsAdmin               bool           `json:"-" gorm:"not null;default:false"`
+	Events                []Event        `json:"events" gorm:"foreignKey:UserID"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt `json:"-" gorm:"index"`
 }
 type UserRequest struct {
 	Email     string `json:"email" binding:"required,email"`
@@ -28,9 +30,17 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
+	ID        uint   `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	// IsAdmin is only set when users see their own account.
+	IsAdmin   bool      `json:"is_admin,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
+type UserPreferencesRequest struct {
+	HideFromAttendeeLists *bool `json:"hide_from_attendee_lists" binding:"required"`
+}
+type UserPreferencesResponse struct {
+	HideFromAttendeeLists bool `json:"hide_from_attendee_lists"`
+}
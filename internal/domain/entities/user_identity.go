@@ -0,0 +1,20 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentity links a user to an account at an external identity provider.
+type UserIdentity struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"not null;index"`
+	Provider  string         `json:"provider" gorm:"not null;uniqueIndex:idx_user_identity_provider_subject"`
+	Subject   string         `json:"subject" gorm:"not null;uniqueIndex:idx_user_identity_provider_subject"`
+	Email     string         `json:"email"`
+	User      User           `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
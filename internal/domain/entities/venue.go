@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Venue is a reusable location for events. Personal venues are managed by
+// their creator and organization venues by the organization's members whose
+// role grants PermissionManageVenues. Any organizer can hold events at any
+// venue.
+type Venue struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	Name              string         `json:"name" gorm:"not null"`
+	Address           string         `json:"address" gorm:"not null"`
+	City              string         `json:"city"`
+	Country           string         `json:"country"`
+	Latitude          *float64       `json:"latitude"`
+	Longitude         *float64       `json:"longitude"`
+	AccessibilityInfo string         `json:"accessibility_info"`
+	UserID            uint           `json:"user_id" gorm:"not null;index"`
+	OrganizationID    *uint          `json:"organization_id" gorm:"index"`
+	Rooms             []VenueRoom    `json:"rooms" gorm:"foreignKey:VenueID"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// VenueRoom is a room of a venue; events held in it can't take more people
+// than its capacity.
+type VenueRoom struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	VenueID           uint           `json:"venue_id" gorm:"not null;index"`
+	Name              string         `json:"name" gorm:"not null"`
+	Capacity          int            `json:"capacity" gorm:"not null"`
+	AccessibilityInfo string         `json:"accessibility_info"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// FullAddress joins the venue's name and address for display.
+func (v *Venue) FullAddress() string {
+	address := v.Name + ", " + v.Address
+	if v.City != "" {
+		address += ", " + v.City
+	}
+	if v.Country != "" {
+		address += ", " + v.Country
+	}
+	return address
+}
+
+type VenueRequest struct {
+	Name              string   `json:"name" binding:"required"`
+	Address           string   `json:"address" binding:"required"`
+	City              string   `json:"city"`
+	Country           string   `json:"country"`
+	Latitude          *float64 `json:"latitude" binding:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude         *float64 `json:"longitude" binding:"required_with=Latitude,omitempty,min=-180,max=180"`
+	AccessibilityInfo string   `json:"accessibility_info"`
+	OrganizationID    *uint    `json:"organization_id"`
+}
+
+type VenueRoomRequest struct {
+	Name              string `json:"name" binding:"required"`
+	Capacity          int    `json:"capacity" binding:"required,min=1"`
+	AccessibilityInfo string `json:"accessibility_info"`
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+	"time"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, apiKey *entities.APIKey) error
+	GetByID(ctx context.Context, id uint) (*entities.APIKey, error)
+	// GetByPrefix returns the key together with its User.
+	GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error)
+	GetByUserID(ctx context.Context, userID uint) ([]*entities.APIKey, error)
+	Revoke(ctx context.Context, id uint, revokedAt time.Time) error
+	TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error
+}
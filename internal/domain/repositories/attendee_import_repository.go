@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+	"time"
+)
+
+type AttendeeImportRepository interface {
+	Create(ctx context.Context, attendeeImport *entities.AttendeeImport) error
+	GetByID(ctx context.Context, id uint) (*entities.AttendeeImport, error)
+	Update(ctx context.Context, attendeeImport *entities.AttendeeImport) error
+	// FailRunning marks the running imports not updated since updatedBefore
+	// as failed with message, returning how many there were.
+	FailRunning(ctx context.Context, updatedBefore time.Time, message string) (int64, error)
+}
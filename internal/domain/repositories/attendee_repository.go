@@ -3,14 +3,71 @@ package repositories
 import (
 	"EventsAPI/internal/domain/entities"
 	"context"
+	"time"
 )
 
 type AttendeeRepository interface {
 	Create(ctx context.Context, attendee *entities.Attendee) error
 	GetByID(ctx context.Context, id uint) (*entities.Attendee, error)
-	GetByEventID(ctx context.Context, eventID uint, limit, offset int) ([]*entities.Attendee, error)
+	// Get returns the user's registration for the event, whatever its status,
+	// with its User.
+	Get(ctx context.Context, eventID, userID uint) (*entities.Attendee, error)
+	// GetByEventID returns the attendees with their User and Answers,
+	// optionally only the ones with the given status.
+	GetByEventID(ctx context.Context, eventID uint, status string, limit, offset int) ([]*entities.Attendee, error)
+	// EachByEventID calls fn with the event's attendees, optionally only the
+	// ones with the given status, with their User and Answers. They are loaded
+	// in batches ordered by ID so large events are never held in memory at
+	// once. It stops at the first error fn returns.
+	EachByEventID(ctx context.Context, eventID uint, status string, fn func(attendees []*entities.Attendee) error) error
+	// GetVisibleByEventID returns the confirmed attendees that are going,
+	// leaving out the users that chose to be hidden from attendee lists,
+	// except for viewerID.
+	GetVisibleByEventID(ctx context.Context, eventID, viewerID uint, limit, offset int) ([]*entities.Attendee, error)
+	// GetConfirmedByRSVP returns the confirmed attendees with the given RSVP,
+	// with their User.
+	GetConfirmedByRSVP(ctx context.Context, eventID uint, rsvp string) ([]*entities.Attendee, error)
+	// GetByUserID returns the user's registrations with their Event.
 	GetByUserID(ctx context.Context, userID uint, limit, offset int) ([]*entities.Attendee, error)
-	Delete(ctx context.Context, eventID, userID uint) error
+	// GetOverlapping returns the user's pending or confirmed registrations,
+	// other than for event and not answered "not going", whose events overlap
+	// event, with their Event.
+	GetOverlapping(ctx context.Context, userID uint, event *entities.Event) ([]*entities.Attendee, error)
+	// GetUpcomingByUserID returns the user's pending or confirmed
+	// registrations not answered "not going" for events that haven't ended by
+	// from, with their Event, ordered by start time.
+	GetUpcomingByUserID(ctx context.Context, userID uint, from time.Time) ([]*entities.Attendee, error)
+	Update(ctx context.Context, attendee *entities.Attendee) error
+	// IsUserRegistered reports whether the user has a pending or confirmed
+	// registration for the event.
 	IsUserRegistered(ctx context.Context, eventID, userID uint) (bool, error)
-	CountByEventID(ctx context.Context, eventID uint) (int64, error)
+	// CountSeatsByEventID counts the seats taken by the going registrations
+	// that are confirmed or awaiting payment, guests included.
+	CountSeatsByEventID(ctx context.Context, eventID uint) (int, error)
+	// CountSeatsByTicketType counts the seats taken by the going
+	// registrations that are confirmed or awaiting payment, guests included,
+	// per ticket type.
+	CountSeatsByTicketType(ctx context.Context, eventID uint) (map[uint]int, error)
+	// SaveWithinCapacity creates or updates the attendee while holding a lock
+	// on its event, so concurrent registrations can't oversell it. check gets
+	// the seats taken by the event's other going attendees, in total and with
+	// the attendee's ticket type, and aborts the save when it returns an
+	// error.
+	SaveWithinCapacity(ctx context.Context, attendee *entities.Attendee, check func(eventSeats, ticketSeats int) error) error
+	// Register saves a new or reopened registration like SaveWithinCapacity.
+	// When the attendee has an InviteLinkID, a use of the link is counted in
+	// the same transaction, failing with gorm.ErrRecordNotFound when the link
+	// is revoked, expired or used up.
+	Register(ctx context.Context, attendee *entities.Attendee, now time.Time, check func(eventSeats, ticketSeats int) error) error
+	// CancelReservation cancels a registration awaiting payment, giving back
+	// the use of the invite link that admitted it.
+	CancelReservation(ctx context.Context, id uint) error
+	// Cancel saves the cancelled attendee and, when refund isn't nil, creates
+	// the refund of its order like RefundRepository.CreateWithinOrder in the
+	// same transaction, so a cancellation is never saved without the refund
+	// it is owed.
+	Cancel(ctx context.Context, attendee *entities.Attendee, refund *entities.Refund, check func(order *entities.Order, refunded int64) error) error
+	// CheckIn records the check-in time, returning gorm.ErrRecordNotFound when
+	// the user has no confirmed registration for the event.
+	CheckIn(ctx context.Context, eventID, userID uint, checkedInAt time.Time) error
 }
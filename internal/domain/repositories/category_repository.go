@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *entities.Category) error
+	GetByID(ctx context.Context, id uint) (*entities.Category, error)
+	GetBySlug(ctx context.Context, slug string) (*entities.Category, error)
+	// List returns every category ordered by position and name.
+	List(ctx context.Context) ([]*entities.Category, error)
+	Update(ctx context.Context, category *entities.Category) error
+	Delete(ctx context.Context, id uint) error
+	// CountEvents counts the events in the category, not in its subcategories.
+	CountEvents(ctx context.Context, id uint) (int64, error)
+}
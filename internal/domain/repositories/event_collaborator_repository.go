@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+)
+
+type EventCollaboratorRepository interface {
+	// Upsert creates the grant or updates the role of an existing one.
+	Upsert(ctx context.Context, collaborator *entities.EventCollaborator) error
+	Get(ctx context.Context, eventID, userID uint) (*entities.EventCollaborator, error)
+	GetByEventID(ctx context.Context, eventID uint) ([]*entities.EventCollaborator, error)
+	Delete(ctx context.Context, eventID, userID uint) error
+}
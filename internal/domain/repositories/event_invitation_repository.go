@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+	"time"
+)
+
+type EventInviteLinkRepository interface {
+	Create(ctx context.Context, link *entities.EventInviteLink) error
+	GetByID(ctx context.Context, id uint) (*entities.EventInviteLink, error)
+	GetByEventID(ctx context.Context, eventID uint) ([]*entities.EventInviteLink, error)
+	Revoke(ctx context.Context, eventID, id uint, revokedAt time.Time) error
+}
+
+type EventInvitationRepository interface {
+	// Upsert creates the invitation or resets an existing one to pending.
+	Upsert(ctx context.Context, invitation *entities.EventInvitation) error
+	GetByID(ctx context.Context, id uint) (*entities.EventInvitation, error)
+	Get(ctx context.Context, eventID uint, email string) (*entities.EventInvitation, error)
+	GetByEventID(ctx context.Context, eventID uint) ([]*entities.EventInvitation, error)
+	GetByEmail(ctx context.Context, email string) ([]*entities.EventInvitation, error)
+	UpdateStatus(ctx context.Context, id uint, status string, respondedAt time.Time) error
+}
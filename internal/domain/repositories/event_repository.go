@@ -3,13 +3,39 @@ package repositories
 import (
 	"EventsAPI/internal/domain/entities"
 	"context"
+	"time"
 )
 
 type EventRepository interface {
 	Create(ctx context.Context, event *entities.Event) error
 	GetByID(ctx context.Context, id uint) (*entities.Event, error)
-	GetByUserID(ctx context.Context, userID uint, limit, offset int) ([]*entities.Event, error)
+	// GetManagedByUserID returns the user's personal events, the events of
+	// every organization the user is a member of and the events the user
+	// collaborates on.
+	GetManagedByUserID(ctx context.Context, userID uint, limit, offset int) ([]*entities.Event, error)
+	GetByOrganizationID(ctx context.Context, organizationID uint, limit, offset int) ([]*entities.Event, error)
+	// GetOverlappingAtVenue returns the other events at the same place that
+	// overlap event. An event in a room clashes with events in that room and
+	// with events booking the whole venue; an event booking the whole venue
+	// clashes with every event there. Events without a venue never clash, as
+	// a free-text location doesn't identify a place.
+	GetOverlappingAtVenue(ctx context.Context, event *entities.Event) ([]*entities.Event, error)
 	Update(ctx context.Context, event *entities.Event) error
 	Delete(ctx context.Context, id uint) error
-	List(ctx context.Context, limit, offset int) ([]*entities.Event, error)
+	// List returns the public catalog; unlisted and private events are left out.
+	List(ctx context.Context, filter entities.EventFilter, limit, offset int) ([]*entities.Event, error)
+	// ListNear returns the public events within radiusKm of the point that
+	// haven't ended by from, nearest first.
+	ListNear(ctx context.Context, lat, lng, radiusKm float64, from time.Time, filter entities.EventFilter, limit, offset int) ([]*entities.Event, error)
+	// Search returns the public events matching a websearch_to_tsquery query
+	// and, when set, a to_tsquery prefix query, both parsed in each event's
+	// language, ranked by relevance. language, when set, only keeps events in
+	// that language.
+	Search(ctx context.Context, query, prefixQuery, language string, filter entities.EventFilter, limit, offset int) ([]entities.EventSearchResult, error)
+	// CountByCategory counts the public events matching filter that haven't
+	// ended by from, by their own category.
+	CountByCategory(ctx context.Context, filter entities.EventFilter, from time.Time) (map[uint]int, error)
+	// CountByTag counts the public events matching filter that haven't ended
+	// by from, by tag, most used first.
+	CountByTag(ctx context.Context, filter entities.EventFilter, from time.Time, limit int) ([]entities.TagFacet, error)
 }
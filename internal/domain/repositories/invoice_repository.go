@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+)
+
+type InvoiceRepository interface {
+	// GetByOrderID returns the invoice of the order and its credit notes in
+	// the order they were issued.
+	GetByOrderID(ctx context.Context, orderID uint) ([]*entities.Invoice, error)
+	// Issue gives the invoice the next sequence of its series and kind, with
+	// the number built by number, and stores it. Numbers are never skipped:
+	// an invoice that isn't stored gives its sequence back. It returns false
+	// without storing anything when the order already has its invoice, or
+	// the refund its credit note.
+	Issue(ctx context.Context, invoice *entities.Invoice, number func(sequence int) string) (bool, error)
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+)
+
+type OIDCLoginStateRepository interface {
+	Create(ctx context.Context, state *entities.OIDCLoginState) error
+	// Consume returns the login state and deletes it, so a state can only be
+	// used once.
+	Consume(ctx context.Context, state string) (*entities.OIDCLoginState, error)
+}
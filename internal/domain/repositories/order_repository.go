@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+	"time"
+)
+
+type OrderRepository interface {
+	Create(ctx context.Context, order *entities.Order) error
+	// GetByID returns the order with its Event, User, TicketType and Refunds.
+	GetByID(ctx context.Context, id uint) (*entities.Order, error)
+	// GetByPaymentID returns the order paid by the provider's payment, with
+	// its Event, User and TicketType.
+	GetByPaymentID(ctx context.Context, provider, paymentID string) (*entities.Order, error)
+	// GetPaidByAttendeeID returns the registration's latest paid order, with
+	// its Event, User and TicketType.
+	GetPaidByAttendeeID(ctx context.Context, attendeeID uint) (*entities.Order, error)
+	// GetPaidByEventID returns the event's paid orders, with their Event,
+	// User and TicketType.
+	GetPaidByEventID(ctx context.Context, eventID uint) ([]*entities.Order, error)
+	// GetByEventID returns the event's orders, newest first, optionally only
+	// the ones with the given status.
+	GetByEventID(ctx context.Context, eventID uint, status string, limit, offset int) ([]*entities.Order, error)
+	// GetByUserID returns the user's orders, newest first.
+	GetByUserID(ctx context.Context, userID uint, limit, offset int) ([]*entities.Order, error)
+	Update(ctx context.Context, order *entities.Order) error
+	// SetPayment records the provider's payment of an order that has none
+	// yet, reporting false when it already had one.
+	SetPayment(ctx context.Context, id uint, paymentID, checkoutURL string) (bool, error)
+	// UpdateStatus moves the order from one status to another, reporting
+	// false when it no longer had the from status.
+	UpdateStatus(ctx context.Context, id uint, from, to string) (bool, error)
+	// ConfirmPayment marks a pending order as paid and confirms its
+	// registration, reporting false when the order wasn't pending.
+	ConfirmPayment(ctx context.Context, id uint, paidAt time.Time) (bool, error)
+	// MarkPaid marks an order with the from status as paid without touching
+	// its registration, reporting false when it no longer had that status.
+	MarkPaid(ctx context.Context, id uint, from string, paidAt time.Time) (bool, error)
+	// Release moves a pending order to status and cancels its registration
+	// if it is still awaiting payment, reporting false when the order wasn't
+	// pending.
+	Release(ctx context.Context, id uint, status string) (bool, error)
+	// ReleaseByEventID releases the event's pending orders like Release and
+	// returns how many.
+	ReleaseByEventID(ctx context.Context, eventID uint, status string) (int, error)
+	// ExpirePending releases the pending orders that expired by now and
+	// returns how many.
+	ExpirePending(ctx context.Context, now time.Time) (int, error)
+	// RevenueByTicketType sums the event's paid orders and their refunds per
+	// ticket type.
+	RevenueByTicketType(ctx context.Context, eventID uint) ([]entities.TicketTypeRevenue, error)
+}
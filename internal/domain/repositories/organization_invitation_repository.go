@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+	"time"
+)
+
+type OrganizationInvitationRepository interface {
+	Create(ctx context.Context, invitation *entities.OrganizationInvitation) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entities.OrganizationInvitation, error)
+	GetPendingByOrganizationID(ctx context.Context, organizationID uint) ([]*entities.OrganizationInvitation, error)
+	MarkAccepted(ctx context.Context, id uint, acceptedAt time.Time) error
+}
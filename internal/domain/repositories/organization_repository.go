@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+)
+
+type OrganizationRepository interface {
+	// Create stores the organization together with its owner membership.
+	Create(ctx context.Context, organization *entities.Organization, ownerID uint) error
+	GetByID(ctx context.Context, id uint) (*entities.Organization, error)
+	Update(ctx context.Context, organization *entities.Organization) error
+	GetMember(ctx context.Context, organizationID, userID uint) (*entities.OrganizationMember, error)
+	GetMembers(ctx context.Context, organizationID uint) ([]*entities.OrganizationMember, error)
+	// GetMembershipsByUserID returns the user's memberships with their Organization.
+	GetMembershipsByUserID(ctx context.Context, userID uint) ([]*entities.OrganizationMember, error)
+	AddMember(ctx context.Context, member *entities.OrganizationMember) error
+	UpdateMemberRole(ctx context.Context, organizationID, userID uint, role string) error
+	RemoveMember(ctx context.Context, organizationID, userID uint) error
+	// TransferOwnership makes newOwnerID the owner and demotes the current
+	// owner to admin in a single transaction.
+	TransferOwnership(ctx context.Context, organizationID, currentOwnerID, newOwnerID uint) error
+}
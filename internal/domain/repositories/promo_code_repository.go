@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+)
+
+type PromoCodeRepository interface {
+	Create(ctx context.Context, promoCode *entities.PromoCode) error
+	GetByID(ctx context.Context, id uint) (*entities.PromoCode, error)
+	// GetByEventID returns the codes of the event, not its organization's.
+	GetByEventID(ctx context.Context, eventID uint) ([]*entities.PromoCode, error)
+	GetByOrganizationID(ctx context.Context, organizationID uint) ([]*entities.PromoCode, error)
+	// FindForEvent returns the code usable for the event, preferring the
+	// event's own codes over its organization's. Codes are matched
+	// case-insensitively.
+	FindForEvent(ctx context.Context, code string, eventID uint, organizationID *uint) (*entities.PromoCode, error)
+	// ExistsInScope reports whether another code with the same text exists
+	// for the same event or organization.
+	ExistsInScope(ctx context.Context, promoCode *entities.PromoCode) (bool, error)
+	Update(ctx context.Context, promoCode *entities.PromoCode) error
+	Delete(ctx context.Context, id uint) error
+	// CountRedemptions counts the redemptions of the codes whose order is
+	// pending or paid.
+	CountRedemptions(ctx context.Context, promoCodeIDs []uint) (map[uint]int, error)
+	// Redeem records the redemption while holding a lock on its promo code,
+	// so concurrent orders can't exceed its limits. check gets the code's
+	// redemptions of pending or paid orders, in total and by the user, and
+	// aborts the redemption when it returns an error.
+	Redeem(ctx context.Context, redemption *entities.PromoRedemption, check func(redemptions, userRedemptions int) error) error
+	// GetRedemptions returns the code's redemptions, newest first, with their
+	// Order.
+	GetRedemptions(ctx context.Context, promoCodeID uint) ([]*entities.PromoRedemption, error)
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+)
+
+type RefundRepository interface {
+	// CreateWithinOrder creates the refund while holding a lock on its order,
+	// so concurrent refunds can't give back more than was paid. check gets
+	// the order and the amount of its pending or succeeded refunds, may set
+	// the refund's amount, and aborts the creation when it returns an error.
+	// The order's refund summary is updated with it.
+	CreateWithinOrder(ctx context.Context, refund *entities.Refund, check func(order *entities.Order, refunded int64) error) error
+	// Update saves the refund and its order's refund summary.
+	Update(ctx context.Context, refund *entities.Refund) error
+	// Complete moves a pending refund to status and updates its order's
+	// refund summary, reporting false when the refund wasn't pending.
+	Complete(ctx context.Context, id uint, status, failureMessage string) (bool, error)
+	GetByProviderRefundID(ctx context.Context, provider, refundID string) (*entities.Refund, error)
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+)
+
+type RegistrationQuestionRepository interface {
+	Create(ctx context.Context, question *entities.RegistrationQuestion) error
+	GetByID(ctx context.Context, id uint) (*entities.RegistrationQuestion, error)
+	// GetByEventID returns the event's registration form in display order.
+	GetByEventID(ctx context.Context, eventID uint) ([]*entities.RegistrationQuestion, error)
+	Update(ctx context.Context, question *entities.RegistrationQuestion) error
+	// Delete removes the question together with its answers.
+	Delete(ctx context.Context, id uint) error
+	// ReplaceAnswers swaps the answers of a registration for the given ones.
+	ReplaceAnswers(ctx context.Context, attendeeID uint, answers []entities.RegistrationAnswer) error
+	// GetAnswersByEventID returns the answers of the event's pending and
+	// confirmed registrations.
+	GetAnswersByEventID(ctx context.Context, eventID uint) ([]*entities.RegistrationAnswer, error)
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+)
+
+type TicketTypeRepository interface {
+	Create(ctx context.Context, ticketType *entities.TicketType) error
+	GetByID(ctx context.Context, id uint) (*entities.TicketType, error)
+	// GetByEventID returns the event's ticket types in display order.
+	GetByEventID(ctx context.Context, eventID uint) ([]*entities.TicketType, error)
+	Update(ctx context.Context, ticketType *entities.TicketType) error
+	Delete(ctx context.Context, id uint) error
+	// CountRegistrations counts the pending and confirmed registrations with
+	// the ticket type.
+	CountRegistrations(ctx context.Context, id uint) (int64, error)
+}
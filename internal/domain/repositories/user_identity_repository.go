@@ -0,0 +1,11 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+)
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *entities.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*entities.UserIdentity, error)
+}
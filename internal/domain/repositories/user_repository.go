@@ -12,4 +12,7 @@ type UserRepository interface {
 	Update(ctx context.Context, user *entities.User) error
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, limit, offset int) ([]*entities.User, error)
+	// GrantAdmin makes the users with the given emails site administrators and
+	// returns how many weren't already.
+	GrantAdmin(ctx context.Context, emails []string) (int64, error)
 }
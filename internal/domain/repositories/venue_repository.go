@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"context"
+)
+
+type VenueRepository interface {
+	Create(ctx context.Context, venue *entities.Venue) error
+	// GetByID returns the venue with its Rooms.
+	GetByID(ctx context.Context, id uint) (*entities.Venue, error)
+	// List returns the venues with their Rooms, ordered by name.
+	List(ctx context.Context, limit, offset int) ([]*entities.Venue, error)
+	// Update saves the venue and copies its coordinates to its events.
+	Update(ctx context.Context, venue *entities.Venue) error
+	// Delete removes the venue together with its rooms.
+	Delete(ctx context.Context, id uint) error
+	CreateRoom(ctx context.Context, room *entities.VenueRoom) error
+	GetRoom(ctx context.Context, id uint) (*entities.VenueRoom, error)
+	UpdateRoom(ctx context.Context, room *entities.VenueRoom) error
+	DeleteRoom(ctx context.Context, id uint) error
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrLoginRejected is returned by Exchange when the provider refuses the
+	// authorization code or the ID token fails verification.
+	ErrLoginRejected = errors.New("SSO login rejected")
+	// ErrIdentityProviderUnavailable is returned when the provider can't be
+	// reached or doesn't answer as expected.
+	ErrIdentityProviderUnavailable = errors.New("identity provider unavailable")
+)
+
+// ExternalIdentity holds the verified claims of an ID token.
+type ExternalIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+	Name          string
+}
+
+// IdentityProvider is an external OpenID Connect provider used for SSO logins.
+type IdentityProvider interface {
+	Name() string
+	// AuthCodeURL returns the URL the user is redirected to in order to log
+	// in, using PKCE (S256) with the given code challenge.
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems the authorization code and verifies the returned ID
+	// token, including its nonce.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error)
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"io"
+
+	"EventsAPI/internal/domain/entities"
+)
+
+// InvoiceRenderer renders invoices and credit notes as a printable document,
+// one page per invoice.
+type InvoiceRenderer interface {
+	// ContentType is the MIME type of the rendered documents.
+	ContentType() string
+	Render(w io.Writer, invoices []*entities.Invoice) error
+}
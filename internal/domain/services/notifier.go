@@ -0,0 +1,15 @@
+package services
+
+import "context"
+
+// Notification is a message sent to a single recipient.
+type Notification struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers notifications to users (e.g. by email).
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
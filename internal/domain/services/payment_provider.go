@@ -0,0 +1,63 @@
+package services
+
+import "context"
+
+// Payment and refund statuses reported by a PaymentProvider.
+const (
+	PaymentPending   = "pending"
+	PaymentSucceeded = "succeeded"
+	PaymentFailed    = "failed"
+)
+
+// PaymentRequest asks a provider to collect an amount in the currency's minor
+// units. Reference identifies the order on the provider's side.
+type PaymentRequest struct {
+	Reference   string
+	Amount      int64
+	Currency    string
+	Description string
+}
+
+// Payment is a payment created by a provider, paid by the buyer at
+// CheckoutURL.
+type Payment struct {
+	ID          string
+	CheckoutURL string
+}
+
+// RefundRequest asks a provider to give back part or all of a payment.
+type RefundRequest struct {
+	PaymentID string
+	Reference string
+	Amount    int64
+	Currency  string
+}
+
+// RefundResult is a refund accepted by a provider. A pending refund is
+// completed later through the webhook.
+type RefundResult struct {
+	ID     string
+	Status string
+}
+
+// PaymentEvent is a verified webhook notification about a payment, or about
+// one of its refunds when RefundID is set. Reference is the payment's
+// PaymentRequest.Reference.
+type PaymentEvent struct {
+	PaymentID string
+	Reference string
+	RefundID  string
+	Status    string
+	Amount    int64
+	Currency  string
+}
+
+// PaymentProvider collects payments for orders (e.g. a card processor).
+type PaymentProvider interface {
+	Name() string
+	CreatePayment(ctx context.Context, req PaymentRequest) (*Payment, error)
+	Refund(ctx context.Context, req RefundRequest) (*RefundResult, error)
+	// ParseWebhook verifies the signature of a webhook request body and
+	// returns the payment event it carries.
+	ParseWebhook(payload []byte, signature string) (*PaymentEvent, error)
+}
@@ -0,0 +1,25 @@
+package services
+
+import "io"
+
+// TableFormat writes tables, like attendee lists, in a file format. Rows are
+// written one at a time so large tables are streamed instead of being held in
+// memory.
+type TableFormat interface {
+	// Name is how the format is chosen in the API, like "csv".
+	Name() string
+	// ContentType is the MIME type of the written files.
+	ContentType() string
+	// Extension is the file name extension, without the dot.
+	Extension() string
+	// NewWriter starts a table with the given column names on w.
+	NewWriter(w io.Writer, columns []string) (TableWriter, error)
+}
+
+// TableWriter writes the rows of a table. Values are strings, numbers,
+// booleans, string slices, times or nil for an empty cell.
+type TableWriter interface {
+	WriteRow(values []any) error
+	// Close finishes the table without closing the underlying writer.
+	Close() error
+}
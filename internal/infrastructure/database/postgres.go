@@ -29,6 +29,28 @@ func NewPostgresConnection(config *config.Config) (*gorm.DB, error) {
 		&entities.User{},
 		&entities.Event{},
 		&entities.Attendee{},
+		&entities.UserIdentity{},
+		&entities.OIDCLoginState{},
+		&entities.APIKey{},
+		&entities.Organization{},
+		&entities.OrganizationMember{},
+		&entities.OrganizationInvitation{},
+		&entities.EventCollaborator{},
+		&entities.EventInviteLink{},
+		&entities.EventInvitation{},
+		&entities.RegistrationQuestion{},
+		&entities.RegistrationAnswer{},
+		&entities.Venue{},
+		&entities.VenueRoom{},
+		&entities.Category{},
+		&entities.TicketType{},
+		&entities.Order{},
+		&entities.Refund{},
+		&entities.PromoCode{},
+		&entities.PromoRedemption{},
+		&entities.InvoiceSequence{},
+		&entities.Invoice{},
+		&entities.AttendeeImport{},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
@@ -0,0 +1,75 @@
+package exports
+
+import (
+	"encoding/csv"
+	"io"
+
+	"EventsAPI/internal/domain/services"
+)
+
+// CSVFormat writes UTF-8 CSV files that spreadsheets open directly.
+type CSVFormat struct{}
+
+func NewCSVFormat() services.TableFormat {
+	return &CSVFormat{}
+}
+
+func (f *CSVFormat) Name() string {
+	return "csv"
+}
+
+func (f *CSVFormat) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (f *CSVFormat) Extension() string {
+	return "csv"
+}
+
+// NewWriter starts the file with a byte order mark, without which Excel reads
+// UTF-8 files as the system's legacy encoding.
+func (f *CSVFormat) NewWriter(w io.Writer, columns []string) (services.TableWriter, error) {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	writer := &csvWriter{csv: csv.NewWriter(w)}
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	if err := writer.WriteRow(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+type csvWriter struct {
+	csv    *csv.Writer
+	record []string
+}
+
+func (w *csvWriter) WriteRow(values []any) error {
+	w.record = w.record[:0]
+	for _, value := range values {
+		text := cellText(value)
+		if _, ok := value.(string); ok {
+			text = escapeFormula(text)
+		}
+		w.record = append(w.record, text)
+	}
+	return w.csv.Write(w.record)
+}
+
+func (w *csvWriter) Close() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// escapeFormula keeps spreadsheets from evaluating text that starts like a
+// formula, since names and answers are typed in by attendees.
+func escapeFormula(text string) string {
+	if text != "" && (text[0] == '=' || text[0] == '+' || text[0] == '-' || text[0] == '@' || text[0] == '\t' || text[0] == '\r') {
+		return "'" + text
+	}
+	return text
+}
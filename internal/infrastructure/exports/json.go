@@ -0,0 +1,79 @@
+package exports
+
+import (
+	"encoding/json"
+	"io"
+
+	"EventsAPI/internal/domain/services"
+)
+
+// JSONFormat writes a JSON array with an object per row, keyed by column.
+type JSONFormat struct{}
+
+func NewJSONFormat() services.TableFormat {
+	return &JSONFormat{}
+}
+
+func (f *JSONFormat) Name() string {
+	return "json"
+}
+
+func (f *JSONFormat) ContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+func (f *JSONFormat) Extension() string {
+	return "json"
+}
+
+func (f *JSONFormat) NewWriter(w io.Writer, columns []string) (services.TableWriter, error) {
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(column)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, err
+	}
+	return &jsonWriter{w: w, keys: keys}, nil
+}
+
+// jsonWriter writes the objects by hand so their keys keep the column order.
+type jsonWriter struct {
+	w    io.Writer
+	keys [][]byte
+	rows int
+	buf  []byte
+}
+
+func (w *jsonWriter) WriteRow(values []any) error {
+	w.buf = w.buf[:0]
+	if w.rows > 0 {
+		w.buf = append(w.buf, ',')
+	}
+	w.buf = append(w.buf, "\n{"...)
+	for i, value := range values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			w.buf = append(w.buf, ',')
+		}
+		w.buf = append(w.buf, w.keys[i]...)
+		w.buf = append(w.buf, ':')
+		w.buf = append(w.buf, encoded...)
+	}
+	w.buf = append(w.buf, '}')
+	w.rows++
+	_, err := w.w.Write(w.buf)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	_, err := io.WriteString(w.w, "\n]\n")
+	return err
+}
@@ -0,0 +1,40 @@
+package exports
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayout is how times are written as text.
+const timeLayout = time.RFC3339
+
+// cellText is the text of a value in formats that only hold text.
+func cellText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ", ")
+	case time.Time:
+		return v.Format(timeLayout)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// isNumber reports whether the value is written as a number.
+func isNumber(value any) bool {
+	switch value.(type) {
+	case int, int64, uint, float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package invoices
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A4 page size in points.
+const (
+	pageWidth  = 595
+	pageHeight = 842
+)
+
+// Fonts of a page: the standard Helvetica faces every PDF reader has, so no
+// font is embedded.
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+// helveticaWidths are the advance widths of the printable ASCII characters in
+// Helvetica, in thousandths of the font size. Helvetica-Bold is close enough
+// for the digits and capitals it is measured with here.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0 to 9
+	278, 278, 584, 584, 584, 556, 1015, // : to @
+	667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // A to M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N to Z
+	278, 278, 278, 469, 556, 333, // [ to `
+	556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // a to m
+	556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // n to z
+	334, 260, 334, 584, // { to ~
+}
+
+// pdfDocument is a minimal PDF 1.4 writer for pages of text and lines.
+type pdfDocument struct {
+	pages []*pdfPage
+}
+
+// pdfPage holds the content stream of a page. Coordinates are in points from
+// the bottom left corner.
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+func (d *pdfDocument) newPage() *pdfPage {
+	page := &pdfPage{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// text writes s with its baseline starting at x, y.
+func (p *pdfPage) text(x, y float64, font string, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, number(size), number(x), number(y), escape(encode(s)))
+}
+
+// textRight writes s with its baseline ending at right, y.
+func (p *pdfPage) textRight(right, y float64, font string, size float64, s string) {
+	p.text(right-textWidth(s, size), y, font, size, s)
+}
+
+// line draws a thin gray line.
+func (p *pdfPage) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "0.6 G 0.5 w %s %s m %s %s l S 0 G\n", number(x1), number(y1), number(x2), number(y2))
+}
+
+// writeTo writes the document: the catalog, the page tree, the two fonts and
+// then each page with its content stream, followed by the cross-reference
+// table locating every object.
+func (d *pdfDocument) writeTo(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := ""
+	for i := range d.pages {
+		kids += fmt.Sprintf("%d 0 R ", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, fontRegular, fontBold, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := out.WriteTo(w)
+	return err
+}
+
+// textWidth measures s in Helvetica at size points.
+func textWidth(s string, size float64) float64 {
+	width := 0
+	for _, c := range encode(s) {
+		if c >= 32 && c <= 126 {
+			width += helveticaWidths[c-32]
+		} else {
+			width += 556
+		}
+	}
+	return float64(width) * size / 1000
+}
+
+// truncate shortens s with an ellipsis to fit in width points.
+func truncate(s string, size, width float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// encode encodes text for the fonts' WinAnsiEncoding; characters it lacks
+// print as '?'. Encoders keep state, so each call gets its own.
+func encode(s string) []byte {
+	encoded, err := encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder()).Bytes([]byte(s))
+	if err != nil {
+		return []byte(s)
+	}
+	return encoded
+}
+
+// escape makes text safe inside a PDF literal string.
+func escape(text []byte) []byte {
+	escaped := make([]byte, 0, len(text))
+	for _, c := range text {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			escaped = append(escaped, '\\', c)
+		case c < 32:
+			escaped = append(escaped, ' ')
+		default:
+			escaped = append(escaped, c)
+		}
+	}
+	return escaped
+}
+
+func number(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
@@ -0,0 +1,164 @@
+package invoices
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/services"
+)
+
+// Page layout, in points.
+const (
+	marginLeft  = 50
+	marginRight = pageWidth - 50
+	marginTop   = pageHeight - 60
+	lineHeight  = 13
+	billToLeft  = 310
+	qtyRight    = 370
+	priceRight  = 460
+)
+
+// PDFRenderer renders each invoice or credit note as an A4 PDF page.
+type PDFRenderer struct{}
+
+func NewPDFRenderer() services.InvoiceRenderer {
+	return &PDFRenderer{}
+}
+
+func (r *PDFRenderer) ContentType() string {
+	return "application/pdf"
+}
+
+func (r *PDFRenderer) Render(w io.Writer, invoices []*entities.Invoice) error {
+	document := &pdfDocument{}
+	for _, invoice := range invoices {
+		renderInvoice(document.newPage(), invoice)
+	}
+	return document.writeTo(w)
+}
+
+// renderInvoice lays out the header with the number and date, the seller and
+// buyer side by side, the lines and the totals with the tax. Credit notes
+// show their amounts as negative.
+func renderInvoice(page *pdfPage, invoice *entities.Invoice) {
+	sign := int64(1)
+	title := "INVOICE"
+	if invoice.Kind == entities.InvoiceKindCreditNote {
+		sign = -1
+		title = "CREDIT NOTE"
+	}
+	money := func(amount int64) string {
+		return formatMoney(sign*amount, invoice.Currency)
+	}
+
+	y := float64(marginTop)
+	page.text(marginLeft, y, fontBold, 22, title)
+	page.textRight(marginRight, y, fontBold, 12, invoice.Number)
+	page.textRight(marginRight, y-16, fontRegular, 10, "Date: "+invoice.IssuedAt.Format("2006-01-02"))
+	if invoice.CorrectsNumber != "" {
+		page.textRight(marginRight, y-30, fontRegular, 10, "Corrects invoice "+invoice.CorrectsNumber)
+	}
+
+	y -= 70
+	page.text(marginLeft, y, fontBold, 10, "From")
+	page.text(billToLeft, y, fontBold, 10, "Bill to")
+	fromY := writeBlock(page, marginLeft, y-lineHeight, issuerLines(&invoice.Issuer))
+	billToY := writeBlock(page, billToLeft, y-lineHeight, billingLines(&invoice.Billing))
+	y = min(fromY, billToY) - 20
+
+	page.text(marginLeft, y, fontBold, 10, "Description")
+	page.textRight(qtyRight, y, fontBold, 10, "Qty")
+	page.textRight(priceRight, y, fontBold, 10, "Unit price")
+	page.textRight(marginRight, y, fontBold, 10, "Amount")
+	page.line(marginLeft, y-5, marginRight, y-5)
+	y -= 20
+	for _, line := range invoice.Lines {
+		page.text(marginLeft, y, fontRegular, 10, truncate(line.Description, 10, qtyRight-marginLeft-40))
+		page.textRight(qtyRight, y, fontRegular, 10, strconv.Itoa(line.Quantity))
+		page.textRight(priceRight, y, fontRegular, 10, money(line.UnitPrice))
+		page.textRight(marginRight, y, fontRegular, 10, money(line.Amount))
+		y -= lineHeight + 4
+	}
+
+	page.line(marginLeft, y+6, marginRight, y+6)
+	y -= 10
+	taxName := invoice.TaxName
+	if taxName == "" {
+		taxName = "Tax"
+	}
+	totals := [][2]string{
+		{"Net amount", money(invoice.Net)},
+		{fmt.Sprintf("%s %s%%", taxName, strconv.FormatFloat(invoice.TaxRate, 'f', -1, 64)), money(invoice.Tax)},
+	}
+	for _, total := range totals {
+		page.textRight(priceRight, y, fontRegular, 10, total[0])
+		page.textRight(marginRight, y, fontRegular, 10, total[1])
+		y -= lineHeight + 2
+	}
+	page.textRight(priceRight, y-2, fontBold, 12, "Total")
+	page.textRight(marginRight, y-2, fontBold, 12, money(invoice.Total))
+
+	page.text(marginLeft, 50, fontRegular, 8, fmt.Sprintf("Order #%d. Prices include tax.", invoice.OrderID))
+}
+
+// writeBlock writes lines one under the other from y and returns the y of
+// the line after the last.
+func writeBlock(page *pdfPage, x, y float64, lines []string) float64 {
+	for _, line := range lines {
+		page.text(x, y, fontRegular, 10, truncate(line, 10, billToLeft-marginLeft-20))
+		y -= lineHeight
+	}
+	return y
+}
+
+func issuerLines(issuer *entities.InvoiceIssuer) []string {
+	lines := []string{issuer.Name}
+	if issuer.TaxID != "" {
+		lines = append(lines, "Tax ID: "+issuer.TaxID)
+	}
+	return append(lines, addressLines(issuer.Address)...)
+}
+
+func billingLines(billing *entities.BillingDetails) []string {
+	lines := []string{billing.Name}
+	if billing.Company != "" {
+		lines = append(lines, billing.Company)
+	}
+	if billing.TaxID != "" {
+		lines = append(lines, "Tax ID: "+billing.TaxID)
+	}
+	lines = append(lines, addressLines(billing.Address)...)
+	if city := strings.TrimSpace(billing.PostalCode + " " + billing.City); city != "" {
+		lines = append(lines, city)
+	}
+	if billing.Country != "" {
+		lines = append(lines, strings.ToUpper(billing.Country))
+	}
+	if billing.Email != "" {
+		lines = append(lines, billing.Email)
+	}
+	return lines
+}
+
+func addressLines(address string) []string {
+	var lines []string
+	for _, line := range strings.Split(address, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// formatMoney formats an amount in minor units with two decimals.
+func formatMoney(amount int64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d %s", sign, amount/100, amount%100, currency)
+}
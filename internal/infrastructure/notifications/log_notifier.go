@@ -0,0 +1,21 @@
+package notifications
+
+import (
+	"context"
+	"log"
+
+	"EventsAPI/internal/domain/services"
+)
+
+// LogNotifier writes notifications to the application log. It is used until a
+// real delivery channel (SMTP, provider API) is configured.
+type LogNotifier struct{}
+
+func NewLogNotifier() services.Notifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification services.Notification) error {
+	log.Printf("📧 To: %s | Subject: %s\n%s", notification.To, notification.Subject, notification.Body)
+	return nil
+}
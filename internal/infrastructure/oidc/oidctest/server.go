@@ -0,0 +1,196 @@
+// Package oidctest provides a fake OpenID Connect provider for tests.
+package oidctest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"EventsAPI/internal/domain/services"
+	"EventsAPI/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Server is a fake OpenID Connect provider. It serves discovery, a JWKS and a
+// token endpoint that enforces PKCE, and signs ID tokens for the identity it
+// is given. The issuer is the server's URL.
+type Server struct {
+	*httptest.Server
+	ClientID string
+
+	key *utils.JWTKey
+
+	mu             sync.Mutex
+	identity       services.ExternalIdentity
+	audience       string
+	nonce          string
+	authorizations map[string]authorization
+	verifiers      []string
+}
+
+// authorization is a code handed out by Authorize, waiting to be redeemed.
+type authorization struct {
+	challenge string
+	nonce     string
+}
+
+// NewServer starts a provider for the client. Close it when done.
+func NewServer(clientID string) *Server {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	key, err := utils.NewJWTKey("oidctest", private)
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Server{ClientID: clientID, key: key, authorizations: make(map[string]authorization)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("POST /token", s.token)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetIdentity sets who the next ID tokens are for.
+func (s *Server) SetIdentity(identity services.ExternalIdentity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.identity = identity
+}
+
+// SetAudience makes the next ID tokens carry another audience than the
+// client's; an empty audience restores it.
+func (s *Server) SetAudience(audience string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audience = audience
+}
+
+// SetNonce makes the next ID tokens carry another nonce than the one of the
+// authorization request; an empty nonce restores it.
+func (s *Server) SetNonce(nonce string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nonce = nonce
+}
+
+// Verifiers returns the PKCE verifiers the token endpoint received.
+func (s *Server) Verifiers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.verifiers...)
+}
+
+// Authorize plays the user logging in at authURL, as returned by the client's
+// AuthCodeURL, and returns the code and state the provider redirects back
+// with.
+func (s *Server) Authorize(authURL string) (code, state string, err error) {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	query := parsed.Query()
+	if query.Get("client_id") != s.ClientID {
+		return "", "", errors.New("unknown client_id")
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		return "", "", errors.New("missing S256 code challenge")
+	}
+
+	code, err = utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authorizations[code] = authorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	return code, query.Get("state"), nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{s.key.Method.Alg()},
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	jwk, err := utils.NewJWK(s.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, utils.JWKSet{Keys: []utils.JWK{*jwk}})
+}
+
+// token redeems a code once, checking the PKCE verifier against the code's
+// challenge.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("client_id") != s.ClientID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	verifier := r.PostForm.Get("code_verifier")
+	s.verifiers = append(s.verifiers, verifier)
+	code := r.PostForm.Get("code")
+	authorization, ok := s.authorizations[code]
+	delete(s.authorizations, code)
+	challenge := sha256.Sum256([]byte(verifier))
+	if !ok || base64.RawURLEncoding.EncodeToString(challenge[:]) != authorization.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	audience, nonce := s.ClientID, authorization.nonce
+	if s.audience != "" {
+		audience = s.audience
+	}
+	if s.nonce != "" {
+		nonce = s.nonce
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(s.key.Method, jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            s.identity.Subject,
+		"aud":            audience,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          nonce,
+		"email":          s.identity.Email,
+		"email_verified": s.identity.EmailVerified,
+		"given_name":     s.identity.GivenName,
+		"family_name":    s.identity.FamilyName,
+		"name":           s.identity.Name,
+	})
+	token.Header["kid"] = s.key.ID
+	idToken, err := token.SignedString(s.key.PrivateKey)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": idToken})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
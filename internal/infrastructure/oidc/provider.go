@@ -0,0 +1,278 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"EventsAPI/internal/config"
+	"EventsAPI/internal/domain/services"
+	"EventsAPI/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// supportedAlgorithms are the ID token signing algorithms we accept. Symmetric
+// and "none" algorithms are never accepted.
+var supportedAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type discoveryDocument struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	SigningAlgorithms     []string `json:"id_token_signing_alg_values_supported"`
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type idTokenClaims struct {
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Name          string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// Provider implements services.IdentityProvider using OIDC discovery. The
+// discovery document and signing keys are fetched lazily and cached.
+type Provider struct {
+	config     config.OIDCProviderConfig
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      map[string]*utils.JWK
+}
+
+// NewProvider creates a provider. A nil httpClient uses a client with a 10s
+// timeout.
+func NewProvider(cfg config.OIDCProviderConfig, httpClient *http.Client) *Provider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{config: cfg, httpClient: httpClient}
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*services.ExternalIdentity, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: token request failed: %w", services.ErrIdentityProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("%w: invalid token response: %w", services.ErrIdentityProviderUnavailable, err)
+	}
+	// The token endpoint answers 400 when it refuses the code or the PKCE
+	// verifier; anything else is a provider or configuration problem.
+	if resp.StatusCode == http.StatusBadRequest {
+		return nil, fmt.Errorf("%w: token request failed: %s %s", services.ErrLoginRejected, token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: token request failed: %s %s", services.ErrIdentityProviderUnavailable, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", services.ErrIdentityProviderUnavailable)
+	}
+
+	return p.verifyIDToken(ctx, doc, token.IDToken, nonce)
+}
+
+func (p *Provider) verifyIDToken(ctx context.Context, doc *discoveryDocument, rawIDToken, nonce string) (*services.ExternalIdentity, error) {
+	algorithms := supportedAlgorithms
+	if len(doc.SigningAlgorithms) > 0 {
+		algorithms = slices.DeleteFunc(slices.Clone(doc.SigningAlgorithms), func(alg string) bool {
+			return !slices.Contains(supportedAlgorithms, alg)
+		})
+	}
+
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		jwk, err := p.signingKey(ctx, doc, kid)
+		if err != nil {
+			return nil, err
+		}
+		if jwk.Alg != "" && jwk.Alg != token.Method.Alg() {
+			return nil, fmt.Errorf("signing method %s does not match key %q", token.Method.Alg(), kid)
+		}
+		return jwk.PublicKey()
+	},
+		jwt.WithValidMethods(algorithms),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if errors.Is(err, services.ErrIdentityProviderUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ID token: %w", services.ErrLoginRejected, err)
+	}
+
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: invalid ID token: nonce mismatch", services.ErrLoginRejected)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: invalid ID token: missing subject", services.ErrLoginRejected)
+	}
+
+	return &services.ExternalIdentity{
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(claims.Email),
+		EmailVerified: claims.EmailVerified == true || claims.EmailVerified == "true",
+		GivenName:     claims.GivenName,
+		FamilyName:    claims.FamilyName,
+		Name:          claims.Name,
+	}, nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var doc discoveryDocument
+	wellKnown := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, &doc); err != nil {
+		return nil, fmt.Errorf("%w: OIDC discovery failed for %s: %w", services.ErrIdentityProviderUnavailable, p.config.Name, err)
+	}
+	if doc.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("%w: OIDC discovery for %s returned issuer %q", services.ErrIdentityProviderUnavailable, p.config.Name, doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: OIDC discovery for %s is missing endpoints", services.ErrIdentityProviderUnavailable, p.config.Name)
+	}
+
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+// signingKey looks the key up in the cached JWKS, refreshing it once when the
+// kid is unknown so provider key rotations are picked up.
+func (p *Provider) signingKey(ctx context.Context, doc *discoveryDocument, kid string) (*utils.JWK, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if jwk := p.lookupKey(kid); jwk != nil {
+		return jwk, nil
+	}
+
+	var set utils.JWKSet
+	if err := p.getJSON(ctx, doc.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("%w: error fetching JWKS for %s: %w", services.ErrIdentityProviderUnavailable, p.config.Name, err)
+	}
+	p.keys = make(map[string]*utils.JWK, len(set.Keys))
+	for i := range set.Keys {
+		if set.Keys[i].Use == "" || set.Keys[i].Use == "sig" {
+			p.keys[set.Keys[i].Kid] = &set.Keys[i]
+		}
+	}
+
+	if jwk := p.lookupKey(kid); jwk != nil {
+		return jwk, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (p *Provider) lookupKey(kid string) *utils.JWK {
+	if kid != "" {
+		return p.keys[kid]
+	}
+	// Without a kid the key set must be unambiguous.
+	if len(p.keys) == 1 {
+		for _, jwk := range p.keys {
+			return jwk
+		}
+	}
+	return nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, endpoint string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, endpoint)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,170 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"EventsAPI/internal/config"
+	"EventsAPI/internal/domain/services"
+	"EventsAPI/internal/infrastructure/oidc"
+	"EventsAPI/internal/infrastructure/oidc/oidctest"
+)
+
+const (
+	testVerifier = "a-verifier-that-is-long-enough-for-pkce"
+	testNonce    = "nonce-1"
+)
+
+func newTestProvider(t *testing.T) (*oidc.Provider, *oidctest.Server) {
+	t.Helper()
+	server := oidctest.NewServer("events-api")
+	t.Cleanup(server.Close)
+	server.SetIdentity(services.ExternalIdentity{
+		Subject:       "subject-1",
+		Email:         "Ada.Lovelace@Example.com",
+		EmailVerified: true,
+		GivenName:     "Ada",
+		FamilyName:    "Lovelace",
+	})
+	provider := oidc.NewProvider(config.OIDCProviderConfig{
+		Name:        "test",
+		Issuer:      server.URL,
+		ClientID:    server.ClientID,
+		Scopes:      []string{"openid", "email", "profile"},
+		RedirectURL: "http://localhost/auth/oidc/test/callback",
+	}, server.Client())
+	return provider, server
+}
+
+// authorize runs the authorization step with the PKCE challenge of
+// testVerifier and returns the code.
+func authorize(t *testing.T, provider *oidc.Provider, server *oidctest.Server) string {
+	t.Helper()
+	challenge := sha256.Sum256([]byte(testVerifier))
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", testNonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state, err := server.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if state != "state-1" {
+		t.Fatalf("state = %q, want %q", state, "state-1")
+	}
+	return code
+}
+
+func TestProviderAuthCodeURL(t *testing.T) {
+	provider, server := newTestProvider(t)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", testNonce, "challenge")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid URL %q: %v", authURL, err)
+	}
+	if got := parsed.Scheme + "://" + parsed.Host + parsed.Path; got != server.URL+"/authorize" {
+		t.Errorf("endpoint = %q, want %q", got, server.URL+"/authorize")
+	}
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             server.ClientID,
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 testNonce,
+		"code_challenge":        "challenge",
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := parsed.Query().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestProviderExchange(t *testing.T) {
+	provider, server := newTestProvider(t)
+	code := authorize(t, provider, server)
+
+	identity, err := provider.Exchange(context.Background(), code, testVerifier, testNonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := services.ExternalIdentity{
+		Subject:       "subject-1",
+		Email:         "ada.lovelace@example.com",
+		EmailVerified: true,
+		GivenName:     "Ada",
+		FamilyName:    "Lovelace",
+	}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+	if verifiers := server.Verifiers(); len(verifiers) != 1 || verifiers[0] != testVerifier {
+		t.Errorf("token endpoint received verifiers %q, want [%q]", verifiers, testVerifier)
+	}
+}
+
+func TestProviderExchangeRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(*oidctest.Server)
+		verifier string
+		wantErr  string
+	}{
+		{
+			name:     "wrong PKCE verifier",
+			setup:    func(*oidctest.Server) {},
+			verifier: "another-verifier-that-is-long-enough",
+			wantErr:  "invalid_grant",
+		},
+		{
+			name:     "nonce mismatch",
+			setup:    func(s *oidctest.Server) { s.SetNonce("another-nonce") },
+			verifier: testVerifier,
+			wantErr:  "nonce mismatch",
+		},
+		{
+			name:     "audience mismatch",
+			setup:    func(s *oidctest.Server) { s.SetAudience("another-client") },
+			verifier: testVerifier,
+			wantErr:  "invalid ID token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, server := newTestProvider(t)
+			tt.setup(server)
+			code := authorize(t, provider, server)
+
+			identity, err := provider.Exchange(context.Background(), code, tt.verifier, testNonce)
+			if err == nil {
+				t.Fatalf("Exchange returned %+v, want an error", identity)
+			}
+			if !errors.Is(err, services.ErrLoginRejected) {
+				t.Errorf("error = %q, want ErrLoginRejected", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProviderUnavailable(t *testing.T) {
+	provider, server := newTestProvider(t)
+	server.Close()
+
+	_, err := provider.AuthCodeURL(context.Background(), "state-1", testNonce, "challenge")
+	if !errors.Is(err, services.ErrIdentityProviderUnavailable) {
+		t.Errorf("error = %v, want ErrIdentityProviderUnavailable", err)
+	}
+}
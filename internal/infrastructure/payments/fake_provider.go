@@ -0,0 +1,102 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"EventsAPI/internal/domain/services"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// FakeProvider is a PaymentProvider for tests and local development. It never
+// charges anything: payments are completed by posting a webhook body signed
+// with the shared secret, as produced by Sign. Refunds succeed at once, and a
+// webhook with a refund_id can still report a refund's later outcome.
+type FakeProvider struct {
+	secret []byte
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{secret: []byte(webhookSecret)}
+}
+
+// fakeWebhook is the webhook body: {"payment_id", "reference", "refund_id",
+// "status", "amount", "currency"}, where reference and refund_id are optional.
+type fakeWebhook struct {
+	PaymentID string `json:"payment_id"`
+	Reference string `json:"reference"`
+	RefundID  string `json:"refund_id"`
+	Status    string `json:"status"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) CreatePayment(ctx context.Context, req services.PaymentRequest) (*services.Payment, error) {
+	paymentID, err := newFakeID("fake_")
+	if err != nil {
+		return nil, err
+	}
+	return &services.Payment{ID: paymentID, CheckoutURL: "fake://checkout/" + paymentID}, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, req services.RefundRequest) (*services.RefundResult, error) {
+	refundID, err := newFakeID("fake_re_")
+	if err != nil {
+		return nil, err
+	}
+	return &services.RefundResult{ID: refundID, Status: services.PaymentSucceeded}, nil
+}
+
+func (p *FakeProvider) ParseWebhook(payload []byte, signature string) (*services.PaymentEvent, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, p.sign(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var webhook fakeWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, fmt.Errorf("invalid webhook body: %w", err)
+	}
+	if webhook.Status != services.PaymentSucceeded && webhook.Status != services.PaymentFailed {
+		return nil, fmt.Errorf("unknown payment status %q", webhook.Status)
+	}
+	return &services.PaymentEvent{
+		PaymentID: webhook.PaymentID,
+		Reference: webhook.Reference,
+		RefundID:  webhook.RefundID,
+		Status:    webhook.Status,
+		Amount:    webhook.Amount,
+		Currency:  webhook.Currency,
+	}, nil
+}
+
+// Sign returns the hex HMAC-SHA256 signature of a webhook body, to be sent in
+// the X-Payment-Signature header.
+func (p *FakeProvider) Sign(payload []byte) string {
+	return hex.EncodeToString(p.sign(payload))
+}
+
+func (p *FakeProvider) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func newFakeID(prefix string) (string, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(id), nil
+}
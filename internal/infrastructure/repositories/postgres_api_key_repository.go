@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type postgresAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresAPIKeyRepository(db *gorm.DB) repositories.APIKeyRepository {
+	return &postgresAPIKeyRepository{db: db}
+}
+
+func (r *postgresAPIKeyRepository) Create(ctx context.Context, apiKey *entities.APIKey) error {
+	return r.db.WithContext(ctx).Create(apiKey).Error
+}
+
+func (r *postgresAPIKeyRepository) GetByID(ctx context.Context, id uint) (*entities.APIKey, error) {
+	var apiKey entities.APIKey
+	err := r.db.WithContext(ctx).First(&apiKey, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r *postgresAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error) {
+	var apiKey entities.APIKey
+	err := r.db.WithContext(ctx).Preload("User").Where("prefix = ?", prefix).First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r *postgresAPIKeyRepository) GetByUserID(ctx context.Context, userID uint) ([]*entities.APIKey, error) {
+	var apiKeys []*entities.APIKey
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&apiKeys).Error
+	return apiKeys, err
+}
+
+func (r *postgresAPIKeyRepository) Revoke(ctx context.Context, id uint, revokedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *postgresAPIKeyRepository) TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", usedAt).Error
+}
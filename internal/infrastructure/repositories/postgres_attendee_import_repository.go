@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type postgresAttendeeImportRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresAttendeeImportRepository(db *gorm.DB) repositories.AttendeeImportRepository {
+	return &postgresAttendeeImportRepository{db: db}
+}
+
+func (r *postgresAttendeeImportRepository) Create(ctx context.Context, attendeeImport *entities.AttendeeImport) error {
+	return r.db.WithContext(ctx).Create(attendeeImport).Error
+}
+
+func (r *postgresAttendeeImportRepository) GetByID(ctx context.Context, id uint) (*entities.AttendeeImport, error) {
+	var attendeeImport entities.AttendeeImport
+	err := r.db.WithContext(ctx).First(&attendeeImport, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attendeeImport, nil
+}
+
+func (r *postgresAttendeeImportRepository) Update(ctx context.Context, attendeeImport *entities.AttendeeImport) error {
+	return r.db.WithContext(ctx).Save(attendeeImport).Error
+}
+
+func (r *postgresAttendeeImportRepository) FailRunning(ctx context.Context, updatedBefore time.Time, message string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&entities.AttendeeImport{}).
+		Where("status = ? AND COALESCE(updated_at, created_at) < ?", entities.AttendeeImportRunning, updatedBefore).
+		Updates(map[string]interface{}{
+			"status":       entities.AttendeeImportFailed,
+			"error":        message,
+			"completed_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
@@ -4,8 +4,10 @@ import (
 	"EventsAPI/internal/domain/entities"
 	"EventsAPI/internal/domain/repositories"
 	"context"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type postgresAttendeeRepository struct {
@@ -29,9 +31,65 @@ func (r *postgresAttendeeRepository) GetByID(ctx context.Context, id uint) (*ent
 	return &attendee, nil
 }
 
-func (r *postgresAttendeeRepository) GetByEventID(ctx context.Context, eventID uint, limit, offset int) ([]*entities.Attendee, error) {
+func (r *postgresAttendeeRepository) Get(ctx context.Context, eventID, userID uint) (*entities.Attendee, error) {
+	var attendee entities.Attendee
+	err := r.db.WithContext(ctx).Where("event_id = ? AND user_id = ?", eventID, userID).Preload("User").Order("id DESC").First(&attendee).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attendee, nil
+}
+
+func (r *postgresAttendeeRepository) GetByEventID(ctx context.Context, eventID uint, status string, limit, offset int) ([]*entities.Attendee, error) {
+	query := r.db.WithContext(ctx).Where("event_id = ?", eventID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
 	var attendees []*entities.Attendee
-	err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Limit(limit).Offset(offset).Find(&attendees).Error
+	err := query.Preload("User").Preload("Answers.Question").Limit(limit).Offset(offset).Find(&attendees).Error
+	if err != nil {
+		return nil, err
+	}
+	return attendees, nil
+}
+
+// attendeeBatchSize is how many attendees EachByEventID loads at a time.
+const attendeeBatchSize = 500
+
+func (r *postgresAttendeeRepository) EachByEventID(ctx context.Context, eventID uint, status string, fn func(attendees []*entities.Attendee) error) error {
+	query := r.db.WithContext(ctx).Where("event_id = ?", eventID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var attendees []*entities.Attendee
+	return query.Preload("User").Preload("Answers").FindInBatches(&attendees, attendeeBatchSize, func(*gorm.DB, int) error {
+		return fn(attendees)
+	}).Error
+}
+
+func (r *postgresAttendeeRepository) GetVisibleByEventID(ctx context.Context, eventID, viewerID uint, limit, offset int) ([]*entities.Attendee, error) {
+	var attendees []*entities.Attendee
+	err := r.db.WithContext(ctx).
+		Joins("User").
+		Where("attendees.event_id = ? AND attendees.status = ? AND attendees.rsvp = ?", eventID, entities.AttendeeStatusConfirmed, entities.RSVPGoing).
+		Where(`"User".hide_from_attendee_lists = ? OR attendees.user_id = ?`, false, viewerID).
+		Limit(limit).
+		Offset(offset).
+		Find(&attendees).Error
+	if err != nil {
+		return nil, err
+	}
+	return attendees, nil
+}
+
+func (r *postgresAttendeeRepository) GetConfirmedByRSVP(ctx context.Context, eventID uint, rsvp string) ([]*entities.Attendee, error) {
+	var attendees []*entities.Attendee
+	err := r.db.WithContext(ctx).
+		Where("event_id = ? AND status = ? AND rsvp = ?", eventID, entities.AttendeeStatusConfirmed, rsvp).
+		Preload("User").
+		Find(&attendees).Error
 	if err != nil {
 		return nil, err
 	}
@@ -40,31 +98,179 @@ func (r *postgresAttendeeRepository) GetByEventID(ctx context.Context, eventID u
 
 func (r *postgresAttendeeRepository) GetByUserID(ctx context.Context, userID uint, limit, offset int) ([]*entities.Attendee, error) {
 	var attendees []*entities.Attendee
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Limit(limit).Offset(offset).Find(&attendees).Error
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Preload("Event").Limit(limit).Offset(offset).Find(&attendees).Error
 	if err != nil {
 		return nil, err
 	}
 	return attendees, nil
 }
 
-func (r *postgresAttendeeRepository) Delete(ctx context.Context, eventID, userID uint) error {
-	return r.db.WithContext(ctx).Where("event_id = ? AND user_id = ?", eventID, userID).Delete(&entities.Attendee{}).Error
+func (r *postgresAttendeeRepository) GetOverlapping(ctx context.Context, userID uint, event *entities.Event) ([]*entities.Attendee, error) {
+	query, args := overlapCondition(`"Event"`, event)
+
+	var attendees []*entities.Attendee
+	err := r.db.WithContext(ctx).
+		Joins("Event").
+		Where("attendees.user_id = ? AND attendees.event_id <> ?", userID, event.ID).
+		Where("attendees.status IN ? AND attendees.rsvp <> ?", []string{entities.AttendeeStatusPending, entities.AttendeeStatusConfirmed}, entities.RSVPNotGoing).
+		Where(query, args...).
+		Find(&attendees).Error
+	if err != nil {
+		return nil, err
+	}
+	return attendees, nil
+}
+
+func (r *postgresAttendeeRepository) GetUpcomingByUserID(ctx context.Context, userID uint, from time.Time) ([]*entities.Attendee, error) {
+	var attendees []*entities.Attendee
+	err := r.db.WithContext(ctx).
+		Joins("Event").
+		Where("attendees.user_id = ?", userID).
+		Where("attendees.status IN ? AND attendees.rsvp <> ?", []string{entities.AttendeeStatusPending, entities.AttendeeStatusConfirmed}, entities.RSVPNotGoing).
+		Where(`COALESCE("Event".ends_at, "Event".date_time) >= ?`, from).
+		Order(`"Event".date_time`).
+		Find(&attendees).Error
+	if err != nil {
+		return nil, err
+	}
+	return attendees, nil
+}
+
+func (r *postgresAttendeeRepository) Update(ctx context.Context, attendee *entities.Attendee) error {
+	return r.db.WithContext(ctx).Save(attendee).Error
 }
 
 func (r *postgresAttendeeRepository) IsUserRegistered(ctx context.Context, eventID, userID uint) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&entities.Attendee{}).Where("event_id = ? AND user_id = ?", eventID, userID).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&entities.Attendee{}).
+		Where("event_id = ? AND user_id = ?", eventID, userID).
+		Where("status IN ?", []string{entities.AttendeeStatusPending, entities.AttendeeStatusConfirmed}).
+		Count(&count).Error
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-func (r *postgresAttendeeRepository) CountByEventID(ctx context.Context, eventID uint) (int64, error) {
-	var count int64
-	err := r.db.WithContext(ctx).Model(&entities.Attendee{}).Where("event_id = ?", eventID).Count(&count).Error
+func (r *postgresAttendeeRepository) CountSeatsByEventID(ctx context.Context, eventID uint) (int, error) {
+	var seats int
+	err := r.db.WithContext(ctx).Model(&entities.Attendee{}).
+		Select("COALESCE(SUM(1 + guest_count), 0)").
+		Where("event_id = ? AND status IN ? AND rsvp = ?", eventID, entities.SeatHoldingStatuses, entities.RSVPGoing).
+		Scan(&seats).Error
 	if err != nil {
 		return 0, err
 	}
-	return count, nil
+	return seats, nil
+}
+
+func (r *postgresAttendeeRepository) CountSeatsByTicketType(ctx context.Context, eventID uint) (map[uint]int, error) {
+	var rows []struct {
+		TicketTypeID uint
+		Seats        int
+	}
+	err := r.db.WithContext(ctx).Model(&entities.Attendee{}).
+		Select("ticket_type_id, SUM(1 + guest_count) AS seats").
+		Where("event_id = ? AND status IN ? AND rsvp = ?", eventID, entities.SeatHoldingStatuses, entities.RSVPGoing).
+		Where("ticket_type_id IS NOT NULL").
+		Group("ticket_type_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	seats := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		seats[row.TicketTypeID] = row.Seats
+	}
+	return seats, nil
+}
+
+func (r *postgresAttendeeRepository) SaveWithinCapacity(ctx context.Context, attendee *entities.Attendee, check func(eventSeats, ticketSeats int) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return saveWithinCapacity(tx, attendee, check)
+	})
+}
+
+func (r *postgresAttendeeRepository) Register(ctx context.Context, attendee *entities.Attendee, now time.Time, check func(eventSeats, ticketSeats int) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := saveWithinCapacity(tx, attendee, check); err != nil {
+			return err
+		}
+		if attendee.InviteLinkID == nil {
+			return nil
+		}
+		return consumeInviteLink(tx, *attendee.InviteLinkID, now)
+	})
+}
+
+func (r *postgresAttendeeRepository) CancelReservation(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := releaseInviteLinkUses(tx, []uint{id}); err != nil {
+			return err
+		}
+		return tx.Model(&entities.Attendee{}).
+			Where("id = ? AND status = ?", id, entities.AttendeeStatusAwaitingPayment).
+			Updates(map[string]interface{}{"status": entities.AttendeeStatusCancelled, "invite_link_id": nil}).Error
+	})
+}
+
+func (r *postgresAttendeeRepository) Cancel(ctx context.Context, attendee *entities.Attendee, refund *entities.Refund, check func(order *entities.Order, refunded int64) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(attendee).Error; err != nil {
+			return err
+		}
+		if refund == nil {
+			return nil
+		}
+		return createRefundWithinOrder(tx, refund, check)
+	})
+}
+
+// saveWithinCapacity saves the attendee once check accepts the seats taken
+// by the event's other going attendees, locking the event meanwhile.
+func saveWithinCapacity(tx *gorm.DB, attendee *entities.Attendee, check func(eventSeats, ticketSeats int) error) error {
+	var event entities.Event
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&event, attendee.EventID).Error
+	if err != nil {
+		return err
+	}
+
+	var seats struct {
+		EventSeats  int
+		TicketSeats int
+	}
+	var ticketTypeID uint
+	if attendee.TicketTypeID != nil {
+		ticketTypeID = *attendee.TicketTypeID
+	}
+	err = tx.Model(&entities.Attendee{}).
+		Select("COALESCE(SUM(1 + guest_count), 0) AS event_seats, COALESCE(SUM(1 + guest_count) FILTER (WHERE ticket_type_id = ?), 0) AS ticket_seats", ticketTypeID).
+		Where("event_id = ? AND status IN ? AND rsvp = ?", attendee.EventID, entities.SeatHoldingStatuses, entities.RSVPGoing).
+		Where("id <> ?", attendee.ID).
+		Scan(&seats).Error
+	if err != nil {
+		return err
+	}
+	if err := check(seats.EventSeats, seats.TicketSeats); err != nil {
+		return err
+	}
+
+	if attendee.ID == 0 {
+		return tx.Create(attendee).Error
+	}
+	return tx.Save(attendee).Error
+}
+
+func (r *postgresAttendeeRepository) CheckIn(ctx context.Context, eventID, userID uint, checkedInAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&entities.Attendee{}).
+		Where("event_id = ? AND user_id = ? AND status = ?", eventID, userID, entities.AttendeeStatusConfirmed).
+		Update("checked_in_at", checkedInAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type postgresCategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresCategoryRepository(db *gorm.DB) repositories.CategoryRepository {
+	return &postgresCategoryRepository{db: db}
+}
+
+func (r *postgresCategoryRepository) Create(ctx context.Context, category *entities.Category) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+func (r *postgresCategoryRepository) GetByID(ctx context.Context, id uint) (*entities.Category, error) {
+	var category entities.Category
+	err := r.db.WithContext(ctx).First(&category, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *postgresCategoryRepository) GetBySlug(ctx context.Context, slug string) (*entities.Category, error) {
+	var category entities.Category
+	err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *postgresCategoryRepository) List(ctx context.Context) ([]*entities.Category, error) {
+	var categories []*entities.Category
+	err := r.db.WithContext(ctx).Order("position, name, id").Find(&categories).Error
+	return categories, err
+}
+
+func (r *postgresCategoryRepository) Update(ctx context.Context, category *entities.Category) error {
+	return r.db.WithContext(ctx).Save(category).Error
+}
+
+func (r *postgresCategoryRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&entities.Category{}, id).Error
+}
+
+func (r *postgresCategoryRepository) CountEvents(ctx context.Context, id uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&entities.Event{}).Where("category_id = ?", id).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresEventCollaboratorRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresEventCollaboratorRepository(db *gorm.DB) repositories.EventCollaboratorRepository {
+	return &postgresEventCollaboratorRepository{db: db}
+}
+
+func (r *postgresEventCollaboratorRepository) Upsert(ctx context.Context, collaborator *entities.EventCollaborator) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "invited_by_id", "updated_at"}),
+	}).Create(collaborator).Error
+}
+
+func (r *postgresEventCollaboratorRepository) Get(ctx context.Context, eventID, userID uint) (*entities.EventCollaborator, error) {
+	var collaborator entities.EventCollaborator
+	err := r.db.WithContext(ctx).Where("event_id = ? AND user_id = ?", eventID, userID).First(&collaborator).Error
+	if err != nil {
+		return nil, err
+	}
+	return &collaborator, nil
+}
+
+func (r *postgresEventCollaboratorRepository) GetByEventID(ctx context.Context, eventID uint) ([]*entities.EventCollaborator, error) {
+	var collaborators []*entities.EventCollaborator
+	err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Preload("User").Order("id").Find(&collaborators).Error
+	return collaborators, err
+}
+
+func (r *postgresEventCollaboratorRepository) Delete(ctx context.Context, eventID, userID uint) error {
+	result := r.db.WithContext(ctx).Where("event_id = ? AND user_id = ?", eventID, userID).Delete(&entities.EventCollaborator{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
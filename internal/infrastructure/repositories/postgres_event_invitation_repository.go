@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresEventInviteLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresEventInviteLinkRepository(db *gorm.DB) repositories.EventInviteLinkRepository {
+	return &postgresEventInviteLinkRepository{db: db}
+}
+
+func (r *postgresEventInviteLinkRepository) Create(ctx context.Context, link *entities.EventInviteLink) error {
+	return r.db.WithContext(ctx).Create(link).Error
+}
+
+func (r *postgresEventInviteLinkRepository) GetByID(ctx context.Context, id uint) (*entities.EventInviteLink, error) {
+	var link entities.EventInviteLink
+	err := r.db.WithContext(ctx).First(&link, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *postgresEventInviteLinkRepository) GetByEventID(ctx context.Context, eventID uint) ([]*entities.EventInviteLink, error) {
+	var links []*entities.EventInviteLink
+	err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Order("created_at DESC").Find(&links).Error
+	return links, err
+}
+
+func (r *postgresEventInviteLinkRepository) Revoke(ctx context.Context, eventID, id uint, revokedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&entities.EventInviteLink{}).
+		Where("id = ? AND event_id = ? AND revoked_at IS NULL", id, eventID).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// consumeInviteLink counts a use of the link, returning
+// gorm.ErrRecordNotFound when it is revoked, expired or used up.
+func consumeInviteLink(tx *gorm.DB, id uint, now time.Time) error {
+	result := tx.Model(&entities.EventInviteLink{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Where("max_uses IS NULL OR uses < max_uses").
+		Update("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// releaseInviteLinkUses gives back the invite link uses of the registrations
+// among attendeeIDs that are still awaiting payment.
+func releaseInviteLinkUses(tx *gorm.DB, attendeeIDs []uint) error {
+	var uses []struct {
+		InviteLinkID uint
+		Count        int
+	}
+	err := tx.Model(&entities.Attendee{}).
+		Select("invite_link_id, COUNT(*) AS count").
+		Where("id IN ? AND status = ? AND invite_link_id IS NOT NULL", attendeeIDs, entities.AttendeeStatusAwaitingPayment).
+		Group("invite_link_id").
+		Scan(&uses).Error
+	if err != nil {
+		return err
+	}
+	for _, use := range uses {
+		err := tx.Model(&entities.EventInviteLink{}).
+			Where("id = ?", use.InviteLinkID).
+			Update("uses", gorm.Expr("GREATEST(uses - ?, 0)", use.Count)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type postgresEventInvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresEventInvitationRepository(db *gorm.DB) repositories.EventInvitationRepository {
+	return &postgresEventInvitationRepository{db: db}
+}
+
+func (r *postgresEventInvitationRepository) Upsert(ctx context.Context, invitation *entities.EventInvitation) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}, {Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "invited_by_id", "responded_at", "updated_at"}),
+	}).Create(invitation).Error
+}
+
+func (r *postgresEventInvitationRepository) GetByID(ctx context.Context, id uint) (*entities.EventInvitation, error) {
+	var invitation entities.EventInvitation
+	err := r.db.WithContext(ctx).Preload("Event").First(&invitation, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *postgresEventInvitationRepository) Get(ctx context.Context, eventID uint, email string) (*entities.EventInvitation, error) {
+	var invitation entities.EventInvitation
+	err := r.db.WithContext(ctx).Where("event_id = ? AND email = ?", eventID, email).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *postgresEventInvitationRepository) GetByEventID(ctx context.Context, eventID uint) ([]*entities.EventInvitation, error) {
+	var invitations []*entities.EventInvitation
+	err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Preload("Event").Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *postgresEventInvitationRepository) GetByEmail(ctx context.Context, email string) ([]*entities.EventInvitation, error) {
+	var invitations []*entities.EventInvitation
+	err := r.db.WithContext(ctx).Where("email = ?", email).Preload("Event").Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *postgresEventInvitationRepository) UpdateStatus(ctx context.Context, id uint, status string, respondedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.EventInvitation{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"status": status, "responded_at": respondedAt}).Error
+}
@@ -3,11 +3,23 @@ package repositories
 import (
 	"EventsAPI/internal/domain/entities"
 	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/pkg/utils"
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// haversineSQL is the great-circle distance in km from the point given by
+// its three parameters (latitude, latitude, longitude) to an event, matching
+// utils.HaversineKm.
+const haversineSQL = `2 * 6371 * ASIN(LEAST(1, SQRT(
+	POWER(SIN(RADIANS(latitude - ?) / 2), 2) +
+	COS(RADIANS(?)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - ?) / 2), 2))))`
+
 type postgresEventRepository struct {
 	db *gorm.DB
 }
@@ -22,17 +34,24 @@ func (r *postgresEventRepository) Create(ctx context.Context, event *entities.Ev
 
 func (r *postgresEventRepository) GetByID(ctx context.Context, id uint) (*entities.Event, error) {
 	var event entities.Event
-	err := r.db.WithContext(ctx).Preload("User").Preload("Attendees.User").First(&event, id).Error
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Attendees", "status = ?", entities.AttendeeStatusConfirmed).
+		Preload("Attendees.User").
+		First(&event, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &event, nil
 }
 
-func (r *postgresEventRepository) GetByUserID(ctx context.Context, userID uint, limit, offset int) ([]*entities.Event, error) {
+func (r *postgresEventRepository) GetManagedByUserID(ctx context.Context, userID uint, limit, offset int) ([]*entities.Event, error) {
+	memberships := r.db.Model(&entities.OrganizationMember{}).Select("organization_id").Where("user_id = ?", userID)
+	collaborations := r.db.Model(&entities.EventCollaborator{}).Select("event_id").Where("user_id = ?", userID)
+
 	var events []*entities.Event
 	err := r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
+		Where("(user_id = ? AND organization_id IS NULL) OR organization_id IN (?) OR id IN (?)", userID, memberships, collaborations).
 		Preload("User").
 		Limit(limit).
 		Offset(offset).
@@ -40,6 +59,35 @@ func (r *postgresEventRepository) GetByUserID(ctx context.Context, userID uint,
 	return events, err
 }
 
+func (r *postgresEventRepository) GetByOrganizationID(ctx context.Context, organizationID uint, limit, offset int) ([]*entities.Event, error) {
+	var events []*entities.Event
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Preload("User").
+		Limit(limit).
+		Offset(offset).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *postgresEventRepository) GetOverlappingAtVenue(ctx context.Context, event *entities.Event) ([]*entities.Event, error) {
+	if event.VenueID == nil {
+		return nil, nil
+	}
+
+	query, args := overlapCondition("events", event)
+	db := r.db.WithContext(ctx).Where("id <> ?", event.ID).Where(query, args...)
+	if event.RoomID != nil {
+		db = db.Where("room_id = ? OR (venue_id = ? AND room_id IS NULL)", *event.RoomID, *event.VenueID)
+	} else {
+		db = db.Where("venue_id = ?", *event.VenueID)
+	}
+
+	var events []*entities.Event
+	err := db.Find(&events).Error
+	return events, err
+}
+
 func (r *postgresEventRepository) Update(ctx context.Context, event *entities.Event) error {
 	return r.db.WithContext(ctx).Save(event).Error
 }
@@ -48,12 +96,170 @@ func (r *postgresEventRepository) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&entities.Event{}, id).Error
 }
 
-func (r *postgresEventRepository) List(ctx context.Context, limit, offset int) ([]*entities.Event, error) {
+func (r *postgresEventRepository) List(ctx context.Context, filter entities.EventFilter, limit, offset int) ([]*entities.Event, error) {
 	var events []*entities.Event
 	err := r.db.WithContext(ctx).
+		Where("visibility = ?", entities.EventVisibilityPublic).
+		Scopes(eventFilterScope(filter)).
+		Preload("User").
+		Limit(limit).
+		Offset(offset).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *postgresEventRepository) ListNear(ctx context.Context, lat, lng, radiusKm float64, from time.Time, filter entities.EventFilter, limit, offset int) ([]*entities.Event, error) {
+	// The bounding box can use the coordinates index; the exact distance
+	// only runs on the events inside it.
+	bounds := utils.BoundingBox(lat, lng, radiusKm)
+	db := r.db.WithContext(ctx).
+		Where("visibility = ?", entities.EventVisibilityPublic).
+		Where("COALESCE(ends_at, date_time) >= ?", from).
+		Scopes(eventFilterScope(filter)).
+		Where("latitude BETWEEN ? AND ?", bounds.MinLat, bounds.MaxLat)
+	if bounds.CrossesAntimeridian() {
+		db = db.Where("longitude >= ? OR longitude <= ?", bounds.MinLng, bounds.MaxLng)
+	} else {
+		db = db.Where("longitude BETWEEN ? AND ?", bounds.MinLng, bounds.MaxLng)
+	}
+
+	distance := clause.Expr{SQL: haversineSQL, Vars: []interface{}{lat, lat, lng}}
+	var events []*entities.Event
+	err := db.
+		Where("? <= ?", distance, radiusKm).
+		Order(clause.OrderBy{Expression: distance}).
 		Preload("User").
 		Limit(limit).
 		Offset(offset).
 		Find(&events).Error
 	return events, err
 }
+
+// searchHeadlineOptions wraps the matched words in <mark> tags; the
+// description snippet keeps up to two fragments.
+const (
+	titleHeadlineOptions   = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	snippetHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+)
+
+// escapeHTMLSQL escapes the HTML special characters of a text expression, so
+// the <mark> tags added by ts_headline are the only markup in highlights.
+func escapeHTMLSQL(expr string) string {
+	return "replace(replace(replace(replace(" + expr + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`
+}
+
+type eventSearchRow struct {
+	entities.Event
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
+
+func (r *postgresEventRepository) Search(ctx context.Context, query, prefixQuery, language string, filter entities.EventFilter, limit, offset int) ([]entities.EventSearchResult, error) {
+	var tsquery clause.Expr
+	switch {
+	case query != "" && prefixQuery != "":
+		tsquery = clause.Expr{SQL: "(websearch_to_tsquery(language::regconfig, ?) && to_tsquery(language::regconfig, ?))", Vars: []interface{}{query, prefixQuery}}
+	case prefixQuery != "":
+		tsquery = clause.Expr{SQL: "to_tsquery(language::regconfig, ?)", Vars: []interface{}{prefixQuery}}
+	default:
+		tsquery = clause.Expr{SQL: "websearch_to_tsquery(language::regconfig, ?)", Vars: []interface{}{query}}
+	}
+
+	db := r.db.WithContext(ctx).Model(&entities.Event{}).
+		Select("events.*, ts_rank(search_vector, ?) AS rank, ts_headline(language::regconfig, "+escapeHTMLSQL("title")+", ?, ?) AS title_highlight, ts_headline(language::regconfig, "+escapeHTMLSQL("coalesce(description, '')")+", ?, ?) AS snippet",
+			tsquery, tsquery, titleHeadlineOptions, tsquery, snippetHeadlineOptions).
+		Where("visibility = ?", entities.EventVisibilityPublic).
+		Where("search_vector @@ ?", tsquery).
+		Scopes(eventFilterScope(filter))
+	if language != "" {
+		db = db.Where("language = ?", language)
+	}
+
+	var rows []eventSearchRow
+	err := db.Order("rank DESC, date_time").Limit(limit).Offset(offset).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]entities.EventSearchResult, len(rows))
+	for i := range rows {
+		results[i] = entities.EventSearchResult{
+			Event:          &rows[i].Event,
+			Rank:           rows[i].Rank,
+			TitleHighlight: rows[i].TitleHighlight,
+			Snippet:        rows[i].Snippet,
+		}
+	}
+	return results, nil
+}
+
+func (r *postgresEventRepository) CountByCategory(ctx context.Context, filter entities.EventFilter, from time.Time) (map[uint]int, error) {
+	var rows []struct {
+		CategoryID uint
+		Count      int
+	}
+	err := r.upcomingPublic(ctx, filter, from).
+		Select("category_id, COUNT(*) AS count").
+		Where("category_id IS NOT NULL").
+		Group("category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *postgresEventRepository) CountByTag(ctx context.Context, filter entities.EventFilter, from time.Time, limit int) ([]entities.TagFacet, error) {
+	facets := []entities.TagFacet{}
+	err := r.upcomingPublic(ctx, filter, from).
+		Joins("CROSS JOIN LATERAL jsonb_array_elements_text(events.tags) AS tag").
+		Select("tag, COUNT(*) AS count").
+		Group("tag").
+		Order("count DESC, tag").
+		Limit(limit).
+		Scan(&facets).Error
+	return facets, err
+}
+
+// upcomingPublic selects the public events matching filter that haven't ended
+// by from.
+func (r *postgresEventRepository) upcomingPublic(ctx context.Context, filter entities.EventFilter, from time.Time) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entities.Event{}).
+		Where("events.visibility = ?", entities.EventVisibilityPublic).
+		Where("COALESCE(events.ends_at, events.date_time) >= ?", from).
+		Scopes(eventFilterScope(filter))
+}
+
+// eventFilterScope applies filter. A category matches its subcategories'
+// events too, and every tag must be present.
+func eventFilterScope(filter entities.EventFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.CategoryID != nil {
+			db = db.Where(`events.category_id IN (
+				WITH RECURSIVE subtree AS (
+					SELECT id FROM categories WHERE id = ?
+					UNION ALL
+					SELECT categories.id FROM categories JOIN subtree ON categories.parent_id = subtree.id
+				)
+				SELECT id FROM subtree)`, *filter.CategoryID)
+		}
+		if len(filter.Tags) > 0 {
+			tags, _ := json.Marshal(filter.Tags)
+			db = db.Where("events.tags @> ?::jsonb", string(tags))
+		}
+		return db
+	}
+}
+
+// overlapCondition matches the events in table that overlap event, the same
+// way as entities.Event.Overlaps: events without an end are an instant.
+func overlapCondition(table string, event *entities.Event) (string, []interface{}) {
+	query := fmt.Sprintf("((%[1]s.date_time < ? AND COALESCE(%[1]s.ends_at, %[1]s.date_time) > ?) OR %[1]s.date_time = ?)", table)
+	return query, []interface{}{event.EndsAtOrStart(), event.DateTime, event.DateTime}
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errAlreadyIssued rolls back the sequence taken by a duplicate invoice.
+var errAlreadyIssued = errors.New("invoice already issued")
+
+type postgresInvoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresInvoiceRepository(db *gorm.DB) repositories.InvoiceRepository {
+	return &postgresInvoiceRepository{db: db}
+}
+
+func (r *postgresInvoiceRepository) GetByOrderID(ctx context.Context, orderID uint) ([]*entities.Invoice, error) {
+	var invoices []*entities.Invoice
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("id").Find(&invoices).Error
+	return invoices, err
+}
+
+func (r *postgresInvoiceRepository) Issue(ctx context.Context, invoice *entities.Invoice, number func(sequence int) string) (bool, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The upsert locks the sequence row until the transaction ends, so
+		// concurrent invoices of a series are numbered one after the other.
+		var sequence int
+		err := tx.Raw(`INSERT INTO invoice_sequences (series, kind, last) VALUES (?, ?, 1)
+			ON CONFLICT (series, kind) DO UPDATE SET last = invoice_sequences.last + 1
+			RETURNING last`, invoice.Series, invoice.Kind).Scan(&sequence).Error
+		if err != nil {
+			return err
+		}
+
+		invoice.Sequence = sequence
+		invoice.Number = number(sequence)
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(invoice)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAlreadyIssued
+		}
+		return nil
+	})
+	if errors.Is(err, errAlreadyIssued) {
+		return false, nil
+	}
+	return err == nil, err
+}
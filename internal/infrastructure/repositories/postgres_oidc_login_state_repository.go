@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type postgresOIDCLoginStateRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresOIDCLoginStateRepository(db *gorm.DB) repositories.OIDCLoginStateRepository {
+	return &postgresOIDCLoginStateRepository{db: db}
+}
+
+func (r *postgresOIDCLoginStateRepository) Create(ctx context.Context, state *entities.OIDCLoginState) error {
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+func (r *postgresOIDCLoginStateRepository) Consume(ctx context.Context, state string) (*entities.OIDCLoginState, error) {
+	var loginState entities.OIDCLoginState
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state = ?", state).First(&loginState).Error; err != nil {
+			return err
+		}
+		// Only the request that actually deletes the row may use it.
+		result := tx.Delete(&entities.OIDCLoginState{}, loginState.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &loginState, nil
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresOrderRepository(db *gorm.DB) repositories.OrderRepository {
+	return &postgresOrderRepository{db: db}
+}
+
+func (r *postgresOrderRepository) Create(ctx context.Context, order *entities.Order) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(order).Error
+}
+
+func (r *postgresOrderRepository) GetByID(ctx context.Context, id uint) (*entities.Order, error) {
+	var order entities.Order
+	err := r.withDetails(ctx).First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *postgresOrderRepository) GetByPaymentID(ctx context.Context, provider, paymentID string) (*entities.Order, error) {
+	var order entities.Order
+	err := r.withDetails(ctx).Where("provider = ? AND payment_id = ?", provider, paymentID).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *postgresOrderRepository) GetPaidByAttendeeID(ctx context.Context, attendeeID uint) (*entities.Order, error) {
+	var order entities.Order
+	err := r.withDetails(ctx).
+		Where("attendee_id = ? AND status = ?", attendeeID, entities.OrderStatusPaid).
+		Order("id DESC").
+		First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *postgresOrderRepository) GetPaidByEventID(ctx context.Context, eventID uint) ([]*entities.Order, error) {
+	var orders []*entities.Order
+	err := r.withDetails(ctx).Where("event_id = ? AND status = ?", eventID, entities.OrderStatusPaid).Order("id").Find(&orders).Error
+	return orders, err
+}
+
+func (r *postgresOrderRepository) GetByEventID(ctx context.Context, eventID uint, status string, limit, offset int) ([]*entities.Order, error) {
+	query := r.withDetails(ctx).Where("event_id = ?", eventID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var orders []*entities.Order
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&orders).Error
+	return orders, err
+}
+
+func (r *postgresOrderRepository) GetByUserID(ctx context.Context, userID uint, limit, offset int) ([]*entities.Order, error) {
+	var orders []*entities.Order
+	err := r.withDetails(ctx).Where("user_id = ?", userID).Order("id DESC").Limit(limit).Offset(offset).Find(&orders).Error
+	return orders, err
+}
+
+func (r *postgresOrderRepository) Update(ctx context.Context, order *entities.Order) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(order).Error
+}
+
+func (r *postgresOrderRepository) SetPayment(ctx context.Context, id uint, paymentID, checkoutURL string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.Order{}).
+		Where("id = ? AND payment_id = ''", id).
+		Updates(map[string]interface{}{"payment_id": paymentID, "checkout_url": checkoutURL})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *postgresOrderRepository) UpdateStatus(ctx context.Context, id uint, from, to string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.Order{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *postgresOrderRepository) ConfirmPayment(ctx context.Context, id uint, paidAt time.Time) (bool, error) {
+	confirmed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order entities.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error
+		if err != nil || order.Status != entities.OrderStatusPending {
+			return err
+		}
+
+		err = tx.Model(&order).Updates(map[string]interface{}{"status": entities.OrderStatusPaid, "paid_at": paidAt}).Error
+		if err != nil {
+			return err
+		}
+		confirmed = true
+		return tx.Model(&entities.Attendee{}).
+			Where("id = ? AND status = ?", order.AttendeeID, entities.AttendeeStatusAwaitingPayment).
+			Update("status", entities.AttendeeStatusConfirmed).Error
+	})
+	return confirmed, err
+}
+
+func (r *postgresOrderRepository) MarkPaid(ctx context.Context, id uint, from string, paidAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.Order{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{"status": entities.OrderStatusPaid, "paid_at": paidAt})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *postgresOrderRepository) Release(ctx context.Context, id uint, status string) (bool, error) {
+	released := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		released, err = releaseOrders(tx, status, "id = ?", id)
+		return err
+	})
+	return released > 0, err
+}
+
+func (r *postgresOrderRepository) ReleaseByEventID(ctx context.Context, eventID uint, status string) (int, error) {
+	released := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		released, err = releaseOrders(tx, status, "event_id = ?", eventID)
+		return err
+	})
+	return released, err
+}
+
+func (r *postgresOrderRepository) ExpirePending(ctx context.Context, now time.Time) (int, error) {
+	expired := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		expired, err = releaseOrders(tx, entities.OrderStatusExpired, "expires_at <= ?", now)
+		return err
+	})
+	return expired, err
+}
+
+func (r *postgresOrderRepository) RevenueByTicketType(ctx context.Context, eventID uint) ([]entities.TicketTypeRevenue, error) {
+	revenue := []entities.TicketTypeRevenue{}
+	err := r.db.WithContext(ctx).Model(&entities.Order{}).
+		Select("orders.ticket_type_id, ticket_types.name, orders.currency, COUNT(*) AS orders, SUM(orders.quantity) AS tickets_sold, SUM(orders.amount) AS amount, SUM(orders.refunded_amount) AS refunded").
+		Joins("LEFT JOIN ticket_types ON ticket_types.id = orders.ticket_type_id").
+		Where("orders.event_id = ? AND orders.status = ?", eventID, entities.OrderStatusPaid).
+		Group("orders.ticket_type_id, ticket_types.name, orders.currency").
+		Order("orders.ticket_type_id").
+		Scan(&revenue).Error
+	return revenue, err
+}
+
+// withDetails loads the order's Event, User, TicketType and Refunds,
+// including a deleted event or ticket type.
+func (r *postgresOrderRepository) withDetails(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Preload("Event", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Refunds", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("User").
+		Preload("TicketType", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+}
+
+// releaseOrders moves the pending orders matching the condition to status
+// and cancels their registrations still awaiting payment, giving back the
+// invite link uses that admitted them. The orders are
+// locked, skipping the ones another transaction is confirming or releasing.
+func releaseOrders(tx *gorm.DB, status string, condition string, args ...interface{}) (int, error) {
+	var orders []entities.Order
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Select("id", "attendee_id").
+		Where(condition, args...).
+		Where("status = ?", entities.OrderStatusPending).
+		Find(&orders).Error
+	if err != nil || len(orders) == 0 {
+		return 0, err
+	}
+
+	orderIDs := make([]uint, len(orders))
+	attendeeIDs := make([]uint, len(orders))
+	for i, order := range orders {
+		orderIDs[i] = order.ID
+		attendeeIDs[i] = order.AttendeeID
+	}
+
+	err = tx.Model(&entities.Order{}).Where("id IN ?", orderIDs).Update("status", status).Error
+	if err != nil {
+		return 0, err
+	}
+	if err := releaseInviteLinkUses(tx, attendeeIDs); err != nil {
+		return 0, err
+	}
+	err = tx.Model(&entities.Attendee{}).
+		Where("id IN ? AND status = ?", attendeeIDs, entities.AttendeeStatusAwaitingPayment).
+		Updates(map[string]interface{}{"status": entities.AttendeeStatusCancelled, "invite_link_id": nil}).Error
+	if err != nil {
+		return 0, err
+	}
+	return len(orders), nil
+}
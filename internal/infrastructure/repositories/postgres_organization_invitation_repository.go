@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type postgresOrganizationInvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresOrganizationInvitationRepository(db *gorm.DB) repositories.OrganizationInvitationRepository {
+	return &postgresOrganizationInvitationRepository{db: db}
+}
+
+func (r *postgresOrganizationInvitationRepository) Create(ctx context.Context, invitation *entities.OrganizationInvitation) error {
+	return r.db.WithContext(ctx).Create(invitation).Error
+}
+
+func (r *postgresOrganizationInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.OrganizationInvitation, error) {
+	var invitation entities.OrganizationInvitation
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *postgresOrganizationInvitationRepository) GetPendingByOrganizationID(ctx context.Context, organizationID uint) ([]*entities.OrganizationInvitation, error) {
+	var invitations []*entities.OrganizationInvitation
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND accepted_at IS NULL AND expires_at > ?", organizationID, time.Now()).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *postgresOrganizationInvitationRepository) MarkAccepted(ctx context.Context, id uint, acceptedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.OrganizationInvitation{}).
+		Where("id = ?", id).
+		Update("accepted_at", acceptedAt).Error
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type postgresOrganizationRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresOrganizationRepository(db *gorm.DB) repositories.OrganizationRepository {
+	return &postgresOrganizationRepository{db: db}
+}
+
+func (r *postgresOrganizationRepository) Create(ctx context.Context, organization *entities.Organization, ownerID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+		return tx.Create(&entities.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         ownerID,
+			Role:           entities.OrganizationRoleOwner,
+		}).Error
+	})
+}
+
+func (r *postgresOrganizationRepository) GetByID(ctx context.Context, id uint) (*entities.Organization, error) {
+	var organization entities.Organization
+	err := r.db.WithContext(ctx).First(&organization, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+func (r *postgresOrganizationRepository) Update(ctx context.Context, organization *entities.Organization) error {
+	return r.db.WithContext(ctx).Omit("Members").Save(organization).Error
+}
+
+func (r *postgresOrganizationRepository) GetMember(ctx context.Context, organizationID, userID uint) (*entities.OrganizationMember, error) {
+	var member entities.OrganizationMember
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *postgresOrganizationRepository) GetMembers(ctx context.Context, organizationID uint) ([]*entities.OrganizationMember, error) {
+	var members []*entities.OrganizationMember
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Preload("User").
+		Order("id").
+		Find(&members).Error
+	return members, err
+}
+
+func (r *postgresOrganizationRepository) GetMembershipsByUserID(ctx context.Context, userID uint) ([]*entities.OrganizationMember, error) {
+	var members []*entities.OrganizationMember
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Joins("Organization").
+		Find(&members).Error
+	return members, err
+}
+
+func (r *postgresOrganizationRepository) AddMember(ctx context.Context, member *entities.OrganizationMember) error {
+	return r.db.WithContext(ctx).Create(member).Error
+}
+
+func (r *postgresOrganizationRepository) UpdateMemberRole(ctx context.Context, organizationID, userID uint, role string) error {
+	return r.db.WithContext(ctx).Model(&entities.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Update("role", role).Error
+}
+
+func (r *postgresOrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID uint) error {
+	return r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Delete(&entities.OrganizationMember{}).Error
+}
+
+func (r *postgresOrganizationRepository) TransferOwnership(ctx context.Context, organizationID, currentOwnerID, newOwnerID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entities.OrganizationMember{}).
+			Where("organization_id = ? AND user_id = ?", organizationID, newOwnerID).
+			Update("role", entities.OrganizationRoleOwner).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&entities.OrganizationMember{}).
+			Where("organization_id = ? AND user_id = ?", organizationID, currentOwnerID).
+			Update("role", entities.OrganizationRoleAdmin).Error
+	})
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresPromoCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresPromoCodeRepository(db *gorm.DB) repositories.PromoCodeRepository {
+	return &postgresPromoCodeRepository{db: db}
+}
+
+func (r *postgresPromoCodeRepository) Create(ctx context.Context, promoCode *entities.PromoCode) error {
+	return r.db.WithContext(ctx).Create(promoCode).Error
+}
+
+func (r *postgresPromoCodeRepository) GetByID(ctx context.Context, id uint) (*entities.PromoCode, error) {
+	var promoCode entities.PromoCode
+	err := r.db.WithContext(ctx).First(&promoCode, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promoCode, nil
+}
+
+func (r *postgresPromoCodeRepository) GetByEventID(ctx context.Context, eventID uint) ([]*entities.PromoCode, error) {
+	var promoCodes []*entities.PromoCode
+	err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Order("code").Find(&promoCodes).Error
+	return promoCodes, err
+}
+
+func (r *postgresPromoCodeRepository) GetByOrganizationID(ctx context.Context, organizationID uint) ([]*entities.PromoCode, error) {
+	var promoCodes []*entities.PromoCode
+	err := r.db.WithContext(ctx).Where("organization_id = ?", organizationID).Order("code").Find(&promoCodes).Error
+	return promoCodes, err
+}
+
+func (r *postgresPromoCodeRepository) FindForEvent(ctx context.Context, code string, eventID uint, organizationID *uint) (*entities.PromoCode, error) {
+	db := r.db.WithContext(ctx).Where("UPPER(code) = UPPER(?)", code)
+	if organizationID != nil {
+		db = db.Where("event_id = ? OR organization_id = ?", eventID, *organizationID)
+	} else {
+		db = db.Where("event_id = ?", eventID)
+	}
+
+	var promoCode entities.PromoCode
+	err := db.Order("event_id IS NULL").First(&promoCode).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promoCode, nil
+}
+
+func (r *postgresPromoCodeRepository) ExistsInScope(ctx context.Context, promoCode *entities.PromoCode) (bool, error) {
+	db := r.db.WithContext(ctx).Model(&entities.PromoCode{}).
+		Where("UPPER(code) = UPPER(?) AND id <> ?", promoCode.Code, promoCode.ID)
+	if promoCode.EventID != nil {
+		db = db.Where("event_id = ?", *promoCode.EventID)
+	} else {
+		db = db.Where("organization_id = ?", *promoCode.OrganizationID)
+	}
+
+	var count int64
+	err := db.Count(&count).Error
+	return count > 0, err
+}
+
+func (r *postgresPromoCodeRepository) Update(ctx context.Context, promoCode *entities.PromoCode) error {
+	return r.db.WithContext(ctx).Save(promoCode).Error
+}
+
+func (r *postgresPromoCodeRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&entities.PromoCode{}, id).Error
+}
+
+func (r *postgresPromoCodeRepository) CountRedemptions(ctx context.Context, promoCodeIDs []uint) (map[uint]int, error) {
+	var rows []struct {
+		PromoCodeID uint
+		Count       int
+	}
+	err := r.activeRedemptions(r.db.WithContext(ctx)).
+		Select("promo_redemptions.promo_code_id, COUNT(*) AS count").
+		Where("promo_redemptions.promo_code_id IN ?", promoCodeIDs).
+		Group("promo_redemptions.promo_code_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.PromoCodeID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *postgresPromoCodeRepository) Redeem(ctx context.Context, redemption *entities.PromoRedemption, check func(redemptions, userRedemptions int) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var promoCode entities.PromoCode
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promoCode, redemption.PromoCodeID).Error
+		if err != nil {
+			return err
+		}
+
+		var counts struct {
+			Redemptions     int
+			UserRedemptions int
+		}
+		err = r.activeRedemptions(tx).
+			Select("COUNT(*) AS redemptions, COUNT(*) FILTER (WHERE promo_redemptions.user_id = ?) AS user_redemptions", redemption.UserID).
+			Where("promo_redemptions.promo_code_id = ?", promoCode.ID).
+			Scan(&counts).Error
+		if err != nil {
+			return err
+		}
+		if err := check(counts.Redemptions, counts.UserRedemptions); err != nil {
+			return err
+		}
+
+		return tx.Omit(clause.Associations).Create(redemption).Error
+	})
+}
+
+func (r *postgresPromoCodeRepository) GetRedemptions(ctx context.Context, promoCodeID uint) ([]*entities.PromoRedemption, error) {
+	var redemptions []*entities.PromoRedemption
+	err := r.db.WithContext(ctx).
+		Where("promo_code_id = ?", promoCodeID).
+		Preload("Order").
+		Order("id DESC").
+		Find(&redemptions).Error
+	return redemptions, err
+}
+
+// activeRedemptions selects the redemptions whose order is pending or paid.
+func (r *postgresPromoCodeRepository) activeRedemptions(db *gorm.DB) *gorm.DB {
+	return db.Model(&entities.PromoRedemption{}).
+		Joins("JOIN orders ON orders.id = promo_redemptions.order_id").
+		Where("orders.status IN ?", []string{entities.OrderStatusPending, entities.OrderStatusPaid})
+}
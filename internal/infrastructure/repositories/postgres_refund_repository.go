@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresRefundRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresRefundRepository(db *gorm.DB) repositories.RefundRepository {
+	return &postgresRefundRepository{db: db}
+}
+
+func (r *postgresRefundRepository) CreateWithinOrder(ctx context.Context, refund *entities.Refund, check func(order *entities.Order, refunded int64) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createRefundWithinOrder(tx, refund, check)
+	})
+}
+
+func (r *postgresRefundRepository) Update(ctx context.Context, refund *entities.Refund) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(refund).Error; err != nil {
+			return err
+		}
+		return syncOrderRefunds(tx, refund.OrderID)
+	})
+}
+
+func (r *postgresRefundRepository) Complete(ctx context.Context, id uint, status, failureMessage string) (bool, error) {
+	completed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var refund entities.Refund
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&refund, id).Error
+		if err != nil || refund.Status != entities.RefundStatusPending {
+			return err
+		}
+
+		err = tx.Model(&refund).Updates(map[string]interface{}{"status": status, "failure_message": failureMessage}).Error
+		if err != nil {
+			return err
+		}
+		completed = true
+		return syncOrderRefunds(tx, refund.OrderID)
+	})
+	return completed, err
+}
+
+func (r *postgresRefundRepository) GetByProviderRefundID(ctx context.Context, provider, refundID string) (*entities.Refund, error) {
+	var refund entities.Refund
+	err := r.db.WithContext(ctx).Where("provider = ? AND provider_refund_id = ?", provider, refundID).First(&refund).Error
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// createRefundWithinOrder creates the refund once check accepts what its
+// order already has refunded, locking the order meanwhile.
+func createRefundWithinOrder(tx *gorm.DB, refund *entities.Refund, check func(order *entities.Order, refunded int64) error) error {
+	var order entities.Order
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, refund.OrderID).Error
+	if err != nil {
+		return err
+	}
+
+	var refunded int64
+	err = tx.Model(&entities.Refund{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("order_id = ? AND status IN ?", order.ID, []string{entities.RefundStatusPending, entities.RefundStatusSucceeded}).
+		Scan(&refunded).Error
+	if err != nil {
+		return err
+	}
+	if err := check(&order, refunded); err != nil {
+		return err
+	}
+
+	if err := tx.Create(refund).Error; err != nil {
+		return err
+	}
+	return syncOrderRefunds(tx, refund.OrderID)
+}
+
+// syncOrderRefunds recomputes the order's refunded amount and refund status
+// from its refunds: pending while any refund is, then refunded or partially
+// refunded by the succeeded amount, or failed when every refund failed.
+func syncOrderRefunds(tx *gorm.DB, orderID uint) error {
+	return tx.Exec(`UPDATE orders SET
+			refunded_amount = totals.succeeded,
+			refund_status = CASE
+				WHEN totals.pending > 0 THEN ?
+				WHEN totals.succeeded > 0 AND totals.succeeded >= orders.amount THEN ?
+				WHEN totals.succeeded > 0 THEN ?
+				WHEN totals.failed > 0 THEN ?
+				ELSE '' END,
+			updated_at = NOW()
+		FROM (
+			SELECT
+				COALESCE(SUM(amount) FILTER (WHERE status = ?), 0) AS succeeded,
+				COUNT(*) FILTER (WHERE status = ?) AS pending,
+				COUNT(*) FILTER (WHERE status = ?) AS failed
+			FROM refunds WHERE order_id = ?
+		) AS totals
+		WHERE orders.id = ?`,
+		entities.OrderRefundPending, entities.OrderRefundRefunded, entities.OrderRefundPartial, entities.OrderRefundFailed,
+		entities.RefundStatusSucceeded, entities.RefundStatusPending, entities.RefundStatusFailed, orderID,
+		orderID).Error
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type postgresRegistrationQuestionRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresRegistrationQuestionRepository(db *gorm.DB) repositories.RegistrationQuestionRepository {
+	return &postgresRegistrationQuestionRepository{db: db}
+}
+
+func (r *postgresRegistrationQuestionRepository) Create(ctx context.Context, question *entities.RegistrationQuestion) error {
+	return r.db.WithContext(ctx).Create(question).Error
+}
+
+func (r *postgresRegistrationQuestionRepository) GetByID(ctx context.Context, id uint) (*entities.RegistrationQuestion, error) {
+	var question entities.RegistrationQuestion
+	err := r.db.WithContext(ctx).First(&question, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+func (r *postgresRegistrationQuestionRepository) GetByEventID(ctx context.Context, eventID uint) ([]*entities.RegistrationQuestion, error) {
+	var questions []*entities.RegistrationQuestion
+	err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Order("position, id").Find(&questions).Error
+	return questions, err
+}
+
+func (r *postgresRegistrationQuestionRepository) Update(ctx context.Context, question *entities.RegistrationQuestion) error {
+	return r.db.WithContext(ctx).Save(question).Error
+}
+
+func (r *postgresRegistrationQuestionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("question_id = ?", id).Delete(&entities.RegistrationAnswer{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.RegistrationQuestion{}, id).Error
+	})
+}
+
+func (r *postgresRegistrationQuestionRepository) ReplaceAnswers(ctx context.Context, attendeeID uint, answers []entities.RegistrationAnswer) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("attendee_id = ?", attendeeID).Delete(&entities.RegistrationAnswer{}).Error; err != nil {
+			return err
+		}
+		if len(answers) == 0 {
+			return nil
+		}
+		for i := range answers {
+			answers[i].AttendeeID = attendeeID
+		}
+		return tx.Create(&answers).Error
+	})
+}
+
+func (r *postgresRegistrationQuestionRepository) GetAnswersByEventID(ctx context.Context, eventID uint) ([]*entities.RegistrationAnswer, error) {
+	var answers []*entities.RegistrationAnswer
+	err := r.db.WithContext(ctx).
+		Joins("JOIN attendees ON attendees.id = registration_answers.attendee_id AND attendees.deleted_at IS NULL").
+		Where("attendees.event_id = ?", eventID).
+		Where("attendees.status IN ?", []string{entities.AttendeeStatusPending, entities.AttendeeStatusConfirmed}).
+		Find(&answers).Error
+	return answers, err
+}
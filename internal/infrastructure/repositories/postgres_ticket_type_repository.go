@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type postgresTicketTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresTicketTypeRepository(db *gorm.DB) repositories.TicketTypeRepository {
+	return &postgresTicketTypeRepository{db: db}
+}
+
+func (r *postgresTicketTypeRepository) Create(ctx context.Context, ticketType *entities.TicketType) error {
+	return r.db.WithContext(ctx).Create(ticketType).Error
+}
+
+func (r *postgresTicketTypeRepository) GetByID(ctx context.Context, id uint) (*entities.TicketType, error) {
+	var ticketType entities.TicketType
+	err := r.db.WithContext(ctx).First(&ticketType, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticketType, nil
+}
+
+func (r *postgresTicketTypeRepository) GetByEventID(ctx context.Context, eventID uint) ([]*entities.TicketType, error) {
+	var ticketTypes []*entities.TicketType
+	err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Order("position, id").Find(&ticketTypes).Error
+	return ticketTypes, err
+}
+
+func (r *postgresTicketTypeRepository) Update(ctx context.Context, ticketType *entities.TicketType) error {
+	return r.db.WithContext(ctx).Save(ticketType).Error
+}
+
+func (r *postgresTicketTypeRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&entities.TicketType{}, id).Error
+}
+
+func (r *postgresTicketTypeRepository) CountRegistrations(ctx context.Context, id uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&entities.Attendee{}).
+		Where("ticket_type_id = ?", id).
+		Where("status IN ?", []string{entities.AttendeeStatusPending, entities.AttendeeStatusConfirmed}).
+		Count(&count).Error
+	return count, err
+}
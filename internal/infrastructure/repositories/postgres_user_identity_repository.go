@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type postgresUserIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresUserIdentityRepository(db *gorm.DB) repositories.UserIdentityRepository {
+	return &postgresUserIdentityRepository{db: db}
+}
+
+func (r *postgresUserIdentityRepository) Create(ctx context.Context, identity *entities.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *postgresUserIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*entities.UserIdentity, error) {
+	var identity entities.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
@@ -29,9 +29,11 @@ func (r *postgresUserRepository) GetByID(ctx context.Context, id uint) (*entitie
 	return &user, nil
 }
 
+// GetByEmail matches emails case-insensitively, as accounts created before
+// emails were normalized may hold mixed-case addresses.
 func (r *postgresUserRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
 	var user entities.User
-	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	err := r.db.WithContext(ctx).Where("LOWER(email) = LOWER(?)", email).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
@@ -51,3 +53,10 @@ func (r *postgresUserRepository) List(ctx context.Context, limit, offset int) ([
 	err := r.db.WithContext(ctx).Limit(limit).Offset(offset).Find(&users).Error
 	return users, err
 }
+
+func (r *postgresUserRepository) GrantAdmin(ctx context.Context, emails []string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&entities.User{}).
+		Where("LOWER(email) IN ? AND NOT is_admin", emails).
+		Update("is_admin", true)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type postgresVenueRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresVenueRepository(db *gorm.DB) repositories.VenueRepository {
+	return &postgresVenueRepository{db: db}
+}
+
+func (r *postgresVenueRepository) Create(ctx context.Context, venue *entities.Venue) error {
+	return r.db.WithContext(ctx).Create(venue).Error
+}
+
+func (r *postgresVenueRepository) GetByID(ctx context.Context, id uint) (*entities.Venue, error) {
+	var venue entities.Venue
+	err := r.db.WithContext(ctx).Preload("Rooms").First(&venue, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &venue, nil
+}
+
+func (r *postgresVenueRepository) List(ctx context.Context, limit, offset int) ([]*entities.Venue, error) {
+	var venues []*entities.Venue
+	err := r.db.WithContext(ctx).Preload("Rooms").Order("name, id").Limit(limit).Offset(offset).Find(&venues).Error
+	return venues, err
+}
+
+func (r *postgresVenueRepository) Update(ctx context.Context, venue *entities.Venue) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Rooms").Save(venue).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entities.Event{}).
+			Where("venue_id = ?", venue.ID).
+			Updates(map[string]interface{}{"latitude": venue.Latitude, "longitude": venue.Longitude}).Error
+	})
+}
+
+func (r *postgresVenueRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("venue_id = ?", id).Delete(&entities.VenueRoom{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.Venue{}, id).Error
+	})
+}
+
+func (r *postgresVenueRepository) CreateRoom(ctx context.Context, room *entities.VenueRoom) error {
+	return r.db.WithContext(ctx).Create(room).Error
+}
+
+func (r *postgresVenueRepository) GetRoom(ctx context.Context, id uint) (*entities.VenueRoom, error) {
+	var room entities.VenueRoom
+	err := r.db.WithContext(ctx).First(&room, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (r *postgresVenueRepository) UpdateRoom(ctx context.Context, room *entities.VenueRoom) error {
+	return r.db.WithContext(ctx).Save(room).Error
+}
+
+func (r *postgresVenueRepository) DeleteRoom(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&entities.VenueRoom{}, id).Error
+}
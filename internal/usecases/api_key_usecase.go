@@ -0,0 +1,141 @@
+package usecases
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// API keys look like "evk_<8 char id>_<secret>"; "evk_<8 char id>" is the
+// visible prefix stored in clear text.
+const (
+	apiKeyPrefix    = "evk_"
+	apiKeyPrefixLen = len(apiKeyPrefix) + 8
+)
+
+var (
+	ErrAPIKeyNotFound   = errors.New("API key not found")
+	ErrInvalidAPIKey    = errors.New("invalid API key")
+	ErrAPIKeyExpiryPast = errors.New("API key expiry must be in the future")
+)
+
+type APIKeyUseCase struct {
+	apiKeyRepo repositories.APIKeyRepository
+}
+
+func NewAPIKeyUseCase(apiKeyRepo repositories.APIKeyRepository) *APIKeyUseCase {
+	return &APIKeyUseCase{apiKeyRepo: apiKeyRepo}
+}
+
+// CreateAPIKey stores a new key and returns it in clear text. This is the only
+// time the full key is available.
+func (uc *APIKeyUseCase) CreateAPIKey(ctx context.Context, userID uint, req *entities.APIKeyRequest) (*entities.APIKeyCreatedResponse, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrAPIKeyExpiryPast
+	}
+
+	id, err := utils.GenerateRandomToken(6)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	prefix := apiKeyPrefix + id
+	rawKey := prefix + "_" + secret
+
+	apiKey := &entities.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashToken(rawKey),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := uc.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &entities.APIKeyCreatedResponse{
+		APIKeyResponse: toAPIKeyResponse(apiKey),
+		Key:            rawKey,
+	}, nil
+}
+
+func (uc *APIKeyUseCase) ListAPIKeys(ctx context.Context, userID uint) ([]entities.APIKeyResponse, error) {
+	apiKeys, err := uc.apiKeyRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entities.APIKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		response = append(response, toAPIKeyResponse(apiKey))
+	}
+	return response, nil
+}
+
+func (uc *APIKeyUseCase) RevokeAPIKey(ctx context.Context, userID, id uint) error {
+	apiKey, err := uc.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+	if apiKey.UserID != userID {
+		return ErrAPIKeyNotFound
+	}
+
+	return uc.apiKeyRepo.Revoke(ctx, id, time.Now())
+}
+
+// Authenticate resolves a raw key to its active APIKey (with User loaded) and
+// records when it was last used.
+func (uc *APIKeyUseCase) Authenticate(ctx context.Context, rawKey string) (*entities.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) || len(rawKey) <= apiKeyPrefixLen || rawKey[apiKeyPrefixLen] != '_' {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := uc.apiKeyRepo.GetByPrefix(ctx, rawKey[:apiKeyPrefixLen])
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(rawKey)), []byte(apiKey.KeyHash)) != 1 || !apiKey.IsActive(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := uc.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, now); err != nil {
+		return nil, err
+	}
+	apiKey.LastUsedAt = &now
+
+	return apiKey, nil
+}
+
+func toAPIKeyResponse(apiKey *entities.APIKey) entities.APIKeyResponse {
+	return entities.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
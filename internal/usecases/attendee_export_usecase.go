@@ -0,0 +1,165 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
+
+	"gorm.io/gorm"
+)
+
+var ErrUnsupportedExportFormat = errors.New("unsupported export format")
+
+// attendeeExportColumns are the columns of every attendee export, followed by
+// one per registration question.
+var attendeeExportColumns = []string{
+	"first_name", "last_name", "email", "status", "rsvp", "guest_count", "registered_at", "checked_in_at",
+}
+
+// AttendeeExportUseCase exports the attendee lists of events for their
+// organizers.
+type AttendeeExportUseCase struct {
+	attendeeRepo repositories.AttendeeRepository
+	eventRepo    repositories.EventRepository
+	questionRepo repositories.RegistrationQuestionRepository
+	authorizer   *EventAuthorizer
+	formats      map[string]services.TableFormat
+}
+
+func NewAttendeeExportUseCase(
+	attendeeRepo repositories.AttendeeRepository,
+	eventRepo repositories.EventRepository,
+	questionRepo repositories.RegistrationQuestionRepository,
+	authorizer *EventAuthorizer,
+	formats []services.TableFormat,
+) *AttendeeExportUseCase {
+	byName := make(map[string]services.TableFormat, len(formats))
+	for _, format := range formats {
+		byName[format.Name()] = format
+	}
+	return &AttendeeExportUseCase{
+		attendeeRepo: attendeeRepo,
+		eventRepo:    eventRepo,
+		questionRepo: questionRepo,
+		authorizer:   authorizer,
+		formats:      byName,
+	}
+}
+
+// AttendeeExport is an authorized export ready to be streamed.
+type AttendeeExport struct {
+	ContentType string
+	Filename    string
+	write       func(w io.Writer) error
+}
+
+// Stream writes the export to w row by row.
+func (e *AttendeeExport) Stream(w io.Writer) error {
+	return e.write(w)
+}
+
+// ExportAttendees prepares the export of the event's registrations, optionally
+// only the ones with the given status, in the named format. Only the users
+// who manage the event's attendees can export them; check-in staff can view
+// the attendees but not take their contact details away. Checks happen here
+// so they fail before anything is streamed.
+func (uc *AttendeeExportUseCase) ExportAttendees(ctx context.Context, userID, eventID uint, format, status string) (*AttendeeExport, error) {
+	tableFormat, ok := uc.formats[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedExportFormat, format)
+	}
+
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	if err := uc.authorizer.Authorize(ctx, event, userID, entities.PermissionManageAttendees); err != nil {
+		return nil, err
+	}
+
+	questions, err := uc.questionRepo.GetByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttendeeExport{
+		ContentType: tableFormat.ContentType(),
+		Filename:    fmt.Sprintf("attendees-event-%d.%s", eventID, tableFormat.Extension()),
+		write: func(w io.Writer) error {
+			return uc.writeAttendees(ctx, w, tableFormat, eventID, status, questions)
+		},
+	}, nil
+}
+
+func (uc *AttendeeExportUseCase) writeAttendees(ctx context.Context, w io.Writer, format services.TableFormat, eventID uint, status string, questions []*entities.RegistrationQuestion) error {
+	columns := append(append([]string{}, attendeeExportColumns...), questionColumns(questions)...)
+	column := make(map[uint]int, len(questions))
+	for i, question := range questions {
+		column[question.ID] = len(attendeeExportColumns) + i
+	}
+
+	table, err := format.NewWriter(w, columns)
+	if err != nil {
+		return err
+	}
+	row := make([]any, len(columns))
+	err = uc.attendeeRepo.EachByEventID(ctx, eventID, status, func(attendees []*entities.Attendee) error {
+		for _, attendee := range attendees {
+			clear(row)
+			row[0] = attendee.User.FirstName
+			row[1] = attendee.User.LastName
+			row[2] = attendee.User.Email
+			row[3] = attendee.Status
+			row[4] = attendee.RSVP
+			row[5] = attendee.GuestCount
+			row[6] = attendee.CreatedAt
+			if attendee.CheckedInAt != nil {
+				row[7] = *attendee.CheckedInAt
+			}
+			for _, answer := range attendee.Answers {
+				i, ok := column[answer.QuestionID]
+				if !ok {
+					continue
+				}
+				answer.Question = *questions[i-len(attendeeExportColumns)]
+				row[i] = answer.JSONValue()
+			}
+			if err := table.WriteRow(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return table.Close()
+}
+
+// questionColumns names the columns of the questions after their labels,
+// numbering the labels already taken so every column has its own name.
+func questionColumns(questions []*entities.RegistrationQuestion) []string {
+	columns := make([]string, len(questions))
+	seen := make(map[string]int, len(attendeeExportColumns)+len(questions))
+	for _, column := range attendeeExportColumns {
+		seen[column] = 1
+	}
+	for i, question := range questions {
+		label := strings.TrimSpace(question.Label)
+		seen[label]++
+		if seen[label] > 1 {
+			label = fmt.Sprintf("%s (%d)", label, seen[label])
+		}
+		columns[i] = label
+	}
+	return columns
+}
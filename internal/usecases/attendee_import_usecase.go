@@ -0,0 +1,529 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidImport          = errors.New("invalid attendee import")
+	ErrAttendeeImportNotFound = errors.New("attendee import not found")
+)
+
+const (
+	// importMaxBytes and importMaxRows bound the files accepted for import.
+	importMaxBytes = 10 << 20
+	importMaxRows  = 50000
+	// importInlineRows is the most rows imported within the request; larger
+	// files are imported in the background.
+	importInlineRows = 200
+	// importProgressRows and importProgressInterval are how often a
+	// background import saves its progress.
+	importProgressRows     = 500
+	importProgressInterval = time.Minute
+	// importStaleAfter is how long a running import can go without saving
+	// its progress before it is taken for interrupted.
+	importStaleAfter = 15 * time.Minute
+)
+
+// AttendeeImportUseCase registers attendees in bulk from CSV files, for
+// organizers moving their events from spreadsheets.
+type AttendeeImportUseCase struct {
+	importRepo   repositories.AttendeeImportRepository
+	attendeeRepo repositories.AttendeeRepository
+	eventRepo    repositories.EventRepository
+	userRepo     repositories.UserRepository
+	authorizer   *EventAuthorizer
+	attendees    *AttendeeUseCase
+	tickets      *TicketTypeUseCase
+	sso          *OIDCUseCase
+	notifier     services.Notifier
+}
+
+func NewAttendeeImportUseCase(
+	importRepo repositories.AttendeeImportRepository,
+	attendeeRepo repositories.AttendeeRepository,
+	eventRepo repositories.EventRepository,
+	userRepo repositories.UserRepository,
+	authorizer *EventAuthorizer,
+	attendees *AttendeeUseCase,
+	tickets *TicketTypeUseCase,
+	sso *OIDCUseCase,
+	notifier services.Notifier,
+) *AttendeeImportUseCase {
+	return &AttendeeImportUseCase{
+		importRepo:   importRepo,
+		attendeeRepo: attendeeRepo,
+		eventRepo:    eventRepo,
+		userRepo:     userRepo,
+		authorizer:   authorizer,
+		attendees:    attendees,
+		tickets:      tickets,
+		sso:          sso,
+		notifier:     notifier,
+	}
+}
+
+// importedRow is a row of an imported file.
+type importedRow struct {
+	line      int
+	email     string
+	firstName string
+	lastName  string
+}
+
+// ImportAttendees registers the people listed in a CSV file for the event. The
+// file has a header row with an email column and optional first_name and
+// last_name, or name, columns. Emails are matched with existing users. When
+// single sign-on is configured, the others get a new account without a
+// password, which they can sign in to through it; otherwise they aren't
+// registered but invited by email to sign up and register. Everyone registered
+// is told by email. Registrations are confirmed without the event's approval
+// or registration window, but within its capacity and the ticket type's
+// quantity. Cancelled registrations are reopened, but the ones an organizer
+// rejected are left as they are. Small files are imported before returning;
+// larger ones keep running in the background and the returned import is still
+// running. A dry run reports what would happen without registering anyone or
+// creating accounts.
+func (uc *AttendeeImportUseCase) ImportAttendees(ctx context.Context, userID, eventID uint, file io.Reader, req *entities.AttendeeImportRequest) (*entities.AttendeeImport, error) {
+	event, err := uc.authorizedEvent(ctx, userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	ticketType, err := uc.importTicketType(ctx, event, req.TicketTypeID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := parseImportFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	attendeeImport := &entities.AttendeeImport{
+		EventID:      eventID,
+		CreatedByID:  userID,
+		TicketTypeID: req.TicketTypeID,
+		DryRun:       req.DryRun,
+		Status:       entities.AttendeeImportRunning,
+		Total:        len(rows),
+	}
+	if err := uc.importRepo.Create(ctx, attendeeImport); err != nil {
+		return nil, err
+	}
+
+	if len(rows) <= importInlineRows {
+		if err := uc.run(ctx, attendeeImport, event, ticketType, rows); err != nil {
+			return nil, err
+		}
+		return attendeeImport, nil
+	}
+
+	// The import outlives the request, so it gets its own copy and a context
+	// that isn't cancelled with the request. Its outcome is saved on the
+	// import for GetAttendeeImport to report, a crash included.
+	running := *attendeeImport
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				_ = uc.finish(ctx, &running, fmt.Errorf("the import stopped unexpectedly: %v", r))
+			}
+		}()
+		_ = uc.run(ctx, &running, event, ticketType, rows)
+	}()
+	return attendeeImport, nil
+}
+
+// FailInterruptedImports marks as failed the imports left running by a
+// previous process, which stopped saving their progress, and returns how
+// many there were. It is meant to run at startup.
+func (uc *AttendeeImportUseCase) FailInterruptedImports(ctx context.Context, now time.Time) (int64, error) {
+	return uc.importRepo.FailRunning(ctx, now.Add(-importStaleAfter), "the import was interrupted; import the file again to finish it")
+}
+
+// GetAttendeeImport returns an import of the event with its progress and,
+// once completed, the result of every row.
+func (uc *AttendeeImportUseCase) GetAttendeeImport(ctx context.Context, userID, eventID, importID uint) (*entities.AttendeeImport, error) {
+	if _, err := uc.authorizedEvent(ctx, userID, eventID); err != nil {
+		return nil, err
+	}
+
+	attendeeImport, err := uc.importRepo.GetByID(ctx, importID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttendeeImportNotFound
+		}
+		return nil, err
+	}
+	if attendeeImport.EventID != eventID {
+		return nil, ErrAttendeeImportNotFound
+	}
+	return attendeeImport, nil
+}
+
+// run imports the rows one by one, saving the progress every
+// importProgressRows rows or importProgressInterval, and the outcome at the
+// end.
+func (uc *AttendeeImportUseCase) run(ctx context.Context, attendeeImport *entities.AttendeeImport, event *entities.Event, ticketType *entities.TicketType, rows []importedRow) error {
+	importer, err := uc.newImporter(ctx, event, ticketType, attendeeImport.DryRun)
+	if err == nil {
+		attendeeImport.Rows = make([]entities.AttendeeImportRow, 0, len(rows))
+		saved := time.Now()
+		for _, row := range rows {
+			result := importer.importRow(ctx, &row)
+			attendeeImport.Rows = append(attendeeImport.Rows, result)
+			attendeeImport.Summary.Add(&result)
+			attendeeImport.Processed++
+			if attendeeImport.Processed < attendeeImport.Total &&
+				(attendeeImport.Processed%importProgressRows == 0 || time.Since(saved) >= importProgressInterval) {
+				if err = uc.importRepo.Update(ctx, attendeeImport); err != nil {
+					break
+				}
+				saved = time.Now()
+			}
+		}
+	}
+	return uc.finish(ctx, attendeeImport, err)
+}
+
+// finish saves the outcome of an import: completed, or failed with err.
+func (uc *AttendeeImportUseCase) finish(ctx context.Context, attendeeImport *entities.AttendeeImport, err error) error {
+	now := time.Now()
+	attendeeImport.CompletedAt = &now
+	attendeeImport.Status = entities.AttendeeImportCompleted
+	if err != nil {
+		attendeeImport.Status = entities.AttendeeImportFailed
+		attendeeImport.Error = err.Error()
+	}
+	return uc.importRepo.Update(ctx, attendeeImport)
+}
+
+// attendeeImporter imports the rows of a file. It remembers the emails
+// already seen and, in a dry run, counts the seats the rows would take.
+type attendeeImporter struct {
+	uc          *AttendeeImportUseCase
+	event       *entities.Event
+	ticketType  *entities.TicketType
+	dryRun      bool
+	seen        map[string]bool
+	eventSeats  int
+	ticketSeats int
+}
+
+func (uc *AttendeeImportUseCase) newImporter(ctx context.Context, event *entities.Event, ticketType *entities.TicketType, dryRun bool) (*attendeeImporter, error) {
+	importer := &attendeeImporter{
+		uc:         uc,
+		event:      event,
+		ticketType: ticketType,
+		dryRun:     dryRun,
+		seen:       make(map[string]bool),
+	}
+	if !dryRun {
+		return importer, nil
+	}
+
+	var err error
+	if importer.eventSeats, err = uc.attendeeRepo.CountSeatsByEventID(ctx, event.ID); err != nil {
+		return nil, err
+	}
+	if ticketType != nil {
+		taken, err := uc.attendeeRepo.CountSeatsByTicketType(ctx, event.ID)
+		if err != nil {
+			return nil, err
+		}
+		importer.ticketSeats = taken[ticketType.ID]
+	}
+	return importer, nil
+}
+
+func (i *attendeeImporter) importRow(ctx context.Context, row *importedRow) entities.AttendeeImportRow {
+	result := entities.AttendeeImportRow{Row: row.line, Email: row.email}
+	email, ok := normalizeEmail(row.email)
+	if !ok {
+		result.Result = entities.ImportRowInvalidEmail
+		return result
+	}
+	result.Email = email
+	if i.seen[email] {
+		result.Result = entities.ImportRowAlreadyRegistered
+		return result
+	}
+	i.seen[email] = true
+
+	fail := func(err error) entities.AttendeeImportRow {
+		result.Result = entities.ImportRowFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	user, invited, err := i.user(ctx, email, row)
+	if err != nil {
+		return fail(err)
+	}
+	result.Invited = invited
+	if user == nil {
+		result.Result = entities.ImportRowInvited
+		if !i.dryRun {
+			if err := i.inviteToSignUp(ctx, email); err != nil {
+				result.Error = err.Error()
+			}
+		}
+		return result
+	}
+	result.UserID = user.ID
+
+	var existing *entities.Attendee
+	if user.ID != 0 {
+		existing, err = i.uc.attendeeRepo.Get(ctx, i.event.ID, user.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fail(err)
+		}
+		if existing != nil && (existing.IsActive() || existing.Status == entities.AttendeeStatusAwaitingPayment) {
+			result.Result = entities.ImportRowAlreadyRegistered
+			return result
+		}
+		// An organizer turned the registration down; importing doesn't
+		// overrule that.
+		if existing != nil && existing.Status == entities.AttendeeStatusRejected {
+			result.Result = entities.ImportRowRejected
+			return result
+		}
+	}
+
+	if i.dryRun {
+		if err := capacityCheck(i.event, i.ticketType, 1)(i.eventSeats, i.ticketSeats); err != nil {
+			result.Result = entities.ImportRowCapacityExceeded
+			result.Error = err.Error()
+			return result
+		}
+		i.eventSeats++
+		i.ticketSeats++
+		result.Result = entities.ImportRowCreated
+		return result
+	}
+
+	attendee := existing
+	if attendee == nil {
+		attendee = &entities.Attendee{EventID: i.event.ID, UserID: user.ID}
+	}
+	attendee.Status = entities.AttendeeStatusConfirmed
+	attendee.StatusMessage = ""
+	attendee.RSVP = entities.RSVPGoing
+	attendee.TicketTypeID = nil
+	if i.ticketType != nil {
+		attendee.TicketTypeID = &i.ticketType.ID
+	}
+	attendee.GuestCount = 0
+	attendee.GuestNames = nil
+	attendee.CheckedInAt = nil
+	if err := i.uc.attendees.saveWithinCapacity(ctx, i.event, i.ticketType, attendee); err != nil {
+		if errors.Is(err, ErrEventFull) || errors.Is(err, ErrTicketSoldOut) {
+			result.Result = entities.ImportRowCapacityExceeded
+			result.Error = err.Error()
+			return result
+		}
+		return fail(err)
+	}
+	result.Result = entities.ImportRowCreated
+
+	// The registration stands even if the email can't be sent.
+	if err := i.notify(ctx, user, invited); err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// user finds the user with the email or, when there is none, creates an
+// account for it. In a dry run the account is only made up, with no ID.
+// Without single sign-on nobody could sign in to an account without a
+// password, so none is created and the user is nil.
+func (i *attendeeImporter) user(ctx context.Context, email string, row *importedRow) (*entities.User, bool, error) {
+	user, err := i.uc.userRepo.GetByEmail(ctx, email)
+	if err == nil {
+		return user, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+	if !i.uc.sso.HasProviders() {
+		return nil, true, nil
+	}
+
+	user = &entities.User{Email: email, FirstName: row.firstName, LastName: row.lastName}
+	if user.FirstName == "" {
+		user.FirstName, _, _ = strings.Cut(email, "@")
+	}
+	if i.dryRun {
+		return user, true, nil
+	}
+	if err := i.uc.userRepo.Create(ctx, user); err != nil {
+		// The email may have signed up meanwhile.
+		if existing, getErr := i.uc.userRepo.GetByEmail(ctx, email); getErr == nil {
+			return existing, false, nil
+		}
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+func (i *attendeeImporter) notify(ctx context.Context, user *entities.User, invited bool) error {
+	body := fmt.Sprintf("You are registered for %s on %s.", i.event.Title, i.event.DateTime.Format(time.RFC1123))
+	if invited {
+		body += fmt.Sprintf("\n\nAn account was created for %s. Sign in with this email through single sign-on to manage your registration.", user.Email)
+	}
+	return i.uc.notifier.Notify(ctx, services.Notification{
+		To:      user.Email,
+		Subject: fmt.Sprintf("You are registered for %s", i.event.Title),
+		Body:    body,
+	})
+}
+
+// inviteToSignUp asks someone without an account to sign up with their email
+// and register for the event.
+func (i *attendeeImporter) inviteToSignUp(ctx context.Context, email string) error {
+	return i.uc.notifier.Notify(ctx, services.Notification{
+		To:      email,
+		Subject: fmt.Sprintf("You are invited to %s", i.event.Title),
+		Body:    fmt.Sprintf("You are invited to %s on %s. Sign up with this email to register.", i.event.Title, i.event.DateTime.Format(time.RFC1123)),
+	})
+}
+
+// importTicketType returns the ticket type the imported attendees get. It is
+// required when the event has ticket types and must be free, since paid
+// tickets are bought through orders. Its sales window doesn't apply.
+func (uc *AttendeeImportUseCase) importTicketType(ctx context.Context, event *entities.Event, ticketTypeID *uint) (*entities.TicketType, error) {
+	if ticketTypeID == nil {
+		return uc.tickets.SelectTicketType(ctx, event, nil, "", 1, time.Now())
+	}
+
+	ticketType, err := uc.tickets.eventTicketType(ctx, event.ID, *ticketTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if !ticketType.IsFree() {
+		return nil, fmt.Errorf("%w: %s is a paid ticket type", ErrInvalidImport, ticketType.Name)
+	}
+	return ticketType, nil
+}
+
+func (uc *AttendeeImportUseCase) authorizedEvent(ctx context.Context, userID, eventID uint) (*entities.Event, error) {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+
+	if err := uc.authorizer.Authorize(ctx, event, userID, entities.PermissionManageAttendees); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// parseImportFile reads the rows of a CSV file, skipping blank ones. Files
+// saved by spreadsheets with a semicolon separator, as some locales do, are
+// read too.
+func parseImportFile(file io.Reader) ([]importedRow, error) {
+	data, err := io.ReadAll(io.LimitReader(file, importMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > importMaxBytes {
+		return nil, fmt.Errorf("%w: the file is larger than %d MB", ErrInvalidImport, importMaxBytes>>20)
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("%w: the header has no email column", ErrInvalidImport)
+	}
+
+	var rows []importedRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		if len(rows) == importMaxRows {
+			return nil, fmt.Errorf("%w: the file has more than %d rows", ErrInvalidImport, importMaxRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := importedRow{
+			line:      line,
+			email:     csvField(record, columns, "email"),
+			firstName: csvField(record, columns, "first_name"),
+			lastName:  csvField(record, columns, "last_name"),
+		}
+		if row.firstName == "" && row.lastName == "" {
+			row.firstName, row.lastName, _ = strings.Cut(csvField(record, columns, "name"), " ")
+			row.lastName = strings.TrimSpace(row.lastName)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file has no rows", ErrInvalidImport)
+	}
+	return rows, nil
+}
+
+// csvField returns the trimmed value of the named column, empty when the file
+// doesn't have it.
+func csvField(record []string, columns map[string]int, name string) string {
+	i, ok := columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// normalizeEmail lowercases a bare email address, reporting whether it is
+// valid.
+func normalizeEmail(email string) (string, bool) {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Name != "" || address.Address != email || !strings.Contains(email, "@") {
+		return "", false
+	}
+	return strings.ToLower(email), true
+}
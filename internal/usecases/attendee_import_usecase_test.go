@@ -0,0 +1,340 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
+
+	"gorm.io/gorm"
+)
+
+// memoryAttendeeImportRepository is safe for the background imports to use
+// while a test polls it.
+type memoryAttendeeImportRepository struct {
+	repositories.AttendeeImportRepository
+	mu      sync.Mutex
+	imports map[uint]entities.AttendeeImport
+}
+
+func (r *memoryAttendeeImportRepository) Create(ctx context.Context, attendeeImport *entities.AttendeeImport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attendeeImport.ID = uint(len(r.imports) + 1)
+	r.imports[attendeeImport.ID] = *attendeeImport
+	return nil
+}
+
+func (r *memoryAttendeeImportRepository) GetByID(ctx context.Context, id uint) (*entities.AttendeeImport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attendeeImport, ok := r.imports[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &attendeeImport, nil
+}
+
+func (r *memoryAttendeeImportRepository) Update(ctx context.Context, attendeeImport *entities.AttendeeImport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row := *attendeeImport
+	row.Rows = slices.Clone(row.Rows)
+	r.imports[row.ID] = row
+	return nil
+}
+
+// panickingNotifier crashes whatever sends a notification.
+type panickingNotifier struct{}
+
+func (panickingNotifier) Notify(ctx context.Context, notification services.Notification) error {
+	panic("notifier crashed")
+}
+
+// stubIdentityProvider stands for a configured single sign-on provider.
+type stubIdentityProvider struct {
+	services.IdentityProvider
+}
+
+func (stubIdentityProvider) Name() string {
+	return "sso"
+}
+
+// newImportFixture returns an order fixture whose ticket type is free, with
+// quantity tickets, and the import use case of its event, with single sign-on
+// configured when sso is set.
+func newImportFixture(t *testing.T, quantity int, sso bool, notifier services.Notifier) (*orderFixture, *memoryAttendeeImportRepository, *AttendeeImportUseCase) {
+	t.Helper()
+	f := newOrderFixture(t)
+	f.ticketType.Price = 0
+	f.ticketType.Quantity = quantity
+	f.store.ticketTypes[f.ticketType.ID] = f.ticketType
+
+	imports := &memoryAttendeeImportRepository{imports: map[uint]entities.AttendeeImport{}}
+	eventRepo := &memoryEventRepository{store: f.store}
+	attendeeRepo := &memoryAttendeeRepository{store: f.store}
+	authorizer := NewEventAuthorizer(nil, nil)
+	tickets := NewTicketTypeUseCase(&memoryTicketTypeRepository{store: f.store}, attendeeRepo, eventRepo, authorizer, nil, nil)
+	var providers []services.IdentityProvider
+	if sso {
+		providers = append(providers, stubIdentityProvider{})
+	}
+	oidcUseCase := NewOIDCUseCase(f.store.users, nil, nil, providers, nil)
+	uc := NewAttendeeImportUseCase(imports, attendeeRepo, eventRepo, f.store.users, authorizer, f.attendees, tickets, oidcUseCase, notifier)
+	return f, imports, uc
+}
+
+// importFile returns a CSV file listing n new attendees.
+func importFile(n int) string {
+	var file strings.Builder
+	file.WriteString("email\n")
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&file, "attendee%d@example.com\n", i)
+	}
+	return file.String()
+}
+
+func TestImportAttendeesRecordsCrash(t *testing.T) {
+	f, imports, uc := newImportFixture(t, 1000, true, panickingNotifier{})
+
+	started, err := uc.ImportAttendees(context.Background(), f.event.UserID, f.event.ID, strings.NewReader(importFile(importInlineRows+1)), &entities.AttendeeImportRequest{
+		TicketTypeID: &f.ticketType.ID,
+	})
+	if err != nil {
+		t.Fatalf("ImportAttendees: %v", err)
+	}
+	if started.Status != entities.AttendeeImportRunning {
+		t.Fatalf("import status = %q, want it running in the background", started.Status)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		attendeeImport, err := imports.GetByID(context.Background(), started.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if attendeeImport.Status != entities.AttendeeImportRunning {
+			if attendeeImport.Status != entities.AttendeeImportFailed || !strings.Contains(attendeeImport.Error, "notifier crashed") || attendeeImport.CompletedAt == nil {
+				t.Errorf("import = %q %q, want failed with the crash", attendeeImport.Status, attendeeImport.Error)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the crashed import is still running")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestImportAttendeesWithoutAccount(t *testing.T) {
+	tests := []struct {
+		name        string
+		sso         bool
+		dryRun      bool
+		wantResult  string
+		wantUser    bool
+		wantNotices int
+	}{
+		{name: "single sign-on", sso: true, wantResult: entities.ImportRowCreated, wantUser: true, wantNotices: 1},
+		{name: "no single sign-on", wantResult: entities.ImportRowInvited, wantNotices: 1},
+		{name: "dry run without single sign-on", dryRun: true, wantResult: entities.ImportRowInvited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &recordingNotifier{}
+			f, _, uc := newImportFixture(t, 10, tt.sso, notifier)
+
+			attendeeImport, err := uc.ImportAttendees(context.Background(), f.event.UserID, f.event.ID, strings.NewReader("email,name\nnew@example.com,Ada Lovelace\n"), &entities.AttendeeImportRequest{
+				TicketTypeID: &f.ticketType.ID,
+				DryRun:       tt.dryRun,
+			})
+			if err != nil {
+				t.Fatalf("ImportAttendees: %v", err)
+			}
+			row := attendeeImport.Rows[0]
+			if row.Result != tt.wantResult || !row.Invited {
+				t.Errorf("row = %q invited %v, want %q invited", row.Result, row.Invited, tt.wantResult)
+			}
+
+			user, err := f.store.users.GetByEmail(context.Background(), "new@example.com")
+			if (err == nil) != tt.wantUser {
+				t.Fatalf("account created = %v, want %v", err == nil, tt.wantUser)
+			}
+			if tt.wantUser && (row.UserID != user.ID || user.FirstName != "Ada" || user.LastName != "Lovelace") {
+				t.Errorf("row user %d = %q %q, want %d Ada Lovelace", row.UserID, user.FirstName, user.LastName, user.ID)
+			}
+			if !tt.wantUser && (row.UserID != 0 || len(f.store.attendees) != 0) {
+				t.Errorf("row user = %d with %d attendees, want nobody registered", row.UserID, len(f.store.attendees))
+			}
+			if len(notifier.sent) != tt.wantNotices {
+				t.Errorf("%d emails sent, want %d", len(notifier.sent), tt.wantNotices)
+			}
+			if tt.wantResult == entities.ImportRowInvited && attendeeImport.Summary.InvitedToSignUp != 1 {
+				t.Errorf("summary = %+v, want 1 invited to sign up", attendeeImport.Summary)
+			}
+		})
+	}
+}
+
+func TestParseImportFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    []importedRow
+		wantErr bool
+	}{
+		{
+			name: "comma separated",
+			file: "email,first_name,last_name\nada@example.com,Ada,Lovelace\n",
+			want: []importedRow{{line: 2, email: "ada@example.com", firstName: "Ada", lastName: "Lovelace"}},
+		},
+		{
+			name: "semicolon separated",
+			file: "email;first_name;last_name\nada@example.com;Ada;Lovelace\n",
+			want: []importedRow{{line: 2, email: "ada@example.com", firstName: "Ada", lastName: "Lovelace"}},
+		},
+		{
+			name: "semicolons outnumber commas",
+			file: "last_name;first_name, initial;email\nLovelace;Ada, A.;ada@example.com\n",
+			want: []importedRow{{line: 2, email: "ada@example.com", lastName: "Lovelace"}},
+		},
+		{
+			name: "byte order mark",
+			file: "\ufeffEmail,Name\nada@example.com,Ada\n",
+			want: []importedRow{{line: 2, email: "ada@example.com", firstName: "Ada"}},
+		},
+		{
+			name: "name split at the first space",
+			file: "email,name\nada@example.com,  Ada  King Lovelace \n",
+			want: []importedRow{{line: 2, email: "ada@example.com", firstName: "Ada", lastName: "King Lovelace"}},
+		},
+		{
+			name: "first_name over name",
+			file: "email,first_name,name\nada@example.com,Ada,Grace Hopper\n",
+			want: []importedRow{{line: 2, email: "ada@example.com", firstName: "Ada"}},
+		},
+		{
+			name: "blank rows skipped",
+			file: "email\n\nada@example.com\n,\ngrace@example.com",
+			want: []importedRow{{line: 3, email: "ada@example.com"}, {line: 5, email: "grace@example.com"}},
+		},
+		{name: "empty file", file: "", wantErr: true},
+		{name: "no email column", file: "name\nAda\n", wantErr: true},
+		{name: "no rows", file: "email\n\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseImportFile(strings.NewReader(tt.file))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidImport) {
+					t.Errorf("error = %v, want ErrInvalidImport", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseImportFile: %v", err)
+			}
+			if !slices.Equal(rows, tt.want) {
+				t.Errorf("rows = %+v, want %+v", rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportAttendeesCountsSeats(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		dryRun   bool
+		emails   []string
+		want     []string
+	}{
+		{
+			name:     "within the quantity",
+			quantity: 3,
+			dryRun:   true,
+			emails:   []string{"new1@example.com", "new2@example.com"},
+			want:     []string{entities.ImportRowCreated, entities.ImportRowCreated},
+		},
+		{
+			name:     "over the quantity",
+			quantity: 2,
+			dryRun:   true,
+			emails:   []string{"new1@example.com", "new2@example.com"},
+			want:     []string{entities.ImportRowCreated, entities.ImportRowCapacityExceeded},
+		},
+		{
+			name:     "registered, rejected and repeated rows take no seat",
+			quantity: 2,
+			dryRun:   true,
+			emails:   []string{"confirmed@example.com", "rejected@example.com", "new1@example.com", "NEW1@example.com", "new2@example.com"},
+			want: []string{
+				entities.ImportRowAlreadyRegistered, entities.ImportRowRejected, entities.ImportRowCreated,
+				entities.ImportRowAlreadyRegistered, entities.ImportRowCapacityExceeded,
+			},
+		},
+		{
+			name:     "import",
+			quantity: 2,
+			emails:   []string{"confirmed@example.com", "rejected@example.com", "cancelled@example.com", "new1@example.com"},
+			want: []string{
+				entities.ImportRowAlreadyRegistered, entities.ImportRowRejected, entities.ImportRowCreated,
+				entities.ImportRowCapacityExceeded,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, _, uc := newImportFixture(t, tt.quantity, true, &recordingNotifier{})
+			registrations := map[string]uint{}
+			for _, status := range []string{entities.AttendeeStatusConfirmed, entities.AttendeeStatusRejected, entities.AttendeeStatusCancelled} {
+				attendee := &entities.Attendee{
+					EventID:      f.event.ID,
+					UserID:       f.newUser(t, status+"@example.com"),
+					Status:       status,
+					RSVP:         entities.RSVPGoing,
+					TicketTypeID: &f.ticketType.ID,
+				}
+				f.store.saveAttendee(attendee)
+				registrations[status] = attendee.ID
+			}
+
+			file := "email\n" + strings.Join(tt.emails, "\n")
+			attendeeImport, err := uc.ImportAttendees(context.Background(), f.event.UserID, f.event.ID, strings.NewReader(file), &entities.AttendeeImportRequest{
+				TicketTypeID: &f.ticketType.ID,
+				DryRun:       tt.dryRun,
+			})
+			if err != nil {
+				t.Fatalf("ImportAttendees: %v", err)
+			}
+			var results []string
+			for _, row := range attendeeImport.Rows {
+				results = append(results, row.Result)
+			}
+			if !slices.Equal(results, tt.want) {
+				t.Errorf("results = %q, want %q", results, tt.want)
+			}
+
+			wantCancelled := entities.AttendeeStatusCancelled
+			if !tt.dryRun {
+				wantCancelled = entities.AttendeeStatusConfirmed
+			}
+			f.assertAttendee(t, registrations[entities.AttendeeStatusRejected], entities.AttendeeStatusRejected)
+			f.assertAttendee(t, registrations[entities.AttendeeStatusCancelled], wantCancelled)
+			if tt.dryRun && len(f.store.attendees) != len(registrations) {
+				t.Errorf("%d attendees after a dry run, want %d", len(f.store.attendees), len(registrations))
+			}
+		})
+	}
+}
@@ -3,54 +3,717 @@ package usecases
 import (
 	"EventsAPI/internal/domain/entities"
 	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEventFull              = errors.New("no hay cupo disponible en el evento")
+	ErrAlreadyRegistered      = errors.New("el usuario ya está registrado")
+	ErrAttendeeNotFound       = errors.New("el usuario no está registrado en el evento")
+	ErrRegistrationRejected   = errors.New("la inscripción al evento fue rechazada")
+	ErrRegistrationNotPending = errors.New("la inscripción no está pendiente de aprobación")
+	ErrTooManyGuests          = errors.New("la inscripción supera el número de invitados permitido")
+	ErrRegistrationNotOpen    = errors.New("las inscripciones al evento aún no están abiertas")
+	ErrRegistrationClosed     = errors.New("las inscripciones al evento están cerradas")
+	ErrCancellationClosed     = errors.New("el plazo para cancelar la inscripción ha terminado")
+	ErrScheduleConflict       = errors.New("el usuario ya está inscrito en un evento que se solapa")
+	ErrPaymentRequired        = errors.New("el tipo de entrada es de pago y se compra con un pedido")
+	ErrPaymentPending         = errors.New("el usuario tiene un pedido pendiente de pago para el evento")
+	ErrPaidRegistration       = errors.New("la inscripción es de pago; para dejar las plazas hay que cancelarla")
 )
 
 type AttendeeUseCase struct {
 	attendeeRepo repositories.AttendeeRepository
 	eventRepo    repositories.EventRepository
+	authorizer   *EventAuthorizer
+	invitations  *EventInvitationUseCase
+	form         *RegistrationFormUseCase
+	tickets      *TicketTypeUseCase
+	refunds      *RefundUseCase
+	notifier     services.Notifier
 }
 
-func NewAttendeeUseCase(attendeeRepo repositories.AttendeeRepository, eventRepo repositories.EventRepository) *AttendeeUseCase {
-	return &AttendeeUseCase{attendeeRepo: attendeeRepo, eventRepo: eventRepo}
+func NewAttendeeUseCase(
+	attendeeRepo repositories.AttendeeRepository,
+	eventRepo repositories.EventRepository,
+	authorizer *EventAuthorizer,
+	invitations *EventInvitationUseCase,
+	form *RegistrationFormUseCase,
+	tickets *TicketTypeUseCase,
+	refunds *RefundUseCase,
+	notifier services.Notifier,
+) *AttendeeUseCase {
+	return &AttendeeUseCase{
+		attendeeRepo: attendeeRepo,
+		eventRepo:    eventRepo,
+		authorizer:   authorizer,
+		invitations:  invitations,
+		form:         form,
+		tickets:      tickets,
+		refunds:      refunds,
+		notifier:     notifier,
+	}
 }
 
-func (uc *AttendeeUseCase) RegisterForEvent(ctx context.Context, eventID, userID uint) error {
+// RegisterForEvent registers the user for the event. Private events require
+// an email invitation or a valid inviteToken, which is only spent when the
+// registration is saved. Events that require approval get a pending
+// registration that doesn't take up capacity until an organizer approves it.
+// A cancelled registration is reopened; a rejected one can't be. The answers
+// are validated against the event's registration form, and the user's guests
+// take up capacity like the user does. Only a "going" RSVP, the default, takes
+// up seats. Registration is only accepted within the event's registration
+// window. Events with ticket types require choosing one, and its quantity is
+// enforced together with the event's capacity; paid ticket types are bought
+// through an order instead. The user's other registrations that overlap the
+// event are returned as a warning, or refused with ErrScheduleConflict when
+// req.BlockOnConflict is set.
+func (uc *AttendeeUseCase) RegisterForEvent(ctx context.Context, eventID, userID uint, inviteToken string, req *entities.AttendeeRequest) (*entities.Attendee, []*entities.Event, error) {
+	registration, err := uc.register(ctx, eventID, userID, inviteToken, req, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return registration.attendee, registration.conflicts, nil
+}
+
+// registration is a saved registration with what was checked to make it.
+type registration struct {
+	attendee   *entities.Attendee
+	event      *entities.Event
+	ticketType *entities.TicketType
+	conflicts  []*entities.Event
+}
+
+// reserveForOrder registers the user like RegisterForEvent but for a paid
+// ticket type: the registration holds its seats awaiting payment of the
+// order.
+func (uc *AttendeeUseCase) reserveForOrder(ctx context.Context, eventID, userID uint, inviteToken string, req *entities.AttendeeRequest) (*registration, error) {
+	return uc.register(ctx, eventID, userID, inviteToken, req, true)
+}
+
+func (uc *AttendeeUseCase) register(ctx context.Context, eventID, userID uint, inviteToken string, req *entities.AttendeeRequest, reserve bool) (*registration, error) {
 	event, err := uc.eventRepo.GetByID(ctx, eventID)
 	if err != nil {
-		return errors.New("evento no existe")
+		return nil, ErrEventNotFound
 	}
 
-	count, err := uc.attendeeRepo.CountByEventID(ctx, eventID)
+	now := time.Now()
+	if event.RegistrationOpensAt != nil && now.Before(*event.RegistrationOpensAt) {
+		return nil, fmt.Errorf("%w: registration opens at %s", ErrRegistrationNotOpen, event.RegistrationOpensAt.Format(time.RFC3339))
+	}
+	if !now.Before(event.RegistrationClosesAtOrStart()) {
+		return nil, ErrRegistrationClosed
+	}
+
+	existing, err := uc.attendeeRepo.Get(ctx, eventID, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.IsActive() {
+			return nil, ErrAlreadyRegistered
+		}
+		if existing.Status == entities.AttendeeStatusAwaitingPayment {
+			return nil, ErrPaymentPending
+		}
+		if existing.Status == entities.AttendeeStatusRejected {
+			return nil, ErrRegistrationRejected
+		}
+	}
+
+	guestNames, err := validateGuests(event, req.GuestCount, req.GuestNames)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if int(count) >= event.MaxCapacity {
-		return errors.New("no hay cupo disponible en el evento")
+	ticketType, err := uc.tickets.SelectTicketType(ctx, event, req.TicketTypeID, req.UnlockCode, 1+req.GuestCount, now)
+	if err != nil {
+		return nil, err
+	}
+
+	rsvp := req.RSVP
+	if rsvp == "" {
+		rsvp = entities.RSVPGoing
+	}
+
+	status := entities.AttendeeStatusPending
+	if reserve {
+		switch {
+		case ticketType == nil || ticketType.IsFree():
+			return nil, fmt.Errorf("%w: free tickets don't need an order, register instead", ErrInvalidOrder)
+		case event.RequiresApproval:
+			return nil, fmt.Errorf("%w: events that require approval can't sell paid tickets", ErrInvalidOrder)
+		case rsvp != entities.RSVPGoing:
+			return nil, fmt.Errorf("%w: orders are for attendees that are going", ErrInvalidOrder)
+		}
+		status = entities.AttendeeStatusAwaitingPayment
+	} else if ticketType != nil && !ticketType.IsFree() {
+		return nil, fmt.Errorf("%w: %s", ErrPaymentRequired, ticketType.Name)
+	} else if req.PromoCode != "" {
+		return nil, fmt.Errorf("%w: promo codes only apply to paid tickets", ErrInvalidPromoCode)
+	}
+	if !event.RequiresApproval {
+		if rsvp == entities.RSVPGoing {
+			if err := uc.ensureSeats(ctx, event, ticketType, 1+req.GuestCount); err != nil {
+				return nil, err
+			}
+		}
+		if !reserve {
+			status = entities.AttendeeStatusConfirmed
+		}
 	}
 
-	exists, err := uc.attendeeRepo.IsUserRegistered(ctx, eventID, userID)
+	var conflicts []*entities.Event
+	if rsvp != entities.RSVPNotGoing {
+		overlapping, err := uc.attendeeRepo.GetOverlapping(ctx, userID, event)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range overlapping {
+			conflicts = append(conflicts, &other.Event)
+		}
+		if len(conflicts) > 0 && req.BlockOnConflict {
+			return nil, fmt.Errorf("%w: %s", ErrScheduleConflict, eventTitles(conflicts))
+		}
+	}
+
+	validAnswers, err := uc.form.ValidateAnswers(ctx, eventID, req.Answers)
+	if err != nil {
+		return nil, err
+	}
+
+	inviteLinkID, err := uc.invitations.AdmitToRegister(ctx, event, userID, inviteToken)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &registration{event: event, ticketType: ticketType, conflicts: conflicts}
+	if existing != nil {
+		existing.Status = status
+		existing.StatusMessage = ""
+		existing.RSVP = rsvp
+		existing.TicketTypeID = req.TicketTypeID
+		existing.GuestCount = req.GuestCount
+		existing.GuestNames = guestNames
+		existing.CheckedInAt = nil
+		existing.InviteLinkID = inviteLinkID
+		if err := uc.saveRegistration(ctx, event, ticketType, existing, now); err != nil {
+			return nil, err
+		}
+		if err := uc.form.ReplaceAnswers(ctx, existing.ID, validAnswers); err != nil {
+			return nil, err
+		}
+		result.attendee = existing
+		return result, nil
+	}
+
+	attendee := &entities.Attendee{
+		EventID:      eventID,
+		UserID:       userID,
+		Status:       status,
+		RSVP:         rsvp,
+		TicketTypeID: req.TicketTypeID,
+		GuestCount:   req.GuestCount,
+		GuestNames:   guestNames,
+		InviteLinkID: inviteLinkID,
+		Answers:      validAnswers,
+	}
+	if err := uc.saveRegistration(ctx, event, ticketType, attendee, now); err != nil {
+		return nil, err
+	}
+	result.attendee = attendee
+	return result, nil
+}
+
+// saveRegistration saves a new or reopened registration like
+// saveWithinCapacity, counting a use of the invite link that admitted it in
+// the same transaction so a registration that isn't saved doesn't spend it.
+func (uc *AttendeeUseCase) saveRegistration(ctx context.Context, event *entities.Event, ticketType *entities.TicketType, attendee *entities.Attendee, now time.Time) error {
+	check := func(eventSeats, ticketSeats int) error { return nil }
+	if attendee.IsGoing() {
+		check = capacityCheck(event, ticketType, attendee.Seats())
+	}
+	err := uc.attendeeRepo.Register(ctx, attendee, now, check)
+	if attendee.InviteLinkID != nil && errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInviteRequired
+	}
+	return err
+}
+
+// releaseReservation gives up the seats of a registration awaiting payment of
+// an order that couldn't be created, and the use of its invite link.
+func (uc *AttendeeUseCase) releaseReservation(ctx context.Context, attendee *entities.Attendee) error {
+	if err := uc.attendeeRepo.CancelReservation(ctx, attendee.ID); err != nil {
+		return err
+	}
+	attendee.Status = entities.AttendeeStatusCancelled
+	attendee.InviteLinkID = nil
+	return nil
+}
+
+// confirmLatePayment confirms a registration whose order was paid after its
+// reservation had been released, if its seats are still available.
+func (uc *AttendeeUseCase) confirmLatePayment(ctx context.Context, order *entities.Order) error {
+	attendee, err := uc.attendeeRepo.GetByID(ctx, order.AttendeeID)
 	if err != nil {
 		return err
 	}
-	if exists {
-		return errors.New("el usuario ya está registrado")
+	if attendee.Status != entities.AttendeeStatusCancelled {
+		return fmt.Errorf("%w: the registration changed after the order expired", ErrEventFull)
+	}
+
+	attendee.Status = entities.AttendeeStatusConfirmed
+	attendee.RSVP = entities.RSVPGoing
+	return uc.saveWithinCapacity(ctx, &order.Event, &order.TicketType, attendee)
+}
+
+// UpdateGuests changes the guests of the user's pending or confirmed
+// registration, within its ticket type's per-order limits. Adding guests to a
+// confirmed registration needs free seats, and can't be done with a paid
+// ticket type.
+func (uc *AttendeeUseCase) UpdateGuests(ctx context.Context, eventID, userID uint, req *entities.AttendeeGuestsRequest) (*entities.Attendee, error) {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, ErrEventNotFound
+	}
+
+	attendee, err := uc.activeRegistration(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	guestNames, err := validateGuests(event, req.GuestCount, req.GuestNames)
+	if err != nil {
+		return nil, err
+	}
+	ticketType, err := uc.ticketTypeOf(ctx, attendee)
+	if err != nil {
+		return nil, err
+	}
+	if ticketType != nil {
+		if err := checkTicketQuantity(ticketType, 1+req.GuestCount); err != nil {
+			return nil, err
+		}
 	}
 
-	attendee := &entities.Attendee{EventID: eventID, UserID: userID}
-	return uc.attendeeRepo.Create(ctx, attendee)
+	addsSeats := req.GuestCount > attendee.GuestCount
+	if addsSeats && ticketType != nil && !ticketType.IsFree() {
+		return nil, fmt.Errorf("%w: guests of paid tickets are bought with the order", ErrPaymentRequired)
+	}
+	attendee.GuestCount = req.GuestCount
+	attendee.GuestNames = guestNames
+	if !addsSeats {
+		if err := uc.attendeeRepo.Update(ctx, attendee); err != nil {
+			return nil, err
+		}
+		return attendee, nil
+	}
+	if err := uc.saveWithinCapacity(ctx, event, ticketType, attendee); err != nil {
+		return nil, err
+	}
+	return attendee, nil
 }
 
+// UpdateRSVP changes the user's RSVP. Switching a confirmed registration to
+// going needs free seats for the user and their guests. Paid registrations
+// can't give up their seats this way, since nothing would be refunded; they
+// are cancelled instead.
+func (uc *AttendeeUseCase) UpdateRSVP(ctx context.Context, eventID, userID uint, rsvp string) (*entities.Attendee, error) {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, ErrEventNotFound
+	}
+
+	attendee, err := uc.activeRegistration(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if attendee.RSVP == rsvp {
+		return attendee, nil
+	}
+
+	ticketType, err := uc.ticketTypeOf(ctx, attendee)
+	if err != nil {
+		return nil, err
+	}
+
+	attendee.RSVP = rsvp
+	if rsvp != entities.RSVPGoing {
+		if ticketType != nil && !ticketType.IsFree() {
+			return nil, ErrPaidRegistration
+		}
+		if err := uc.attendeeRepo.Update(ctx, attendee); err != nil {
+			return nil, err
+		}
+		return attendee, nil
+	}
+
+	if err := uc.saveWithinCapacity(ctx, event, ticketType, attendee); err != nil {
+		return nil, err
+	}
+	return attendee, nil
+}
+
+// UnregisterFromEvent cancels the user's pending or confirmed registration,
+// which is only allowed until the event's cancellation deadline. A paid
+// registration is refunded by the event's refund policy: the refund is
+// recorded with the cancellation, and a refund the provider then rejects is
+// kept as failed for an organizer to retry while the cancellation stands.
 func (uc *AttendeeUseCase) UnregisterFromEvent(ctx context.Context, eventID, userID uint) error {
-	return uc.attendeeRepo.Delete(ctx, eventID, userID)
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return ErrEventNotFound
+	}
+
+	attendee, err := uc.activeRegistration(ctx, eventID, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if now.After(event.CancellationDeadlineOrStart()) {
+		return ErrCancellationClosed
+	}
+
+	order, refund, check, err := uc.refunds.cancellationRefund(ctx, event, attendee, now)
+	if err != nil {
+		return err
+	}
+
+	attendee.Status = entities.AttendeeStatusCancelled
+	err = uc.attendeeRepo.Cancel(ctx, attendee, refund, check)
+	if errors.Is(err, errNothingToRefund) {
+		refund = nil
+		err = uc.attendeeRepo.Cancel(ctx, attendee, nil, nil)
+	}
+	if err != nil || refund == nil {
+		return err
+	}
+	_, err = uc.refunds.submit(ctx, order, refund)
+	return err
+}
+
+// DecideRegistrations approves or rejects the pending registrations of the
+// given users and notifies them, including message when it is set. Each user
+// is decided independently: the per-user outcome is reported instead of
+// failing the whole batch, e.g. when approving would exceed the capacity.
+func (uc *AttendeeUseCase) DecideRegistrations(ctx context.Context, userID, eventID uint, req *entities.AttendeeDecisionRequest, approve bool) ([]entities.AttendeeDecisionResponse, error) {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, ErrEventNotFound
+	}
+
+	if err := uc.authorizer.Authorize(ctx, event, userID, entities.PermissionManageAttendees); err != nil {
+		return nil, err
+	}
+
+	results := make([]entities.AttendeeDecisionResponse, 0, len(req.UserIDs))
+	for _, attendeeUserID := range req.UserIDs {
+		result := entities.AttendeeDecisionResponse{UserID: attendeeUserID}
+
+		attendee, err := uc.decideRegistration(ctx, event, attendeeUserID, approve, req.Message)
+		switch {
+		case err == nil:
+			result.Status = attendee.Status
+			// The decision stands even if the email can't be sent.
+			if err := uc.notifyDecision(ctx, event, attendee, approve, req.Message); err != nil {
+				result.Error = err.Error()
+			}
+		case errors.Is(err, ErrAttendeeNotFound), errors.Is(err, ErrRegistrationNotPending), errors.Is(err, ErrEventFull), errors.Is(err, ErrTicketSoldOut):
+			result.Error = err.Error()
+		default:
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (uc *AttendeeUseCase) decideRegistration(ctx context.Context, event *entities.Event, attendeeUserID uint, approve bool, message string) (*entities.Attendee, error) {
+	attendee, err := uc.attendeeRepo.Get(ctx, event.ID, attendeeUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttendeeNotFound
+		}
+		return nil, err
+	}
+	if attendee.Status != entities.AttendeeStatusPending {
+		return nil, ErrRegistrationNotPending
+	}
+
+	attendee.Status = entities.AttendeeStatusRejected
+	if approve {
+		attendee.Status = entities.AttendeeStatusConfirmed
+	}
+	attendee.StatusMessage = message
+
+	ticketType, err := uc.ticketTypeOf(ctx, attendee)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.saveWithinCapacity(ctx, event, ticketType, attendee); err != nil {
+		return nil, err
+	}
+	return attendee, nil
+}
+
+// notifyDecision emails the attendee whether their registration was approved,
+// with the organizer's message.
+func (uc *AttendeeUseCase) notifyDecision(ctx context.Context, event *entities.Event, attendee *entities.Attendee, approve bool, message string) error {
+	notification := services.Notification{
+		To:      attendee.User.Email,
+		Subject: fmt.Sprintf("Your registration for %s was approved", event.Title),
+		Body:    fmt.Sprintf("You are confirmed for %s on %s.", event.Title, event.DateTime.Format(time.RFC1123)),
+	}
+	if !approve {
+		notification.Subject = fmt.Sprintf("Your registration for %s was not approved", event.Title)
+		notification.Body = fmt.Sprintf("Your registration for %s was not approved.", event.Title)
+	}
+	if message != "" {
+		notification.Body += "\n\n" + message
+	}
+	return uc.notifier.Notify(ctx, notification)
 }
 
 func (uc *AttendeeUseCase) GetMyRegistrations(ctx context.Context, userID uint, limit, offset int) ([]*entities.Attendee, error) {
 	return uc.attendeeRepo.GetByUserID(ctx, userID, limit, offset)
 }
 
-func (uc *AttendeeUseCase) GetEventAttendees(ctx context.Context, eventID uint, limit, offset int) ([]*entities.Attendee, error) {
-	return uc.attendeeRepo.GetByEventID(ctx, eventID, limit, offset)
+// GetEventAttendees lists the attendees of an event according to its attendee
+// visibility. Organizers always get every registration, optionally filtered by
+// status, and full is true; other users get the confirmed attendees that
+// didn't hide themselves and must only be shown the redacted projection.
+func (uc *AttendeeUseCase) GetEventAttendees(ctx context.Context, userID, eventID uint, status string, limit, offset int) (attendees []*entities.Attendee, full bool, err error) {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, false, ErrEventNotFound
+	}
+
+	isOrganizer, err := uc.authorizer.Can(ctx, event, userID, entities.PermissionViewAttendees)
+	if err != nil {
+		return nil, false, err
+	}
+	if isOrganizer {
+		attendees, err = uc.attendeeRepo.GetByEventID(ctx, eventID, status, limit, offset)
+		return attendees, true, err
+	}
+
+	canView, err := uc.invitations.CanView(ctx, event, userID, "")
+	if err != nil {
+		return nil, false, err
+	}
+	if !canView {
+		return nil, false, ErrEventNotFound
+	}
+
+	switch event.AttendeeVisibility {
+	case entities.AttendeeVisibilityPublic:
+	case entities.AttendeeVisibilityAttendees:
+		registered, err := uc.attendeeRepo.IsUserRegistered(ctx, eventID, userID)
+		if err != nil {
+			return nil, false, err
+		}
+		if !registered {
+			return nil, false, ErrForbidden
+		}
+	default:
+		return nil, false, ErrForbidden
+	}
+
+	attendees, err = uc.attendeeRepo.GetVisibleByEventID(ctx, eventID, userID, limit, offset)
+	return attendees, false, err
+}
+
+// CheckIn marks attendeeUserID as present at the event.
+func (uc *AttendeeUseCase) CheckIn(ctx context.Context, userID, eventID, attendeeUserID uint) error {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return ErrEventNotFound
+	}
+
+	if err := uc.authorizer.Authorize(ctx, event, userID, entities.PermissionCheckInAttendees); err != nil {
+		return err
+	}
+
+	err = uc.attendeeRepo.CheckIn(ctx, eventID, attendeeUserID, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAttendeeNotFound
+	}
+	return err
+}
+
+// MessageAttendees notifies the confirmed attendees with the given RSVP. A
+// failed email doesn't stop the others: the result counts the attendees that
+// were messaged and reports the ones that weren't.
+func (uc *AttendeeUseCase) MessageAttendees(ctx context.Context, userID, eventID uint, req *entities.AttendeeMessageRequest) (*entities.AttendeeMessageResponse, error) {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, ErrEventNotFound
+	}
+
+	if err := uc.authorizer.Authorize(ctx, event, userID, entities.PermissionManageAttendees); err != nil {
+		return nil, err
+	}
+
+	attendees, err := uc.attendeeRepo.GetConfirmedByRSVP(ctx, eventID, req.RSVP)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entities.AttendeeMessageResponse{}
+	for _, attendee := range attendees {
+		err := uc.notifier.Notify(ctx, services.Notification{
+			To:      attendee.User.Email,
+			Subject: fmt.Sprintf("[%s] %s", event.Title, req.Subject),
+			Body:    req.Body,
+		})
+		if err != nil {
+			result.Failures = append(result.Failures, entities.AttendeeMessageFailure{UserID: attendee.UserID, Error: err.Error()})
+			continue
+		}
+		result.Recipients++
+	}
+	return result, nil
+}
+
+// GetConflicts returns the pairs of the user's upcoming registrations whose
+// events overlap.
+func (uc *AttendeeUseCase) GetConflicts(ctx context.Context, userID uint) ([]entities.ScheduleConflict, error) {
+	attendees, err := uc.attendeeRepo.GetUpcomingByUserID(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// The registrations are ordered by start time, so the scan can stop at the
+	// first later event that starts after the current one ends.
+	var conflicts []entities.ScheduleConflict
+	for i := range attendees {
+		event := &attendees[i].Event
+		for j := i + 1; j < len(attendees); j++ {
+			other := &attendees[j].Event
+			if !event.Overlaps(other) {
+				if other.DateTime.After(event.DateTime) {
+					break
+				}
+				continue
+			}
+			conflicts = append(conflicts, entities.ScheduleConflict{Event: event, ConflictsWith: other})
+		}
+	}
+	return conflicts, nil
+}
+
+// activeRegistration returns the user's pending or confirmed registration.
+func (uc *AttendeeUseCase) activeRegistration(ctx context.Context, eventID, userID uint) (*entities.Attendee, error) {
+	attendee, err := uc.attendeeRepo.Get(ctx, eventID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttendeeNotFound
+		}
+		return nil, err
+	}
+	if !attendee.IsActive() {
+		return nil, ErrAttendeeNotFound
+	}
+	return attendee, nil
+}
+
+// eventTitles lists the titles of events for error messages.
+func eventTitles(events []*entities.Event) string {
+	titles := make([]string, len(events))
+	for i, event := range events {
+		titles[i] = fmt.Sprintf("%q", event.Title)
+	}
+	return strings.Join(titles, ", ")
+}
+
+// ensureSeats fails early when the event or the ticket type doesn't have the
+// given number of free seats. Only going registrations take seats, one for
+// the user and one per guest; pending registrations take none until they are
+// approved. saveWithinCapacity checks again atomically.
+func (uc *AttendeeUseCase) ensureSeats(ctx context.Context, event *entities.Event, ticketType *entities.TicketType, seats int) error {
+	eventSeats, err := uc.attendeeRepo.CountSeatsByEventID(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	var ticketSeats int
+	if ticketType != nil {
+		taken, err := uc.attendeeRepo.CountSeatsByTicketType(ctx, event.ID)
+		if err != nil {
+			return err
+		}
+		ticketSeats = taken[ticketType.ID]
+	}
+	return capacityCheck(event, ticketType, seats)(eventSeats, ticketSeats)
+}
+
+// saveWithinCapacity saves the registration, making sure that when it takes
+// up seats they fit the event's capacity and its ticket type's quantity even
+// with concurrent registrations.
+func (uc *AttendeeUseCase) saveWithinCapacity(ctx context.Context, event *entities.Event, ticketType *entities.TicketType, attendee *entities.Attendee) error {
+	if !attendee.IsGoing() {
+		if attendee.ID == 0 {
+			return uc.attendeeRepo.Create(ctx, attendee)
+		}
+		return uc.attendeeRepo.Update(ctx, attendee)
+	}
+	return uc.attendeeRepo.SaveWithinCapacity(ctx, attendee, capacityCheck(event, ticketType, attendee.Seats()))
+}
+
+// capacityCheck returns ErrEventFull when seats don't fit the event's overall
+// capacity and ErrTicketSoldOut when they don't fit the ticket type. Events
+// without an overall capacity are limited by their ticket types only, so they
+// have no seats without one.
+func capacityCheck(event *entities.Event, ticketType *entities.TicketType, seats int) func(eventSeats, ticketSeats int) error {
+	return func(eventSeats, ticketSeats int) error {
+		if event.MaxCapacity > 0 && eventSeats+seats > event.MaxCapacity {
+			return ErrEventFull
+		}
+		if ticketType == nil {
+			if event.MaxCapacity == 0 {
+				return ErrEventFull
+			}
+			return nil
+		}
+		if ticketSeats+seats > ticketType.Quantity {
+			return fmt.Errorf("%w: %s", ErrTicketSoldOut, ticketType.Name)
+		}
+		return nil
+	}
+}
+
+// ticketTypeOf returns the registration's ticket type, nil when it has none.
+func (uc *AttendeeUseCase) ticketTypeOf(ctx context.Context, attendee *entities.Attendee) (*entities.TicketType, error) {
+	if attendee.TicketTypeID == nil {
+		return nil, nil
+	}
+	return uc.tickets.GetTicketType(ctx, *attendee.TicketTypeID)
+}
+
+// validateGuests checks the party against the event's guest limit, none by
+// default, and returns the trimmed guest names. Guests take up seats like the
+// user does.
+func validateGuests(event *entities.Event, guestCount int, names []string) ([]string, error) {
+	if guestCount > event.MaxGuests {
+		return nil, ErrTooManyGuests
+	}
+
+	guestNames := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			guestNames = append(guestNames, name)
+		}
+	}
+	if len(guestNames) > guestCount {
+		return nil, ErrTooManyGuests
+	}
+	return guestNames, nil
 }
@@ -0,0 +1,81 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/services"
+)
+
+func TestUnregisterRefundsPaidRegistration(t *testing.T) {
+	tests := []struct {
+		name          string
+		refundErr     error
+		saveErr       error
+		wantErr       bool
+		wantAttendee  string
+		wantRefund    string
+		wantRefundErr string
+	}{
+		{
+			name:         "refunded",
+			wantAttendee: entities.AttendeeStatusCancelled,
+			wantRefund:   entities.RefundStatusSucceeded,
+		},
+		{
+			name:          "provider fails",
+			refundErr:     errors.New("provider unavailable"),
+			wantAttendee:  entities.AttendeeStatusCancelled,
+			wantRefund:    entities.RefundStatusFailed,
+			wantRefundErr: "provider unavailable",
+		},
+		{
+			name:         "refund not saved",
+			saveErr:      errors.New("connection lost"),
+			wantErr:      true,
+			wantAttendee: entities.AttendeeStatusConfirmed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOrderFixture(t)
+			buyer := f.newUser(t, "buyer@example.com")
+			order, err := f.checkout(buyer, "")
+			if err != nil {
+				t.Fatalf("Checkout: %v", err)
+			}
+			if err := f.pay(order.PaymentID, "", services.PaymentSucceeded, order.Amount); err != nil {
+				t.Fatalf("payment: %v", err)
+			}
+			f.provider.refundErr = tt.refundErr
+			f.store.failRefunds = tt.saveErr
+
+			err = f.attendees.UnregisterFromEvent(context.Background(), f.event.ID, buyer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnregisterFromEvent error = %v, want error %v", err, tt.wantErr)
+			}
+			f.assertAttendee(t, order.AttendeeID, tt.wantAttendee)
+
+			saved, _ := f.store.order(order.ID)
+			if tt.wantRefund == "" {
+				if len(saved.Refunds) != 0 {
+					t.Errorf("%d refunds, want none", len(saved.Refunds))
+				}
+				return
+			}
+			if len(saved.Refunds) != 1 {
+				t.Fatalf("%d refunds, want 1", len(saved.Refunds))
+			}
+			refund := saved.Refunds[0]
+			if refund.Status != tt.wantRefund || refund.Amount != order.Amount || refund.Reason != entities.RefundReasonAttendeeCancelled {
+				t.Errorf("refund = %q %d %q, want %q %d %q", refund.Status, refund.Amount, refund.Reason, tt.wantRefund, order.Amount, entities.RefundReasonAttendeeCancelled)
+			}
+			if refund.FailureMessage != tt.wantRefundErr {
+				t.Errorf("refund failure = %q, want %q", refund.FailureMessage, tt.wantRefundErr)
+			}
+		})
+	}
+}
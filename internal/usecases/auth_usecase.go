@@ -3,9 +3,7 @@ package usecases
 import (
 	"context"
 	"errors"
-	"time"
 
-	"EventsAPI/internal/config"
 	"EventsAPI/internal/domain/entities"
 	"EventsAPI/internal/domain/repositories"
 	"EventsAPI/pkg/utils"
@@ -14,14 +12,14 @@ import (
 )
 
 type AuthUseCase struct {
-	userRepo repositories.UserRepository
-	config   *config.Config
+	userRepo   repositories.UserRepository
+	jwtManager *utils.JWTManager
 }
 
-func NewAuthUseCase(userRepo repositories.UserRepository, config *config.Config) *AuthUseCase {
+func NewAuthUseCase(userRepo repositories.UserRepository, jwtManager *utils.JWTManager) *AuthUseCase {
 	return &AuthUseCase{
-		userRepo: userRepo,
-		config:   config,
+		userRepo:   userRepo,
+		jwtManager: jwtManager,
 	}
 }
 
@@ -78,8 +76,7 @@ func (uc *AuthUseCase) Login(ctx context.Context, req *entities.LoginRequest) (s
 	}
 
 	// Generate JWT
-	expiration, _ := time.ParseDuration(uc.config.JWT.Expiration)
-	token, err := utils.GenerateJWT(user.ID, user.Email, uc.config.JWT.Secret, expiration)
+	token, err := uc.jwtManager.GenerateJWT(user.ID, user.Email)
 	if err != nil {
 		return "", nil, err
 	}
@@ -89,8 +86,14 @@ func (uc *AuthUseCase) Login(ctx context.Context, req *entities.LoginRequest) (s
 		Email:     user.Email,
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
+		IsAdmin:   user.IsAdmin,
 		CreatedAt: user.CreatedAt,
 	}
 
 	return token, userResponse, nil
 }
+
+// JWKS returns the public keys used to verify the API tokens.
+func (uc *AuthUseCase) JWKS() (*utils.JWKSet, error) {
+	return uc.jwtManager.JWKS()
+}
@@ -0,0 +1,272 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryExists   = errors.New("a category with this slug already exists")
+	ErrCategoryInUse    = errors.New("category has subcategories or events")
+	ErrInvalidCategory  = errors.New("invalid category")
+)
+
+// CategoryUseCase manages the event category taxonomy. Only site
+// administrators may change it.
+type CategoryUseCase struct {
+	categoryRepo repositories.CategoryRepository
+	userRepo     repositories.UserRepository
+}
+
+func NewCategoryUseCase(categoryRepo repositories.CategoryRepository, userRepo repositories.UserRepository) *CategoryUseCase {
+	return &CategoryUseCase{categoryRepo: categoryRepo, userRepo: userRepo}
+}
+
+// ListCategories returns the root categories with their subcategories nested
+// in Children.
+func (uc *CategoryUseCase) ListCategories(ctx context.Context) ([]*entities.Category, error) {
+	categories, err := uc.categoryRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*entities.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	roots := []*entities.Category{}
+	for _, category := range categories {
+		if parent, ok := byID[parentOf(category)]; ok {
+			parent.Children = append(parent.Children, category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+	return roots, nil
+}
+
+func (uc *CategoryUseCase) GetCategory(ctx context.Context, id uint) (*entities.Category, error) {
+	category, err := uc.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+func (uc *CategoryUseCase) CreateCategory(ctx context.Context, userID uint, req *entities.CategoryRequest) (*entities.Category, error) {
+	if err := uc.authorizeAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	category := &entities.Category{}
+	if err := uc.applyCategoryRequest(ctx, category, req); err != nil {
+		return nil, err
+	}
+	if err := uc.categoryRepo.Create(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (uc *CategoryUseCase) UpdateCategory(ctx context.Context, userID, id uint, req *entities.CategoryRequest) (*entities.Category, error) {
+	if err := uc.authorizeAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	category, err := uc.GetCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.applyCategoryRequest(ctx, category, req); err != nil {
+		return nil, err
+	}
+	if err := uc.categoryRepo.Update(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory removes a category without subcategories or events.
+func (uc *CategoryUseCase) DeleteCategory(ctx context.Context, userID, id uint) error {
+	if err := uc.authorizeAdmin(ctx, userID); err != nil {
+		return err
+	}
+
+	if _, err := uc.GetCategory(ctx, id); err != nil {
+		return err
+	}
+	categories, err := uc.categoryRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, category := range categories {
+		if parentOf(category) == id {
+			return ErrCategoryInUse
+		}
+	}
+	events, err := uc.categoryRepo.CountEvents(ctx, id)
+	if err != nil {
+		return err
+	}
+	if events > 0 {
+		return ErrCategoryInUse
+	}
+	return uc.categoryRepo.Delete(ctx, id)
+}
+
+// Facets turns the event counts of each category into facets where every
+// category also counts its subcategories' events. Categories without events
+// are left out.
+func (uc *CategoryUseCase) Facets(ctx context.Context, counts map[uint]int) ([]entities.CategoryFacet, error) {
+	categories, err := uc.categoryRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*entities.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	totals := make(map[uint]int, len(categories))
+	for id, count := range counts {
+		// seen guards against a parent cycle left in the table.
+		seen := map[uint]bool{}
+		for category, ok := byID[id]; ok && !seen[category.ID]; category, ok = byID[parentOf(category)] {
+			seen[category.ID] = true
+			totals[category.ID] += count
+		}
+	}
+
+	facets := []entities.CategoryFacet{}
+	for _, category := range categories {
+		if totals[category.ID] == 0 {
+			continue
+		}
+		facets = append(facets, entities.CategoryFacet{
+			ID:       category.ID,
+			Name:     category.Name,
+			Slug:     category.Slug,
+			ParentID: category.ParentID,
+			Count:    totals[category.ID],
+		})
+	}
+	return facets, nil
+}
+
+func (uc *CategoryUseCase) authorizeAdmin(ctx context.Context, userID uint) error {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrForbidden
+		}
+		return err
+	}
+	if !user.IsAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// applyCategoryRequest copies req to the category, generating the slug from
+// the name when it is missing. The slug must be unique and the parent must
+// exist and not be the category itself or one of its subcategories.
+func (uc *CategoryUseCase) applyCategoryRequest(ctx context.Context, category *entities.Category, req *entities.CategoryRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCategory)
+	}
+	slug := slugify(req.Slug)
+	if slug == "" {
+		slug = slugify(name)
+	}
+	if slug == "" {
+		return fmt.Errorf("%w: slug must contain letters or digits", ErrInvalidCategory)
+	}
+
+	existing, err := uc.categoryRepo.GetBySlug(ctx, slug)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && existing.ID != category.ID {
+		return fmt.Errorf("%w: %s", ErrCategoryExists, slug)
+	}
+
+	if req.ParentID != nil {
+		if err := uc.checkParent(ctx, category.ID, *req.ParentID); err != nil {
+			return err
+		}
+	}
+
+	category.Name = name
+	category.Slug = slug
+	category.ParentID = req.ParentID
+	category.Position = req.Position
+	return nil
+}
+
+// checkParent rejects a parent that doesn't exist or would make a cycle.
+// id is zero for new categories.
+func (uc *CategoryUseCase) checkParent(ctx context.Context, id, parentID uint) error {
+	categories, err := uc.categoryRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]*entities.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	if _, ok := byID[parentID]; !ok {
+		return fmt.Errorf("%w: parent %d", ErrCategoryNotFound, parentID)
+	}
+	seen := map[uint]bool{}
+	for ancestor, ok := byID[parentID]; ok && !seen[ancestor.ID]; ancestor, ok = byID[parentOf(ancestor)] {
+		if ancestor.ID == id {
+			return fmt.Errorf("%w: a category can't be its own ancestor", ErrInvalidCategory)
+		}
+		seen[ancestor.ID] = true
+	}
+	return nil
+}
+
+// parentOf returns the category's parent ID, zero for root categories.
+func parentOf(category *entities.Category) uint {
+	if category.ParentID == nil {
+		return 0
+	}
+	return *category.ParentID
+}
+
+// slugify lowercases s, drops accents and joins its words with hyphens.
+func slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		default:
+			hyphen = true
+		}
+	}
+	return b.String()
+}
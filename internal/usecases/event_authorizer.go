@@ -0,0 +1,82 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+var ErrForbidden = errors.New("you don't have permission to perform this action")
+
+// EventAuthorizer decides what a user may do with an event. Personal events
+// are managed by their creator; organization events by the organization
+// members whose role grants the permission. On top of that, per-event
+// collaborator grants (co-organizer, staff) apply to both kinds of event.
+type EventAuthorizer struct {
+	organizationRepo repositories.OrganizationRepository
+	collaboratorRepo repositories.EventCollaboratorRepository
+}
+
+func NewEventAuthorizer(organizationRepo repositories.OrganizationRepository, collaboratorRepo repositories.EventCollaboratorRepository) *EventAuthorizer {
+	return &EventAuthorizer{organizationRepo: organizationRepo, collaboratorRepo: collaboratorRepo}
+}
+
+func (a *EventAuthorizer) Can(ctx context.Context, event *entities.Event, userID uint, permission entities.Permission) (bool, error) {
+	if event.OrganizationID == nil {
+		if event.UserID == userID {
+			return true, nil
+		}
+	} else {
+		allowed, err := a.CanInOrganization(ctx, *event.OrganizationID, userID, permission)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+
+	collaborator, err := a.collaboratorRepo.Get(ctx, event.ID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return entities.EventRoleHasPermission(collaborator.Role, permission), nil
+}
+
+func (a *EventAuthorizer) CanInOrganization(ctx context.Context, organizationID, userID uint, permission entities.Permission) (bool, error) {
+	member, err := a.organizationRepo.GetMember(ctx, organizationID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return entities.OrganizationRoleHasPermission(member.Role, permission), nil
+}
+
+// Authorize returns ErrForbidden when the user lacks the permission.
+func (a *EventAuthorizer) Authorize(ctx context.Context, event *entities.Event, userID uint, permission entities.Permission) error {
+	allowed, err := a.Can(ctx, event, userID, permission)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (a *EventAuthorizer) AuthorizeInOrganization(ctx context.Context, organizationID, userID uint, permission entities.Permission) error {
+	allowed, err := a.CanInOrganization(ctx, organizationID, userID, permission)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound         = errors.New("user not found")
+	ErrCollaboratorNotFound = errors.New("collaborator not found")
+	ErrCollaboratorIsOwner  = errors.New("the event owner can't be added as a collaborator")
+)
+
+type EventCollaboratorUseCase struct {
+	collaboratorRepo repositories.EventCollaboratorRepository
+	eventRepo        repositories.EventRepository
+	userRepo         repositories.UserRepository
+	authorizer       *EventAuthorizer
+	notifier         services.Notifier
+}
+
+func NewEventCollaboratorUseCase(
+	collaboratorRepo repositories.EventCollaboratorRepository,
+	eventRepo repositories.EventRepository,
+	userRepo repositories.UserRepository,
+	authorizer *EventAuthorizer,
+	notifier services.Notifier,
+) *EventCollaboratorUseCase {
+	return &EventCollaboratorUseCase{
+		collaboratorRepo: collaboratorRepo,
+		eventRepo:        eventRepo,
+		userRepo:         userRepo,
+		authorizer:       authorizer,
+		notifier:         notifier,
+	}
+}
+
+// AddCollaborator grants the user with the given email a role on the event,
+// replacing any role they already had, and notifies them.
+func (uc *EventCollaboratorUseCase) AddCollaborator(ctx context.Context, userID, eventID uint, req *entities.EventCollaboratorRequest) (*entities.EventCollaboratorResponse, error) {
+	event, err := uc.authorizedEvent(ctx, userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	collaboratorUser, err := uc.userRepo.GetByEmail(ctx, strings.ToLower(req.Email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if event.OrganizationID == nil && collaboratorUser.ID == event.UserID {
+		return nil, ErrCollaboratorIsOwner
+	}
+
+	collaborator := &entities.EventCollaborator{
+		EventID:     eventID,
+		UserID:      collaboratorUser.ID,
+		Role:        req.Role,
+		InvitedByID: userID,
+	}
+	if err := uc.collaboratorRepo.Upsert(ctx, collaborator); err != nil {
+		return nil, err
+	}
+
+	err = uc.notifier.Notify(ctx, services.Notification{
+		To:      collaboratorUser.Email,
+		Subject: fmt.Sprintf("You are now helping run %s", event.Title),
+		Body:    fmt.Sprintf("You have been added to %s as %s.", event.Title, strings.ReplaceAll(req.Role, "_", "-")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.EventCollaboratorResponse{
+		UserID:    collaboratorUser.ID,
+		Email:     collaboratorUser.Email,
+		FirstName: collaboratorUser.FirstName,
+		LastName:  collaboratorUser.LastName,
+		Role:      collaborator.Role,
+		CreatedAt: collaborator.CreatedAt,
+	}, nil
+}
+
+func (uc *EventCollaboratorUseCase) ListCollaborators(ctx context.Context, userID, eventID uint) ([]entities.EventCollaboratorResponse, error) {
+	if _, err := uc.authorizedEvent(ctx, userID, eventID); err != nil {
+		return nil, err
+	}
+
+	collaborators, err := uc.collaboratorRepo.GetByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entities.EventCollaboratorResponse, 0, len(collaborators))
+	for _, collaborator := range collaborators {
+		response = append(response, entities.EventCollaboratorResponse{
+			UserID:    collaborator.UserID,
+			Email:     collaborator.User.Email,
+			FirstName: collaborator.User.FirstName,
+			LastName:  collaborator.User.LastName,
+			Role:      collaborator.Role,
+			CreatedAt: collaborator.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// RemoveCollaborator revokes a grant. Collaborators may also remove
+// themselves.
+func (uc *EventCollaboratorUseCase) RemoveCollaborator(ctx context.Context, userID, eventID, collaboratorUserID uint) error {
+	if userID != collaboratorUserID {
+		if _, err := uc.authorizedEvent(ctx, userID, eventID); err != nil {
+			return err
+		}
+	}
+
+	err := uc.collaboratorRepo.Delete(ctx, eventID, collaboratorUserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCollaboratorNotFound
+	}
+	return err
+}
+
+func (uc *EventCollaboratorUseCase) authorizedEvent(ctx context.Context, userID, eventID uint) (*entities.Event, error) {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+
+	if err := uc.authorizer.Authorize(ctx, event, userID, entities.PermissionManageEventTeam); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
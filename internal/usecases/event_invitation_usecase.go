@@ -0,0 +1,409 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
+	"EventsAPI/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInviteRequired          = errors.New("this event is invite-only")
+	ErrInviteLinkNotFound      = errors.New("invite link not found")
+	ErrInviteLinkExpiryPast    = errors.New("the invite link expiry must be in the future")
+	ErrEventInvitationNotFound = errors.New("invitation not found")
+	ErrEventNotPrivate         = errors.New("only private events take invitations")
+	ErrEventInvitationAnswered = errors.New("the invitation was already answered")
+)
+
+// eventInviteAudience keeps invite tokens from being accepted as access tokens
+// and the other way around.
+const eventInviteAudience = "event_invite"
+
+// eventInviteClaims identifies an invite link. The claims are deterministic, so
+// a link always produces the same token and it can be shown again later.
+type eventInviteClaims struct {
+	EventID uint `json:"event_id"`
+	jwt.RegisteredClaims
+}
+
+// EventInvitationUseCase manages who may see and join private events: signed
+// invite links that can be shared, and invitations sent to an email address.
+type EventInvitationUseCase struct {
+	linkRepo       repositories.EventInviteLinkRepository
+	invitationRepo repositories.EventInvitationRepository
+	eventRepo      repositories.EventRepository
+	userRepo       repositories.UserRepository
+	attendeeRepo   repositories.AttendeeRepository
+	authorizer     *EventAuthorizer
+	jwtManager     *utils.JWTManager
+	notifier       services.Notifier
+}
+
+func NewEventInvitationUseCase(
+	linkRepo repositories.EventInviteLinkRepository,
+	invitationRepo repositories.EventInvitationRepository,
+	eventRepo repositories.EventRepository,
+	userRepo repositories.UserRepository,
+	attendeeRepo repositories.AttendeeRepository,
+	authorizer *EventAuthorizer,
+	jwtManager *utils.JWTManager,
+	notifier services.Notifier,
+) *EventInvitationUseCase {
+	return &EventInvitationUseCase{
+		linkRepo:       linkRepo,
+		invitationRepo: invitationRepo,
+		eventRepo:      eventRepo,
+		userRepo:       userRepo,
+		attendeeRepo:   attendeeRepo,
+		authorizer:     authorizer,
+		jwtManager:     jwtManager,
+		notifier:       notifier,
+	}
+}
+
+func (uc *EventInvitationUseCase) CreateInviteLink(ctx context.Context, userID, eventID uint, req *entities.EventInviteLinkRequest) (*entities.EventInviteLinkResponse, error) {
+	if _, err := uc.authorizedEvent(ctx, userID, eventID); err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInviteLinkExpiryPast
+	}
+
+	link := &entities.EventInviteLink{
+		EventID:     eventID,
+		CreatedByID: userID,
+		MaxUses:     req.MaxUses,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := uc.linkRepo.Create(ctx, link); err != nil {
+		return nil, err
+	}
+
+	return uc.newInviteLinkResponse(link)
+}
+
+func (uc *EventInvitationUseCase) ListInviteLinks(ctx context.Context, userID, eventID uint) ([]entities.EventInviteLinkResponse, error) {
+	if _, err := uc.authorizedEvent(ctx, userID, eventID); err != nil {
+		return nil, err
+	}
+
+	links, err := uc.linkRepo.GetByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entities.EventInviteLinkResponse, 0, len(links))
+	for _, link := range links {
+		linkResponse, err := uc.newInviteLinkResponse(link)
+		if err != nil {
+			return nil, err
+		}
+		response = append(response, *linkResponse)
+	}
+	return response, nil
+}
+
+func (uc *EventInvitationUseCase) RevokeInviteLink(ctx context.Context, userID, eventID, linkID uint) error {
+	if _, err := uc.authorizedEvent(ctx, userID, eventID); err != nil {
+		return err
+	}
+
+	err := uc.linkRepo.Revoke(ctx, eventID, linkID, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInviteLinkNotFound
+	}
+	return err
+}
+
+// Invite sends an invitation to an email address. Inviting the same address
+// again resets a declined invitation to pending.
+func (uc *EventInvitationUseCase) Invite(ctx context.Context, userID, eventID uint, req *entities.EventInvitationRequest) (*entities.EventInvitationResponse, error) {
+	event, err := uc.authorizedEvent(ctx, userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Visibility != entities.EventVisibilityPrivate {
+		return nil, ErrEventNotPrivate
+	}
+
+	invitation := &entities.EventInvitation{
+		EventID:     eventID,
+		Email:       strings.ToLower(req.Email),
+		Status:      entities.EventInvitationPending,
+		InvitedByID: userID,
+	}
+	if err := uc.invitationRepo.Upsert(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	err = uc.notifier.Notify(ctx, services.Notification{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You are invited to %s", event.Title),
+		Body:    fmt.Sprintf("You have been invited to %s on %s. Accept or decline invitation %d from your invitations.", event.Title, event.DateTime.Format(time.RFC1123), invitation.ID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invitation.Event = *event
+	response := newEventInvitationResponse(invitation)
+	return &response, nil
+}
+
+func (uc *EventInvitationUseCase) ListInvitations(ctx context.Context, userID, eventID uint) ([]entities.EventInvitationResponse, error) {
+	if _, err := uc.authorizedEvent(ctx, userID, eventID); err != nil {
+		return nil, err
+	}
+
+	invitations, err := uc.invitationRepo.GetByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return newEventInvitationResponses(invitations), nil
+}
+
+// ListMyInvitations returns the invitations sent to the user's email address.
+func (uc *EventInvitationUseCase) ListMyInvitations(ctx context.Context, userID uint) ([]entities.EventInvitationResponse, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := uc.invitationRepo.GetByEmail(ctx, strings.ToLower(user.Email))
+	if err != nil {
+		return nil, err
+	}
+	return newEventInvitationResponses(invitations), nil
+}
+
+// RespondToInvitation accepts or declines an invitation sent to the user's
+// email address. Accepting doesn't register the user; it lets them register.
+func (uc *EventInvitationUseCase) RespondToInvitation(ctx context.Context, userID, invitationID uint, accept bool) (*entities.EventInvitationResponse, error) {
+	invitation, err := uc.invitationRepo.GetByID(ctx, invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventInvitationNotFound
+		}
+		return nil, err
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrEventInvitationNotFound
+	}
+	if invitation.Status != entities.EventInvitationPending {
+		return nil, ErrEventInvitationAnswered
+	}
+
+	status := entities.EventInvitationDeclined
+	if accept {
+		status = entities.EventInvitationAccepted
+	}
+	now := time.Now()
+	if err := uc.invitationRepo.UpdateStatus(ctx, invitation.ID, status, now); err != nil {
+		return nil, err
+	}
+
+	invitation.Status = status
+	invitation.RespondedAt = &now
+	response := newEventInvitationResponse(invitation)
+	return &response, nil
+}
+
+// CanView reports whether the user may see the event. Public and unlisted
+// events are visible to everyone; private events to their team, their
+// attendees, the users invited by email and the holders of a valid invite
+// token.
+func (uc *EventInvitationUseCase) CanView(ctx context.Context, event *entities.Event, userID uint, inviteToken string) (bool, error) {
+	if event.Visibility != entities.EventVisibilityPrivate {
+		return true, nil
+	}
+
+	allowed, err := uc.isInvited(ctx, event, userID, false)
+	if err != nil || allowed {
+		return allowed, err
+	}
+
+	registered, err := uc.attendeeRepo.IsUserRegistered(ctx, event.ID, userID)
+	if err != nil || registered {
+		return registered, err
+	}
+
+	if inviteToken == "" {
+		return false, nil
+	}
+	link, err := uc.inviteLink(ctx, event.ID, inviteToken)
+	if err != nil {
+		if errors.Is(err, ErrInviteRequired) {
+			return false, nil
+		}
+		return false, err
+	}
+	return link.IsUsable(time.Now()), nil
+}
+
+// AdmitToRegister checks that the user may register for the event, returning
+// ErrInviteRequired for private events the user wasn't invited to. A pending
+// email invitation is accepted on the way. A user admitted by an invite token
+// gets the ID of its link, which must still be usable; the registration
+// counts a use of it when it is saved.
+func (uc *EventInvitationUseCase) AdmitToRegister(ctx context.Context, event *entities.Event, userID uint, inviteToken string) (*uint, error) {
+	if event.Visibility != entities.EventVisibilityPrivate {
+		return nil, nil
+	}
+
+	invited, err := uc.isInvited(ctx, event, userID, true)
+	if err != nil || invited {
+		return nil, err
+	}
+
+	if inviteToken == "" {
+		return nil, ErrInviteRequired
+	}
+	link, err := uc.inviteLink(ctx, event.ID, inviteToken)
+	if err != nil {
+		return nil, err
+	}
+	if !link.IsUsable(time.Now()) {
+		return nil, ErrInviteRequired
+	}
+	return &link.ID, nil
+}
+
+// isInvited reports whether the user is part of the event's team or holds an
+// email invitation that wasn't declined. With accept, a pending invitation is
+// marked as accepted.
+func (uc *EventInvitationUseCase) isInvited(ctx context.Context, event *entities.Event, userID uint, accept bool) (bool, error) {
+	for _, permission := range []entities.Permission{entities.PermissionViewAttendees, entities.PermissionCheckInAttendees} {
+		allowed, err := uc.authorizer.Can(ctx, event, userID, permission)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	invitation, err := uc.invitationRepo.Get(ctx, event.ID, strings.ToLower(user.Email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	switch invitation.Status {
+	case entities.EventInvitationAccepted:
+		return true, nil
+	case entities.EventInvitationPending:
+		if !accept {
+			return true, nil
+		}
+		return true, uc.invitationRepo.UpdateStatus(ctx, invitation.ID, entities.EventInvitationAccepted, time.Now())
+	default:
+		return false, nil
+	}
+}
+
+// inviteLink verifies an invite token for the event and loads its link.
+func (uc *EventInvitationUseCase) inviteLink(ctx context.Context, eventID uint, token string) (*entities.EventInviteLink, error) {
+	claims := &eventInviteClaims{}
+	if err := uc.jwtManager.Parse(token, claims, jwt.WithAudience(eventInviteAudience)); err != nil {
+		return nil, ErrInviteRequired
+	}
+	if claims.EventID != eventID {
+		return nil, ErrInviteRequired
+	}
+	linkID, err := strconv.ParseUint(claims.ID, 10, 64)
+	if err != nil {
+		return nil, ErrInviteRequired
+	}
+
+	link, err := uc.linkRepo.GetByID(ctx, uint(linkID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInviteRequired
+		}
+		return nil, err
+	}
+	if link.EventID != eventID {
+		return nil, ErrInviteRequired
+	}
+	return link, nil
+}
+
+func (uc *EventInvitationUseCase) signInviteLink(link *entities.EventInviteLink) (string, error) {
+	return uc.jwtManager.Sign(&eventInviteClaims{
+		EventID: link.EventID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       strconv.FormatUint(uint64(link.ID), 10),
+			Audience: jwt.ClaimStrings{eventInviteAudience},
+		},
+	})
+}
+
+func (uc *EventInvitationUseCase) newInviteLinkResponse(link *entities.EventInviteLink) (*entities.EventInviteLinkResponse, error) {
+	token, err := uc.signInviteLink(link)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.EventInviteLinkResponse{
+		ID:        link.ID,
+		Token:     token,
+		MaxUses:   link.MaxUses,
+		Uses:      link.Uses,
+		ExpiresAt: link.ExpiresAt,
+		RevokedAt: link.RevokedAt,
+		CreatedAt: link.CreatedAt,
+	}, nil
+}
+
+func (uc *EventInvitationUseCase) authorizedEvent(ctx context.Context, userID, eventID uint) (*entities.Event, error) {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+
+	if err := uc.authorizer.Authorize(ctx, event, userID, entities.PermissionEditEvent); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func newEventInvitationResponse(invitation *entities.EventInvitation) entities.EventInvitationResponse {
+	return entities.EventInvitationResponse{
+		ID:          invitation.ID,
+		EventID:     invitation.EventID,
+		EventTitle:  invitation.Event.Title,
+		Email:       invitation.Email,
+		Status:      invitation.Status,
+		RespondedAt: invitation.RespondedAt,
+		CreatedAt:   invitation.CreatedAt,
+	}
+}
+
+func newEventInvitationResponses(invitations []*entities.EventInvitation) []entities.EventInvitationResponse {
+	response := make([]entities.EventInvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		response = append(response, newEventInvitationResponse(invitation))
+	}
+	return response
+}
@@ -3,17 +3,79 @@ package usecases
 import (
 	"EventsAPI/internal/domain/entities"
 	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/pkg/utils"
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEventNotFound   = errors.New("evento no existe")
+	ErrInvalidCapacity = errors.New("la capacidad del evento no es válida")
+	ErrEventInPast     = errors.New("la fecha del evento debe ser futura")
+	ErrInvalidSchedule = errors.New("las fechas de inscripción y cancelación no son coherentes")
+	ErrInvalidEndTime  = errors.New("el evento debe terminar después de empezar")
+	ErrInvalidTimeZone = errors.New("zona horaria no válida")
+	ErrVenueConflict   = errors.New("el lugar ya está reservado para otro evento a esa hora")
+	ErrRoomTooSmall    = errors.New("la capacidad del evento supera la de la sala")
+	ErrInvalidNearby   = errors.New("búsqueda por cercanía no válida")
+	ErrInvalidSearch   = errors.New("búsqueda no válida")
+	ErrInvalidTags     = errors.New("etiquetas no válidas")
+	ErrInvalidRefunds  = errors.New("política de reembolsos no válida")
+)
+
+// maxSearchRadiusKm is half the Earth's circumference, which covers the whole
+// planet.
+const maxSearchRadiusKm = 20016
+
+// Tag limits. Tags are stored lowercased, so "Jazz" and "jazz" are the same
+// tag.
+const (
+	maxEventTags     = 20
+	maxTagLength     = 40
+	maxTagFacetCount = 50
 )
 
 type EventUseCase struct {
-	eventRepo repositories.EventRepository
-	userRepo  repositories.UserRepository
+	eventRepo      repositories.EventRepository
+	ticketTypeRepo repositories.TicketTypeRepository
+	attendeeRepo   repositories.AttendeeRepository
+	userRepo       repositories.UserRepository
+	authorizer     *EventAuthorizer
+	invitations    *EventInvitationUseCase
+	venues         *VenueUseCase
+	categories     *CategoryUseCase
+	refunds        *RefundUseCase
 }
 
-func NewEventUseCase(eventRepo repositories.EventRepository, userRepo repositories.UserRepository) *EventUseCase {
-	return &EventUseCase{eventRepo: eventRepo, userRepo: userRepo}
+func NewEventUseCase(
+	eventRepo repositories.EventRepository,
+	ticketTypeRepo repositories.TicketTypeRepository,
+	attendeeRepo repositories.AttendeeRepository,
+	userRepo repositories.UserRepository,
+	authorizer *EventAuthorizer,
+	invitations *EventInvitationUseCase,
+	venues *VenueUseCase,
+	categories *CategoryUseCase,
+	refunds *RefundUseCase,
+) *EventUseCase {
+	return &EventUseCase{
+		eventRepo:      eventRepo,
+		ticketTypeRepo: ticketTypeRepo,
+		attendeeRepo:   attendeeRepo,
+		userRepo:       userRepo,
+		authorizer:     authorizer,
+		invitations:    invitations,
+		venues:         venues,
+		categories:     categories,
+		refunds:        refunds,
+	}
 }
 
 func (uc *EventUseCase) CreateEvent(ctx context.Context, event *entities.Event) error {
@@ -22,8 +84,48 @@ func (uc *EventUseCase) CreateEvent(ctx context.Context, event *entities.Event)
 		return errors.New("usuario no encontrado")
 	}
 
+	// New events have no ticket types yet to limit them.
 	if event.MaxCapacity < 1 {
-		return errors.New("la capacidad del evento debe ser mayor que cero")
+		return errCapacityRequired
+	}
+	if err := uc.applyVenue(ctx, event); err != nil {
+		return err
+	}
+	if err := uc.applyClassification(ctx, event); err != nil {
+		return err
+	}
+	if err := normalizeRefundPolicy(event); err != nil {
+		return err
+	}
+
+	if err := normalizeSchedule(event); err != nil {
+		return err
+	}
+	if !isUpcoming(event, time.Now()) {
+		return ErrEventInPast
+	}
+	if err := validateSchedule(event); err != nil {
+		return err
+	}
+	if err := uc.ensureVenueFree(ctx, event); err != nil {
+		return err
+	}
+
+	if event.Visibility == "" {
+		event.Visibility = entities.EventVisibilityPublic
+	}
+	if event.AttendeeVisibility == "" {
+		event.AttendeeVisibility = entities.AttendeeVisibilityOrganizers
+	}
+	if event.Language == "" {
+		event.Language = entities.EventLanguageSpanish
+	}
+
+	if event.OrganizationID != nil {
+		err := uc.authorizer.AuthorizeInOrganization(ctx, *event.OrganizationID, event.UserID, entities.PermissionCreateEvent)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Validaciones adicionales...
@@ -31,22 +133,464 @@ func (uc *EventUseCase) CreateEvent(ctx context.Context, event *entities.Event)
 	return uc.eventRepo.Create(ctx, event)
 }
 
-func (uc *EventUseCase) ListEvents(ctx context.Context, limit, offset int) ([]*entities.Event, error) {
-	return uc.eventRepo.List(ctx, limit, offset)
+func (uc *EventUseCase) ListEvents(ctx context.Context, filter entities.EventFilter, limit, offset int) ([]*entities.Event, error) {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return uc.eventRepo.List(ctx, filter, limit, offset)
+}
+
+// EventFacets counts the upcoming public events matching filter per category,
+// subcategories included, and per tag.
+func (uc *EventUseCase) EventFacets(ctx context.Context, filter entities.EventFilter) (*entities.EventFacets, error) {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	counts, err := uc.eventRepo.CountByCategory(ctx, filter, now)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := uc.categories.Facets(ctx, counts)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := uc.eventRepo.CountByTag(ctx, filter, now, maxTagFacetCount)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.EventFacets{Categories: categories, Tags: tags}, nil
+}
+
+// ListEventsNear returns the upcoming public events within radiusKm of the
+// point, nearest first.
+func (uc *EventUseCase) ListEventsNear(ctx context.Context, lat, lng, radiusKm float64, filter entities.EventFilter, limit, offset int) ([]entities.NearbyEvent, error) {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("%w: near must be a valid latitude,longitude", ErrInvalidNearby)
+	}
+	if radiusKm <= 0 || radiusKm > maxSearchRadiusKm {
+		return nil, fmt.Errorf("%w: radius_km must be between 0 and %d", ErrInvalidNearby, maxSearchRadiusKm)
+	}
+
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := uc.eventRepo.ListNear(ctx, lat, lng, radiusKm, time.Now(), filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	nearby := make([]entities.NearbyEvent, len(events))
+	for i, event := range events {
+		nearby[i] = entities.NearbyEvent{
+			Event:      event,
+			DistanceKm: utils.HaversineKm(lat, lng, *event.Latitude, *event.Longitude),
+		}
+	}
+	return nearby, nil
+}
+
+// SearchEvents runs a full-text search over the public events. The query
+// follows websearch_to_tsquery: "quoted phrases", OR and -excluded words.
+// Words ending in * match as prefixes.
+func (uc *EventUseCase) SearchEvents(ctx context.Context, query, language string, filter entities.EventFilter, limit, offset int) ([]entities.EventSearchResult, error) {
+	if language != "" && language != entities.EventLanguageSpanish && language != entities.EventLanguageEnglish {
+		return nil, fmt.Errorf("%w: unsupported language %q", ErrInvalidSearch, language)
+	}
+
+	text, prefixQuery := splitPrefixTerms(query)
+	if text == "" && prefixQuery == "" {
+		return nil, fmt.Errorf("%w: q is required", ErrInvalidSearch)
+	}
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return uc.eventRepo.Search(ctx, text, prefixQuery, language, filter, limit, offset)
 }
 
 func (uc *EventUseCase) GetEventByID(ctx context.Context, id uint) (*entities.Event, error) {
-	return uc.eventRepo.GetByID(ctx, id)
+	event, err := uc.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+// GetEventForUser returns the event if userID may see it. Private events are
+// reported as not found to users that weren't invited, unless inviteToken is a
+// valid invite for the event.
+func (uc *EventUseCase) GetEventForUser(ctx context.Context, userID, id uint, inviteToken string) (*entities.Event, error) {
+	event, err := uc.GetEventByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := uc.invitations.CanView(ctx, event, userID, inviteToken)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrEventNotFound
+	}
+	return event, nil
 }
 
-func (uc *EventUseCase) UpdateEvent(ctx context.Context, event *entities.Event) error {
-	return uc.eventRepo.Update(ctx, event)
+// UpdateEvent applies req to the event if userID may edit it. Setting a
+// different OrganizationID moves the event, which also requires permission to
+// delete it from its current owner and to create events in the target
+// organization.
+func (uc *EventUseCase) UpdateEvent(ctx context.Context, userID, id uint, req *entities.EventRequest) (*entities.Event, error) {
+	event, err := uc.GetEventByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.authorizer.Authorize(ctx, event, userID, entities.PermissionEditEvent); err != nil {
+		return nil, err
+	}
+
+	if req.OrganizationID != nil && (event.OrganizationID == nil || *event.OrganizationID != *req.OrganizationID) {
+		if err := uc.authorizer.Authorize(ctx, event, userID, entities.PermissionDeleteEvent); err != nil {
+			return nil, err
+		}
+		err := uc.authorizer.AuthorizeInOrganization(ctx, *req.OrganizationID, userID, entities.PermissionCreateEvent)
+		if err != nil {
+			return nil, err
+		}
+		event.OrganizationID = req.OrganizationID
+	}
+
+	previousStart := event.DateTime
+
+	event.Title = req.Title
+	event.Description = req.Description
+	event.Location = req.Location
+	event.VenueID = req.VenueID
+	event.RoomID = req.RoomID
+	event.Latitude = req.Latitude
+	event.Longitude = req.Longitude
+	event.DateTime = req.DateTime
+	event.EndsAt = req.EndsAt
+	event.AllDay = req.AllDay
+	if req.TimeZone != "" {
+		event.TimeZone = req.TimeZone
+	}
+	event.MaxCapacity = req.MaxCapacity
+	if err := uc.checkCapacity(ctx, event); err != nil {
+		return nil, err
+	}
+	event.RequiresApproval = req.RequiresApproval
+	event.MaxGuests = req.MaxGuests
+	if req.Language != "" {
+		event.Language = req.Language
+	}
+	event.RegistrationOpensAt = req.RegistrationOpensAt
+	event.RegistrationClosesAt = req.RegistrationClosesAt
+	event.CancellationDeadline = req.CancellationDeadline
+	event.CategoryID = req.CategoryID
+	event.Tags = req.Tags
+	event.RefundPolicy = req.RefundPolicy
+	event.TaxRate = req.TaxRate
+	event.TaxName = strings.TrimSpace(req.TaxName)
+	if err := uc.applyVenue(ctx, event); err != nil {
+		return nil, err
+	}
+	if err := uc.applyClassification(ctx, event); err != nil {
+		return nil, err
+	}
+	if err := normalizeRefundPolicy(event); err != nil {
+		return nil, err
+	}
+	if err := normalizeSchedule(event); err != nil {
+		return nil, err
+	}
+	if !event.DateTime.Equal(previousStart) && !isUpcoming(event, time.Now()) {
+		return nil, ErrEventInPast
+	}
+	if err := validateSchedule(event); err != nil {
+		return nil, err
+	}
+	if err := uc.ensureVenueFree(ctx, event); err != nil {
+		return nil, err
+	}
+	if req.Visibility != "" {
+		event.Visibility = req.Visibility
+	}
+	if req.AttendeeVisibility != "" {
+		event.AttendeeVisibility = req.AttendeeVisibility
+	}
+
+	if err := uc.eventRepo.Update(ctx, event); err != nil {
+		return nil, err
+	}
+	return event, nil
 }
 
-func (uc *EventUseCase) DeleteEvent(ctx context.Context, id uint) error {
+func (uc *EventUseCase) DeleteEvent(ctx context.Context, userID, id uint) error {
+	event, err := uc.GetEventByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.authorizer.Authorize(ctx, event, userID, entities.PermissionDeleteEvent); err != nil {
+		return err
+	}
+
+	// Deleting an event cancels it: its buyers get their money back.
+	if err := uc.refunds.RefundEvent(ctx, event); err != nil {
+		return err
+	}
 	return uc.eventRepo.Delete(ctx, id)
 }
 
+// GetUserEvents returns the events the user manages, personally or through an
+// organization.
 func (uc *EventUseCase) GetUserEvents(ctx context.Context, userID uint, limit, offset int) ([]*entities.Event, error) {
-	return uc.eventRepo.GetByUserID(ctx, userID, limit, offset)
+	return uc.eventRepo.GetManagedByUserID(ctx, userID, limit, offset)
+}
+
+// errCapacityRequired is returned for events without a capacity that have no
+// ticket types to limit them.
+var errCapacityRequired = fmt.Errorf("%w: debe ser mayor que cero si no tiene tipos de entrada", ErrInvalidCapacity)
+
+// checkCapacity requires an overall capacity unless the event's ticket types
+// limit it, and refuses one below the seats already taken.
+func (uc *EventUseCase) checkCapacity(ctx context.Context, event *entities.Event) error {
+	if event.MaxCapacity < 0 {
+		return ErrInvalidCapacity
+	}
+	if event.MaxCapacity == 0 {
+		ticketTypes, err := uc.ticketTypeRepo.GetByEventID(ctx, event.ID)
+		if err != nil {
+			return err
+		}
+		if len(ticketTypes) == 0 {
+			return errCapacityRequired
+		}
+		return nil
+	}
+
+	seats, err := uc.attendeeRepo.CountSeatsByEventID(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	if event.MaxCapacity < seats {
+		return fmt.Errorf("%w: ya hay %d plazas ocupadas", ErrInvalidCapacity, seats)
+	}
+	return nil
+}
+
+// applyVenue checks the event's venue and room, takes the location from the
+// venue when it is missing and rejects a capacity larger than the room's.
+// Events at a venue always take its coordinates.
+func (uc *EventUseCase) applyVenue(ctx context.Context, event *entities.Event) error {
+	if event.VenueID == nil {
+		event.RoomID = nil
+		return nil
+	}
+
+	venue, err := uc.venues.GetVenue(ctx, *event.VenueID)
+	if err != nil {
+		return err
+	}
+	if event.Location == "" {
+		event.Location = venue.FullAddress()
+	}
+	event.Latitude = venue.Latitude
+	event.Longitude = venue.Longitude
+
+	if event.RoomID != nil {
+		room, err := uc.venues.GetRoom(ctx, venue.ID, *event.RoomID)
+		if err != nil {
+			return err
+		}
+		if event.MaxCapacity > room.Capacity {
+			return fmt.Errorf("%w: %q holds %d people", ErrRoomTooSmall, room.Name, room.Capacity)
+		}
+	}
+	return nil
+}
+
+// applyClassification checks that the event's category exists and
+// normalizes its tags.
+func (uc *EventUseCase) applyClassification(ctx context.Context, event *entities.Event) error {
+	if event.CategoryID != nil {
+		if _, err := uc.categories.GetCategory(ctx, *event.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	tags, err := normalizeTags(event.Tags)
+	if err != nil {
+		return err
+	}
+	if len(tags) > maxEventTags {
+		return fmt.Errorf("%w: at most %d tags", ErrInvalidTags, maxEventTags)
+	}
+	event.Tags = tags
+	return nil
+}
+
+// normalizeRefundPolicy sorts the refund rules from the earliest cancellation
+// and rejects two rules for the same number of days.
+func normalizeRefundPolicy(event *entities.Event) error {
+	rules := slices.Clone(event.RefundPolicy)
+	if rules == nil {
+		rules = []entities.RefundRule{}
+	}
+	slices.SortFunc(rules, func(a, b entities.RefundRule) int { return b.DaysBefore - a.DaysBefore })
+	for i := 1; i < len(rules); i++ {
+		if rules[i].DaysBefore == rules[i-1].DaysBefore {
+			return fmt.Errorf("%w: more than one rule for %d days before", ErrInvalidRefunds, rules[i].DaysBefore)
+		}
+	}
+	event.RefundPolicy = rules
+	return nil
+}
+
+// normalizeTags trims and lowercases the tags, collapses inner spaces and
+// drops empty and repeated tags, keeping their order.
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len([]rune(tag)) > maxTagLength {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTags, tag, maxTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized, nil
+}
+
+// normalizeFilter normalizes the filter tags the same way as event tags.
+func normalizeFilter(filter entities.EventFilter) (entities.EventFilter, error) {
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil {
+		return filter, err
+	}
+	filter.Tags = tags
+	return filter, nil
+}
+
+// splitPrefixTerms takes the words ending in * out of a search query, outside
+// quoted phrases, and returns the rest of the query and a to_tsquery query
+// matching all of those words as prefixes. Prefix words are reduced to letters
+// and digits so they can't inject tsquery operators.
+func splitPrefixTerms(query string) (string, string) {
+	var rest, prefixes []string
+	inPhrase := false
+	for _, word := range strings.Fields(query) {
+		quotes := strings.Count(word, `"`)
+		if !inPhrase && quotes == 0 && strings.HasSuffix(word, "*") {
+			prefix := strings.Map(func(r rune) rune {
+				if unicode.IsLetter(r) || unicode.IsDigit(r) {
+					return r
+				}
+				return -1
+			}, word)
+			if prefix != "" {
+				prefixes = append(prefixes, prefix+":*")
+			}
+			continue
+		}
+		if quotes%2 == 1 {
+			inPhrase = !inPhrase
+		}
+		rest = append(rest, word)
+	}
+	return strings.Join(rest, " "), strings.Join(prefixes, " & ")
+}
+
+// ensureVenueFree returns ErrVenueConflict when another event at the same
+// venue or room overlaps the event.
+func (uc *EventUseCase) ensureVenueFree(ctx context.Context, event *entities.Event) error {
+	overlapping, err := uc.eventRepo.GetOverlappingAtVenue(ctx, event)
+	if err != nil {
+		return err
+	}
+	if len(overlapping) > 0 {
+		return fmt.Errorf("%w: %s", ErrVenueConflict, eventTitles(overlapping))
+	}
+	return nil
+}
+
+// normalizeSchedule defaults the time zone to UTC, stores the start and end in
+// UTC and extends all-day events to whole days in the event's time zone. It
+// rejects events that don't end after they start.
+func normalizeSchedule(event *entities.Event) error {
+	if event.TimeZone == "" {
+		event.TimeZone = "UTC"
+	}
+	loc, err := time.LoadLocation(event.TimeZone)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidTimeZone, event.TimeZone)
+	}
+
+	if event.AllDay {
+		start := startOfDay(event.DateTime, loc)
+		end := start.AddDate(0, 0, 1)
+		if event.EndsAt != nil {
+			end = startOfDay(*event.EndsAt, loc)
+			if end.Before(event.EndsAt.In(loc)) {
+				end = end.AddDate(0, 0, 1)
+			}
+		}
+		event.DateTime = start
+		event.EndsAt = &end
+	}
+
+	event.DateTime = event.DateTime.UTC()
+	if event.EndsAt != nil {
+		end := event.EndsAt.UTC()
+		if !end.After(event.DateTime) {
+			return ErrInvalidEndTime
+		}
+		event.EndsAt = &end
+	}
+	return nil
+}
+
+// startOfDay returns midnight of t's day in loc.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// isUpcoming reports whether the event starts after now. All-day events count
+// as upcoming until they end.
+func isUpcoming(event *entities.Event, now time.Time) bool {
+	if event.AllDay {
+		return event.EndsAtOrStart().After(now)
+	}
+	return event.DateTime.After(now)
+}
+
+// validateSchedule checks that registration opens before it closes and that
+// registration and cancellation both close by the time the event starts.
+func validateSchedule(event *entities.Event) error {
+	closesAt := event.RegistrationClosesAtOrStart()
+	if closesAt.After(event.DateTime) {
+		return fmt.Errorf("%w: registration_closes_at is after the event starts", ErrInvalidSchedule)
+	}
+	if event.RegistrationOpensAt != nil && !event.RegistrationOpensAt.Before(closesAt) {
+		return fmt.Errorf("%w: registration_opens_at must be before registration closes", ErrInvalidSchedule)
+	}
+	if event.CancellationDeadlineOrStart().After(event.DateTime) {
+		return fmt.Errorf("%w: cancellation_deadline is after the event starts", ErrInvalidSchedule)
+	}
+	return nil
 }
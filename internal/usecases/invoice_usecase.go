@@ -0,0 +1,246 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
+)
+
+var ErrInvoiceNotAvailable = errors.New("only paid orders have an invoice")
+
+// InvoiceUseCase issues the invoices of paid orders and the credit notes of
+// their refunds. Each organization, or the organizer of personal events,
+// numbers its invoices and credit notes in its own sequences.
+type InvoiceUseCase struct {
+	invoiceRepo      repositories.InvoiceRepository
+	orderRepo        repositories.OrderRepository
+	organizationRepo repositories.OrganizationRepository
+	userRepo         repositories.UserRepository
+	renderer         services.InvoiceRenderer
+}
+
+func NewInvoiceUseCase(
+	invoiceRepo repositories.InvoiceRepository,
+	orderRepo repositories.OrderRepository,
+	organizationRepo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+	renderer services.InvoiceRenderer,
+) *InvoiceUseCase {
+	return &InvoiceUseCase{
+		invoiceRepo:      invoiceRepo,
+		orderRepo:        orderRepo,
+		organizationRepo: organizationRepo,
+		userRepo:         userRepo,
+		renderer:         renderer,
+	}
+}
+
+// render issues what the paid order is missing and renders its invoice
+// followed by its credit notes. It returns the document's content type.
+func (uc *InvoiceUseCase) render(ctx context.Context, order *entities.Order) ([]byte, string, error) {
+	if order.PaidAt == nil {
+		return nil, "", ErrInvoiceNotAvailable
+	}
+	invoices, err := uc.issue(ctx, order.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var document bytes.Buffer
+	if err := uc.renderer.Render(&document, invoices); err != nil {
+		return nil, "", err
+	}
+	return document.Bytes(), uc.renderer.ContentType(), nil
+}
+
+// issue issues the invoice of a paid order and a credit note for each of its
+// succeeded refunds, unless they were already issued, and returns them all.
+// Orders that weren't paid have none.
+func (uc *InvoiceUseCase) issue(ctx context.Context, orderID uint) ([]*entities.Invoice, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil || order.PaidAt == nil {
+		return nil, err
+	}
+	invoices, err := uc.invoiceRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, credited := findInvoices(invoices)
+	if invoice == nil {
+		invoice, err = uc.newInvoice(ctx, order)
+		if err != nil {
+			return nil, err
+		}
+		if invoice, err = uc.issueOne(ctx, invoice); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range order.Refunds {
+		refund := &order.Refunds[i]
+		if refund.Status != entities.RefundStatusSucceeded || credited[refund.ID] {
+			continue
+		}
+		if _, err := uc.issueOne(ctx, newCreditNote(invoice, refund)); err != nil {
+			return nil, err
+		}
+	}
+	return uc.invoiceRepo.GetByOrderID(ctx, order.ID)
+}
+
+// issueOne numbers and stores an invoice. When another request issued the
+// order's invoice first, that one is returned instead.
+func (uc *InvoiceUseCase) issueOne(ctx context.Context, invoice *entities.Invoice) (*entities.Invoice, error) {
+	prefix := "INV"
+	if invoice.Kind == entities.InvoiceKindCreditNote {
+		prefix = "CN"
+	}
+	issued, err := uc.invoiceRepo.Issue(ctx, invoice, func(sequence int) string {
+		return fmt.Sprintf("%s-%06d", prefix, sequence)
+	})
+	if err != nil || issued {
+		return invoice, err
+	}
+
+	invoices, err := uc.invoiceRepo.GetByOrderID(ctx, invoice.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	existing, _ := findInvoices(invoices)
+	if existing == nil {
+		return nil, fmt.Errorf("invoice of order %d not found", invoice.OrderID)
+	}
+	return existing, nil
+}
+
+// newInvoice makes out the invoice of a paid order: a line for its tickets
+// and one for its discount, with the tax included in the event's prices.
+func (uc *InvoiceUseCase) newInvoice(ctx context.Context, order *entities.Order) (*entities.Invoice, error) {
+	series, issuer, err := uc.issuer(ctx, &order.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	billing := entities.BillingDetails{
+		Name:  strings.TrimSpace(order.User.FirstName + " " + order.User.LastName),
+		Email: order.User.Email,
+	}
+	if order.Billing != nil {
+		billing = *order.Billing
+	}
+
+	lines := []entities.InvoiceLine{{
+		Description: fmt.Sprintf("%s - %s", order.TicketType.Name, order.Event.Title),
+		Quantity:    order.Quantity,
+		UnitPrice:   order.UnitPrice,
+		Amount:      order.UnitPrice * int64(order.Quantity),
+	}}
+	if order.Discount > 0 {
+		lines = append(lines, entities.InvoiceLine{
+			Description: strings.TrimSpace("Discount " + order.PromoCode),
+			Quantity:    1,
+			UnitPrice:   -order.Discount,
+			Amount:      -order.Discount,
+		})
+	}
+
+	net, tax := entities.SplitTax(order.Amount, order.Event.TaxRate)
+	return &entities.Invoice{
+		Kind:     entities.InvoiceKindInvoice,
+		Series:   series,
+		OrderID:  order.ID,
+		Issuer:   *issuer,
+		Billing:  billing,
+		Lines:    lines,
+		Currency: order.Currency,
+		Net:      net,
+		TaxName:  order.Event.TaxName,
+		TaxRate:  order.Event.TaxRate,
+		Tax:      tax,
+		Total:    order.Amount,
+		IssuedAt: time.Now(),
+	}, nil
+}
+
+// issuer returns the series and the seller of the event's invoices: its
+// organization or, for personal events, its organizer.
+func (uc *InvoiceUseCase) issuer(ctx context.Context, event *entities.Event) (string, *entities.InvoiceIssuer, error) {
+	if event.OrganizationID != nil {
+		organization, err := uc.organizationRepo.GetByID(ctx, *event.OrganizationID)
+		if err != nil {
+			return "", nil, err
+		}
+		issuer := &entities.InvoiceIssuer{
+			Name:    organization.LegalName,
+			TaxID:   organization.TaxID,
+			Address: organization.BillingAddress,
+		}
+		if issuer.Name == "" {
+			issuer.Name = organization.Name
+		}
+		return fmt.Sprintf("organization-%d", organization.ID), issuer, nil
+	}
+
+	organizer, err := uc.userRepo.GetByID(ctx, event.UserID)
+	if err != nil {
+		return "", nil, err
+	}
+	issuer := &entities.InvoiceIssuer{Name: strings.TrimSpace(organizer.FirstName + " " + organizer.LastName)}
+	if issuer.Name == "" {
+		issuer.Name = organizer.Email
+	}
+	return fmt.Sprintf("user-%d", organizer.ID), issuer, nil
+}
+
+// newCreditNote corrects the invoice by the amount of a refund, keeping the
+// invoice's issuer, buyer and tax rate.
+func newCreditNote(invoice *entities.Invoice, refund *entities.Refund) *entities.Invoice {
+	net, tax := entities.SplitTax(refund.Amount, invoice.TaxRate)
+	return &entities.Invoice{
+		Kind:           entities.InvoiceKindCreditNote,
+		Series:         invoice.Series,
+		OrderID:        invoice.OrderID,
+		RefundID:       &refund.ID,
+		CorrectsID:     &invoice.ID,
+		CorrectsNumber: invoice.Number,
+		Issuer:         invoice.Issuer,
+		Billing:        invoice.Billing,
+		Lines: []entities.InvoiceLine{{
+			Description: "Refund of invoice " + invoice.Number,
+			Quantity:    1,
+			UnitPrice:   refund.Amount,
+			Amount:      refund.Amount,
+		}},
+		Currency: refund.Currency,
+		Net:      net,
+		TaxName:  invoice.TaxName,
+		TaxRate:  invoice.TaxRate,
+		Tax:      tax,
+		Total:    refund.Amount,
+		IssuedAt: time.Now(),
+	}
+}
+
+// findInvoices returns the invoice among an order's invoices and the refunds
+// that have a credit note.
+func findInvoices(invoices []*entities.Invoice) (*entities.Invoice, map[uint]bool) {
+	var invoice *entities.Invoice
+	credited := make(map[uint]bool)
+	for _, existing := range invoices {
+		switch {
+		case existing.Kind == entities.InvoiceKindInvoice:
+			invoice = existing
+		case existing.RefundID != nil:
+			credited[*existing.RefundID] = true
+		}
+	}
+	return invoice, credited
+}
@@ -0,0 +1,522 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
+
+	"gorm.io/gorm"
+)
+
+// memoryStore holds the rows of the in-memory repositories used by the order,
+// refund and promo code tests. Rows are copied in and out like a database
+// would, so a use case only changes what it saves. The repositories embed
+// their interface: calling a method a test doesn't need panics.
+type memoryStore struct {
+	users       *memoryUserRepository
+	events      map[uint]entities.Event
+	ticketTypes map[uint]entities.TicketType
+	attendees   map[uint]entities.Attendee
+	orders      map[uint]entities.Order
+	refunds     map[uint]entities.Refund
+	promoCodes  map[uint]entities.PromoCode
+	redemptions []entities.PromoRedemption
+	invoices    []entities.Invoice
+	nextID      uint
+	// failRefunds makes creating refunds fail.
+	failRefunds error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		users:       &memoryUserRepository{},
+		events:      map[uint]entities.Event{},
+		ticketTypes: map[uint]entities.TicketType{},
+		attendees:   map[uint]entities.Attendee{},
+		orders:      map[uint]entities.Order{},
+		refunds:     map[uint]entities.Refund{},
+		promoCodes:  map[uint]entities.PromoCode{},
+	}
+}
+
+func (s *memoryStore) newID() uint {
+	s.nextID++
+	return s.nextID
+}
+
+// countSeats counts the seats of the going attendees of the event other than
+// except, in total and with the ticket type.
+func (s *memoryStore) countSeats(eventID, except uint, ticketTypeID *uint) (eventSeats, ticketSeats int) {
+	for _, attendee := range s.attendees {
+		if attendee.EventID != eventID || attendee.ID == except || !attendee.IsGoing() {
+			continue
+		}
+		eventSeats += attendee.Seats()
+		if ticketTypeID != nil && attendee.TicketTypeID != nil && *attendee.TicketTypeID == *ticketTypeID {
+			ticketSeats += attendee.Seats()
+		}
+	}
+	return eventSeats, ticketSeats
+}
+
+func (s *memoryStore) saveAttendee(attendee *entities.Attendee) {
+	if attendee.ID == 0 {
+		attendee.ID = s.newID()
+		attendee.CreatedAt = time.Now()
+	}
+	row := *attendee
+	row.User = entities.User{}
+	row.Event = entities.Event{}
+	s.attendees[attendee.ID] = row
+}
+
+func (s *memoryStore) order(id uint) (*entities.Order, error) {
+	row, ok := s.orders[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	order := row
+	order.Event = s.events[order.EventID]
+	order.TicketType = s.ticketTypes[order.TicketTypeID]
+	if user, err := s.users.GetByID(context.Background(), order.UserID); err == nil {
+		order.User = *user
+	}
+	order.Refunds = nil
+	for _, refund := range s.refunds {
+		if refund.OrderID == order.ID {
+			order.Refunds = append(order.Refunds, refund)
+		}
+	}
+	slices.SortFunc(order.Refunds, func(a, b entities.Refund) int { return int(a.ID) - int(b.ID) })
+	return &order, nil
+}
+
+func (s *memoryStore) releaseOrder(id uint, status string) bool {
+	order, ok := s.orders[id]
+	if !ok || order.Status != entities.OrderStatusPending {
+		return false
+	}
+	order.Status = status
+	s.orders[id] = order
+	if attendee, ok := s.attendees[order.AttendeeID]; ok && attendee.Status == entities.AttendeeStatusAwaitingPayment {
+		attendee.Status = entities.AttendeeStatusCancelled
+		attendee.InviteLinkID = nil
+		s.attendees[attendee.ID] = attendee
+	}
+	return true
+}
+
+// createRefund creates the refund like RefundRepository.CreateWithinOrder.
+func (s *memoryStore) createRefund(refund *entities.Refund, check func(order *entities.Order, refunded int64) error) error {
+	if s.failRefunds != nil {
+		return s.failRefunds
+	}
+	order, ok := s.orders[refund.OrderID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	var refunded int64
+	for _, other := range s.refunds {
+		if other.OrderID == order.ID && (other.Status == entities.RefundStatusPending || other.Status == entities.RefundStatusSucceeded) {
+			refunded += other.Amount
+		}
+	}
+	if err := check(&order, refunded); err != nil {
+		return err
+	}
+	refund.ID = s.newID()
+	s.refunds[refund.ID] = *refund
+	s.syncOrderRefunds(order.ID)
+	return nil
+}
+
+func (s *memoryStore) syncOrderRefunds(orderID uint) {
+	order := s.orders[orderID]
+	var succeeded int64
+	var pending, failed int
+	for _, refund := range s.refunds {
+		if refund.OrderID != orderID {
+			continue
+		}
+		switch refund.Status {
+		case entities.RefundStatusSucceeded:
+			succeeded += refund.Amount
+		case entities.RefundStatusPending:
+			pending++
+		case entities.RefundStatusFailed:
+			failed++
+		}
+	}
+	order.RefundedAmount = succeeded
+	switch {
+	case pending > 0:
+		order.RefundStatus = entities.OrderRefundPending
+	case succeeded > 0 && succeeded >= order.Amount:
+		order.RefundStatus = entities.OrderRefundRefunded
+	case succeeded > 0:
+		order.RefundStatus = entities.OrderRefundPartial
+	case failed > 0:
+		order.RefundStatus = entities.OrderRefundFailed
+	default:
+		order.RefundStatus = ""
+	}
+	s.orders[orderID] = order
+}
+
+type memoryEventRepository struct {
+	repositories.EventRepository
+	store *memoryStore
+}
+
+func (r *memoryEventRepository) GetByID(ctx context.Context, id uint) (*entities.Event, error) {
+	event, ok := r.store.events[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &event, nil
+}
+
+type memoryTicketTypeRepository struct {
+	repositories.TicketTypeRepository
+	store *memoryStore
+}
+
+func (r *memoryTicketTypeRepository) GetByID(ctx context.Context, id uint) (*entities.TicketType, error) {
+	ticketType, ok := r.store.ticketTypes[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &ticketType, nil
+}
+
+func (r *memoryTicketTypeRepository) GetByEventID(ctx context.Context, eventID uint) ([]*entities.TicketType, error) {
+	var ticketTypes []*entities.TicketType
+	for _, ticketType := range r.store.ticketTypes {
+		if ticketType.EventID == eventID {
+			ticketTypes = append(ticketTypes, &ticketType)
+		}
+	}
+	return ticketTypes, nil
+}
+
+type memoryAttendeeRepository struct {
+	repositories.AttendeeRepository
+	store *memoryStore
+}
+
+func (r *memoryAttendeeRepository) Create(ctx context.Context, attendee *entities.Attendee) error {
+	r.store.saveAttendee(attendee)
+	return nil
+}
+
+func (r *memoryAttendeeRepository) Update(ctx context.Context, attendee *entities.Attendee) error {
+	r.store.saveAttendee(attendee)
+	return nil
+}
+
+func (r *memoryAttendeeRepository) GetByID(ctx context.Context, id uint) (*entities.Attendee, error) {
+	attendee, ok := r.store.attendees[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &attendee, nil
+}
+
+func (r *memoryAttendeeRepository) Get(ctx context.Context, eventID, userID uint) (*entities.Attendee, error) {
+	for _, attendee := range r.store.attendees {
+		if attendee.EventID == eventID && attendee.UserID == userID {
+			if user, err := r.store.users.GetByID(ctx, userID); err == nil {
+				attendee.User = *user
+			}
+			return &attendee, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryAttendeeRepository) GetOverlapping(ctx context.Context, userID uint, event *entities.Event) ([]*entities.Attendee, error) {
+	return nil, nil
+}
+
+func (r *memoryAttendeeRepository) CountSeatsByEventID(ctx context.Context, eventID uint) (int, error) {
+	seats, _ := r.store.countSeats(eventID, 0, nil)
+	return seats, nil
+}
+
+func (r *memoryAttendeeRepository) CountSeatsByTicketType(ctx context.Context, eventID uint) (map[uint]int, error) {
+	seats := map[uint]int{}
+	for _, attendee := range r.store.attendees {
+		if attendee.EventID == eventID && attendee.IsGoing() && attendee.TicketTypeID != nil {
+			seats[*attendee.TicketTypeID] += attendee.Seats()
+		}
+	}
+	return seats, nil
+}
+
+func (r *memoryAttendeeRepository) SaveWithinCapacity(ctx context.Context, attendee *entities.Attendee, check func(eventSeats, ticketSeats int) error) error {
+	if err := check(r.store.countSeats(attendee.EventID, attendee.ID, attendee.TicketTypeID)); err != nil {
+		return err
+	}
+	r.store.saveAttendee(attendee)
+	return nil
+}
+
+func (r *memoryAttendeeRepository) Register(ctx context.Context, attendee *entities.Attendee, now time.Time, check func(eventSeats, ticketSeats int) error) error {
+	return r.SaveWithinCapacity(ctx, attendee, check)
+}
+
+func (r *memoryAttendeeRepository) CancelReservation(ctx context.Context, id uint) error {
+	attendee, ok := r.store.attendees[id]
+	if ok && attendee.Status == entities.AttendeeStatusAwaitingPayment {
+		attendee.Status = entities.AttendeeStatusCancelled
+		attendee.InviteLinkID = nil
+		r.store.attendees[id] = attendee
+	}
+	return nil
+}
+
+func (r *memoryAttendeeRepository) Cancel(ctx context.Context, attendee *entities.Attendee, refund *entities.Refund, check func(order *entities.Order, refunded int64) error) error {
+	if refund != nil {
+		if err := r.store.createRefund(refund, check); err != nil {
+			return err
+		}
+	}
+	r.store.saveAttendee(attendee)
+	return nil
+}
+
+type memoryRegistrationQuestionRepository struct {
+	repositories.RegistrationQuestionRepository
+}
+
+func (r *memoryRegistrationQuestionRepository) GetByEventID(ctx context.Context, eventID uint) ([]*entities.RegistrationQuestion, error) {
+	return nil, nil
+}
+
+func (r *memoryRegistrationQuestionRepository) ReplaceAnswers(ctx context.Context, attendeeID uint, answers []entities.RegistrationAnswer) error {
+	return nil
+}
+
+type memoryOrderRepository struct {
+	repositories.OrderRepository
+	store *memoryStore
+	// failSetPayment makes SetPayment fail, as when the database is lost
+	// right after the provider created the payment.
+	failSetPayment error
+}
+
+func (r *memoryOrderRepository) Create(ctx context.Context, order *entities.Order) error {
+	order.ID = r.store.newID()
+	order.CreatedAt = time.Now()
+	row := *order
+	row.Event, row.User, row.TicketType, row.Refunds = entities.Event{}, entities.User{}, entities.TicketType{}, nil
+	r.store.orders[order.ID] = row
+	return nil
+}
+
+func (r *memoryOrderRepository) GetByID(ctx context.Context, id uint) (*entities.Order, error) {
+	return r.store.order(id)
+}
+
+func (r *memoryOrderRepository) GetByPaymentID(ctx context.Context, provider, paymentID string) (*entities.Order, error) {
+	for _, order := range r.store.orders {
+		if order.Provider == provider && order.PaymentID == paymentID {
+			return r.store.order(order.ID)
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryOrderRepository) GetPaidByAttendeeID(ctx context.Context, attendeeID uint) (*entities.Order, error) {
+	var latest *entities.Order
+	for _, order := range r.store.orders {
+		if order.AttendeeID == attendeeID && order.Status == entities.OrderStatusPaid && (latest == nil || order.ID > latest.ID) {
+			latest = &order
+		}
+	}
+	if latest == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.store.order(latest.ID)
+}
+
+func (r *memoryOrderRepository) SetPayment(ctx context.Context, id uint, paymentID, checkoutURL string) (bool, error) {
+	if r.failSetPayment != nil {
+		return false, r.failSetPayment
+	}
+	order, ok := r.store.orders[id]
+	if !ok || order.PaymentID != "" {
+		return false, nil
+	}
+	order.PaymentID = paymentID
+	order.CheckoutURL = checkoutURL
+	r.store.orders[id] = order
+	return true, nil
+}
+
+func (r *memoryOrderRepository) UpdateStatus(ctx context.Context, id uint, from, to string) (bool, error) {
+	order, ok := r.store.orders[id]
+	if !ok || order.Status != from {
+		return false, nil
+	}
+	order.Status = to
+	r.store.orders[id] = order
+	return true, nil
+}
+
+func (r *memoryOrderRepository) ConfirmPayment(ctx context.Context, id uint, paidAt time.Time) (bool, error) {
+	order, ok := r.store.orders[id]
+	if !ok || order.Status != entities.OrderStatusPending {
+		return false, nil
+	}
+	order.Status = entities.OrderStatusPaid
+	order.PaidAt = &paidAt
+	r.store.orders[id] = order
+	if attendee, ok := r.store.attendees[order.AttendeeID]; ok && attendee.Status == entities.AttendeeStatusAwaitingPayment {
+		attendee.Status = entities.AttendeeStatusConfirmed
+		r.store.attendees[attendee.ID] = attendee
+	}
+	return true, nil
+}
+
+func (r *memoryOrderRepository) MarkPaid(ctx context.Context, id uint, from string, paidAt time.Time) (bool, error) {
+	order, ok := r.store.orders[id]
+	if !ok || order.Status != from {
+		return false, nil
+	}
+	order.Status = entities.OrderStatusPaid
+	order.PaidAt = &paidAt
+	r.store.orders[id] = order
+	return true, nil
+}
+
+func (r *memoryOrderRepository) Release(ctx context.Context, id uint, status string) (bool, error) {
+	return r.store.releaseOrder(id, status), nil
+}
+
+func (r *memoryOrderRepository) ExpirePending(ctx context.Context, now time.Time) (int, error) {
+	expired := 0
+	for _, order := range r.store.orders {
+		if !order.ExpiresAt.After(now) && r.store.releaseOrder(order.ID, entities.OrderStatusExpired) {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+type memoryRefundRepository struct {
+	repositories.RefundRepository
+	store *memoryStore
+}
+
+func (r *memoryRefundRepository) CreateWithinOrder(ctx context.Context, refund *entities.Refund, check func(order *entities.Order, refunded int64) error) error {
+	return r.store.createRefund(refund, check)
+}
+
+func (r *memoryRefundRepository) Update(ctx context.Context, refund *entities.Refund) error {
+	r.store.refunds[refund.ID] = *refund
+	r.store.syncOrderRefunds(refund.OrderID)
+	return nil
+}
+
+type memoryPromoCodeRepository struct {
+	repositories.PromoCodeRepository
+	store *memoryStore
+}
+
+func (r *memoryPromoCodeRepository) FindForEvent(ctx context.Context, code string, eventID uint, organizationID *uint) (*entities.PromoCode, error) {
+	var found *entities.PromoCode
+	for _, promoCode := range r.store.promoCodes {
+		if !strings.EqualFold(promoCode.Code, code) {
+			continue
+		}
+		if promoCode.EventID != nil && *promoCode.EventID == eventID {
+			return &promoCode, nil
+		}
+		if promoCode.OrganizationID != nil && organizationID != nil && *promoCode.OrganizationID == *organizationID {
+			found = &promoCode
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return found, nil
+}
+
+func (r *memoryPromoCodeRepository) Redeem(ctx context.Context, redemption *entities.PromoRedemption, check func(redemptions, userRedemptions int) error) error {
+	if _, ok := r.store.promoCodes[redemption.PromoCodeID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	var redemptions, userRedemptions int
+	for _, other := range r.store.redemptions {
+		status := r.store.orders[other.OrderID].Status
+		if other.PromoCodeID != redemption.PromoCodeID || (status != entities.OrderStatusPending && status != entities.OrderStatusPaid) {
+			continue
+		}
+		redemptions++
+		if other.UserID == redemption.UserID {
+			userRedemptions++
+		}
+	}
+	if err := check(redemptions, userRedemptions); err != nil {
+		return err
+	}
+	redemption.ID = r.store.newID()
+	r.store.redemptions = append(r.store.redemptions, *redemption)
+	return nil
+}
+
+type memoryInvoiceRepository struct {
+	repositories.InvoiceRepository
+	store *memoryStore
+}
+
+func (r *memoryInvoiceRepository) GetByOrderID(ctx context.Context, orderID uint) ([]*entities.Invoice, error) {
+	var invoices []*entities.Invoice
+	for _, invoice := range r.store.invoices {
+		if invoice.OrderID == orderID {
+			invoices = append(invoices, &invoice)
+		}
+	}
+	return invoices, nil
+}
+
+func (r *memoryInvoiceRepository) Issue(ctx context.Context, invoice *entities.Invoice, number func(sequence int) string) (bool, error) {
+	sequence := 1
+	for _, other := range r.store.invoices {
+		if other.Kind == entities.InvoiceKindInvoice && invoice.Kind == entities.InvoiceKindInvoice && other.OrderID == invoice.OrderID {
+			return false, nil
+		}
+		if other.RefundID != nil && invoice.RefundID != nil && *other.RefundID == *invoice.RefundID {
+			return false, nil
+		}
+		if other.Series == invoice.Series && other.Kind == invoice.Kind {
+			sequence++
+		}
+	}
+	invoice.ID = r.store.newID()
+	invoice.Sequence = sequence
+	invoice.Number = number(sequence)
+	r.store.invoices = append(r.store.invoices, *invoice)
+	return true, nil
+}
+
+// recordingNotifier keeps the notifications it is asked to send, failing for
+// the addresses in fail.
+type recordingNotifier struct {
+	sent []services.Notification
+	fail map[string]error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification services.Notification) error {
+	if err := n.fail[notification.To]; err != nil {
+		return err
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
@@ -0,0 +1,190 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
+	"EventsAPI/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const oidcLoginStateTTL = 10 * time.Minute
+
+var (
+	ErrUnknownIdentityProvider = errors.New("unknown identity provider")
+	ErrInvalidLoginState       = errors.New("invalid or expired login state")
+	ErrEmailNotVerified        = errors.New("the identity provider did not verify the email address")
+)
+
+type OIDCUseCase struct {
+	userRepo     repositories.UserRepository
+	identityRepo repositories.UserIdentityRepository
+	stateRepo    repositories.OIDCLoginStateRepository
+	providers    map[string]services.IdentityProvider
+	jwtManager   *utils.JWTManager
+}
+
+func NewOIDCUseCase(
+	userRepo repositories.UserRepository,
+	identityRepo repositories.UserIdentityRepository,
+	stateRepo repositories.OIDCLoginStateRepository,
+	providers []services.IdentityProvider,
+	jwtManager *utils.JWTManager,
+) *OIDCUseCase {
+	providerMap := make(map[string]services.IdentityProvider, len(providers))
+	for _, provider := range providers {
+		providerMap[provider.Name()] = provider
+	}
+	return &OIDCUseCase{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		stateRepo:    stateRepo,
+		providers:    providerMap,
+		jwtManager:   jwtManager,
+	}
+}
+
+// HasProviders reports whether any identity provider is configured, i.e.
+// whether users can sign in without a password.
+func (uc *OIDCUseCase) HasProviders() bool {
+	return len(uc.providers) > 0
+}
+
+// StartLogin stores a fresh state, nonce and PKCE verifier and returns the
+// provider URL the user has to be redirected to.
+func (uc *OIDCUseCase) StartLogin(ctx context.Context, providerName string) (string, error) {
+	provider, ok := uc.providers[providerName]
+	if !ok {
+		return "", ErrUnknownIdentityProvider
+	}
+
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = uc.stateRepo.Create(ctx, &entities.OIDCLoginState{
+		State:        state,
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oidcLoginStateTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	return provider.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+}
+
+// CompleteLogin redeems the authorization code, resolves (or creates) the
+// local user and issues the usual API token.
+func (uc *OIDCUseCase) CompleteLogin(ctx context.Context, providerName, state, code string) (string, *entities.UserResponse, error) {
+	provider, ok := uc.providers[providerName]
+	if !ok {
+		return "", nil, ErrUnknownIdentityProvider
+	}
+
+	loginState, err := uc.stateRepo.Consume(ctx, state)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil, ErrInvalidLoginState
+		}
+		return "", nil, err
+	}
+	if loginState.Provider != providerName || time.Now().After(loginState.ExpiresAt) {
+		return "", nil, ErrInvalidLoginState
+	}
+
+	identity, err := provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return "", nil, err
+	}
+
+	user, err := uc.resolveUser(ctx, providerName, identity)
+	if err != nil {
+		return "", nil, err
+	}
+
+	token, err := uc.jwtManager.GenerateJWT(user.ID, user.Email)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, &entities.UserResponse{
+		ID:        user.ID,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		IsAdmin:   user.IsAdmin,
+		CreatedAt: user.CreatedAt,
+	}, nil
+}
+
+// resolveUser finds the user already linked to the external identity. New
+// identities are linked to an existing account by verified email, or a new
+// password-less account is created.
+func (uc *OIDCUseCase) resolveUser(ctx context.Context, providerName string, identity *services.ExternalIdentity) (*entities.User, error) {
+	linked, err := uc.identityRepo.GetByProviderSubject(ctx, providerName, identity.Subject)
+	if err == nil {
+		return uc.userRepo.GetByID(ctx, linked.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	user, err := uc.userRepo.GetByEmail(ctx, identity.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if user == nil {
+		firstName, lastName := identity.GivenName, identity.FamilyName
+		if firstName == "" {
+			firstName, lastName, _ = strings.Cut(identity.Name, " ")
+		}
+		if firstName == "" {
+			firstName, _, _ = strings.Cut(identity.Email, "@")
+		}
+		user = &entities.User{
+			Email:     identity.Email,
+			FirstName: firstName,
+			LastName:  lastName,
+		}
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	err = uc.identityRepo.Create(ctx, &entities.UserIdentity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
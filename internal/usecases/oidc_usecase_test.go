@@ -0,0 +1,335 @@
+package usecases
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"EventsAPI/internal/config"
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/services"
+	"EventsAPI/internal/infrastructure/oidc"
+	"EventsAPI/internal/infrastructure/oidc/oidctest"
+	"EventsAPI/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+type memoryUserRepository struct {
+	users []*entities.User
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *entities.User) error {
+	if existing, _ := r.GetByEmail(ctx, user.Email); existing != nil {
+		return gorm.ErrDuplicatedKey
+	}
+	user.ID = uint(len(r.users) + 1)
+	user.CreatedAt = time.Now()
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id uint) (*entities.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryUserRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *entities.User) error {
+	return nil
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, limit, offset int) ([]*entities.User, error) {
+	return r.users, nil
+}
+
+func (r *memoryUserRepository) GrantAdmin(ctx context.Context, emails []string) (int64, error) {
+	var granted int64
+	for _, user := range r.users {
+		if !user.IsAdmin && slices.Contains(emails, strings.ToLower(user.Email)) {
+			user.IsAdmin = true
+			granted++
+		}
+	}
+	return granted, nil
+}
+
+type memoryUserIdentityRepository struct {
+	identities []*entities.UserIdentity
+}
+
+func (r *memoryUserIdentityRepository) Create(ctx context.Context, identity *entities.UserIdentity) error {
+	identity.ID = uint(len(r.identities) + 1)
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *memoryUserIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*entities.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type memoryOIDCLoginStateRepository struct {
+	states map[string]*entities.OIDCLoginState
+}
+
+func (r *memoryOIDCLoginStateRepository) Create(ctx context.Context, state *entities.OIDCLoginState) error {
+	r.states[state.State] = state
+	return nil
+}
+
+func (r *memoryOIDCLoginStateRepository) Consume(ctx context.Context, state string) (*entities.OIDCLoginState, error) {
+	loginState, ok := r.states[state]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	delete(r.states, state)
+	return loginState, nil
+}
+
+type oidcTest struct {
+	uc         *OIDCUseCase
+	server     *oidctest.Server
+	users      *memoryUserRepository
+	identities *memoryUserIdentityRepository
+	states     *memoryOIDCLoginStateRepository
+}
+
+// newOIDCTest wires the use case to a fake provider named "test" that
+// authenticates Ada with a verified email.
+func newOIDCTest(t *testing.T) *oidcTest {
+	t.Helper()
+	server := oidctest.NewServer("events-api")
+	t.Cleanup(server.Close)
+	server.SetIdentity(services.ExternalIdentity{
+		Subject:       "subject-1",
+		Email:         "ada@example.com",
+		EmailVerified: true,
+		GivenName:     "Ada",
+		FamilyName:    "Lovelace",
+	})
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := utils.NewJWTKey("api", private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtManager, err := utils.NewJWTManager(utils.JWTOptions{SigningKey: key, Expiration: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	test := &oidcTest{
+		server:     server,
+		users:      &memoryUserRepository{},
+		identities: &memoryUserIdentityRepository{},
+		states:     &memoryOIDCLoginStateRepository{states: make(map[string]*entities.OIDCLoginState)},
+	}
+	provider := oidc.NewProvider(config.OIDCProviderConfig{
+		Name:        "test",
+		Issuer:      server.URL,
+		ClientID:    server.ClientID,
+		Scopes:      []string{"openid", "email", "profile"},
+		RedirectURL: "http://localhost/auth/oidc/test/callback",
+	}, server.Client())
+	test.uc = NewOIDCUseCase(test.users, test.identities, test.states, []services.IdentityProvider{provider}, jwtManager)
+	return test
+}
+
+// start begins a login and returns the code and state the provider redirects
+// back with.
+func (test *oidcTest) start(t *testing.T) (code, state string) {
+	t.Helper()
+	authURL, err := test.uc.StartLogin(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+	code, state, err = test.server.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	return code, state
+}
+
+func TestOIDCLoginCreatesUser(t *testing.T) {
+	test := newOIDCTest(t)
+	code, state := test.start(t)
+	verifier := test.states.states[state].CodeVerifier
+
+	token, user, err := test.uc.CompleteLogin(context.Background(), "test", state, code)
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if token == "" {
+		t.Error("CompleteLogin returned an empty token")
+	}
+	if user.Email != "ada@example.com" || user.FirstName != "Ada" || user.LastName != "Lovelace" {
+		t.Errorf("user = %+v, want Ada Lovelace <ada@example.com>", user)
+	}
+	if verifiers := test.server.Verifiers(); len(verifiers) != 1 || verifiers[0] != verifier {
+		t.Errorf("token endpoint received verifiers %q, want [%q]", verifiers, verifier)
+	}
+	if len(test.users.users) != 1 {
+		t.Errorf("%d users were created, want 1", len(test.users.users))
+	}
+	if len(test.identities.identities) != 1 || test.identities.identities[0].UserID != user.ID {
+		t.Errorf("identities = %+v, want one linked to user %d", test.identities.identities, user.ID)
+	}
+	if _, ok := test.states.states[state]; ok {
+		t.Error("the login state was not consumed")
+	}
+}
+
+func TestOIDCLoginLinksExistingUser(t *testing.T) {
+	test := newOIDCTest(t)
+	existing := &entities.User{Email: "Ada@Example.com", FirstName: "Augusta", LastName: "King"}
+	if err := test.users.Create(context.Background(), existing); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		code, state := test.start(t)
+		_, user, err := test.uc.CompleteLogin(context.Background(), "test", state, code)
+		if err != nil {
+			t.Fatalf("CompleteLogin: %v", err)
+		}
+		if user.ID != existing.ID || user.FirstName != "Augusta" {
+			t.Errorf("user = %+v, want the existing user %d", user, existing.ID)
+		}
+	}
+	if len(test.users.users) != 1 {
+		t.Errorf("%d users exist, want only the existing one", len(test.users.users))
+	}
+	if len(test.identities.identities) != 1 {
+		t.Errorf("%d identities were linked, want 1", len(test.identities.identities))
+	}
+}
+
+func TestOIDCLoginRejectsInvalidState(t *testing.T) {
+	tests := []struct {
+		name  string
+		state func(t *testing.T, test *oidcTest, state string) string
+	}{
+		{
+			name:  "missing state",
+			state: func(t *testing.T, test *oidcTest, state string) string { return "" },
+		},
+		{
+			name:  "unknown state",
+			state: func(t *testing.T, test *oidcTest, state string) string { return "forged" },
+		},
+		{
+			name: "expired state",
+			state: func(t *testing.T, test *oidcTest, state string) string {
+				test.states.states[state].ExpiresAt = time.Now().Add(-time.Second)
+				return state
+			},
+		},
+		{
+			name: "state of another provider",
+			state: func(t *testing.T, test *oidcTest, state string) string {
+				test.states.states[state].Provider = "other"
+				return state
+			},
+		},
+		{
+			name: "reused state",
+			state: func(t *testing.T, test *oidcTest, state string) string {
+				if _, err := test.states.Consume(context.Background(), state); err != nil {
+					t.Fatal(err)
+				}
+				return state
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newOIDCTest(t)
+			code, state := test.start(t)
+
+			_, _, err := test.uc.CompleteLogin(context.Background(), "test", tt.state(t, test, state), code)
+			if !errors.Is(err, ErrInvalidLoginState) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidLoginState)
+			}
+			if len(test.server.Verifiers()) != 0 {
+				t.Error("the code was redeemed despite the invalid state")
+			}
+		})
+	}
+}
+
+func TestOIDCLoginRejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(*oidctest.Server)
+		wantErr string
+	}{
+		{
+			name:    "nonce mismatch",
+			setup:   func(s *oidctest.Server) { s.SetNonce("another-nonce") },
+			wantErr: "nonce mismatch",
+		},
+		{
+			name:    "audience mismatch",
+			setup:   func(s *oidctest.Server) { s.SetAudience("another-client") },
+			wantErr: "invalid ID token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newOIDCTest(t)
+			tt.setup(test.server)
+			code, state := test.start(t)
+
+			_, _, err := test.uc.CompleteLogin(context.Background(), "test", state, code)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantErr)
+			}
+			if len(test.users.users) != 0 || len(test.identities.identities) != 0 {
+				t.Error("a user was created or linked from an invalid ID token")
+			}
+		})
+	}
+}
+
+func TestOIDCLoginRequiresVerifiedEmail(t *testing.T) {
+	test := newOIDCTest(t)
+	test.server.SetIdentity(services.ExternalIdentity{Subject: "subject-1", Email: "ada@example.com"})
+	code, state := test.start(t)
+
+	_, _, err := test.uc.CompleteLogin(context.Background(), "test", state, code)
+	if !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("error = %v, want %v", err, ErrEmailNotVerified)
+	}
+	if len(test.users.users) != 0 || len(test.identities.identities) != 0 {
+		t.Error("a user was created or linked from an unverified email")
+	}
+}
@@ -0,0 +1,455 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrderNotFound  = errors.New("order not found")
+	ErrInvalidOrder   = errors.New("invalid order")
+	ErrInvalidWebhook = errors.New("invalid payment webhook")
+)
+
+var orderStatuses = []string{
+	entities.OrderStatusPending, entities.OrderStatusPaid, entities.OrderStatusFailed,
+	entities.OrderStatusExpired, entities.OrderStatusCancelled, entities.OrderStatusUnfulfilled,
+}
+
+// OrderUseCase sells paid tickets: an order reserves the seats of a
+// registration for a while and confirms it once the payment provider reports
+// the payment succeeded.
+type OrderUseCase struct {
+	orderRepo   repositories.OrderRepository
+	eventRepo   repositories.EventRepository
+	attendees   *AttendeeUseCase
+	authorizer  *EventAuthorizer
+	refunds     *RefundUseCase
+	promoCodes  *PromoCodeUseCase
+	invoices    *InvoiceUseCase
+	provider    services.PaymentProvider
+	notifier    services.Notifier
+	reservation time.Duration
+}
+
+func NewOrderUseCase(
+	orderRepo repositories.OrderRepository,
+	eventRepo repositories.EventRepository,
+	attendees *AttendeeUseCase,
+	authorizer *EventAuthorizer,
+	refunds *RefundUseCase,
+	promoCodes *PromoCodeUseCase,
+	invoices *InvoiceUseCase,
+	provider services.PaymentProvider,
+	notifier services.Notifier,
+	reservation time.Duration,
+) *OrderUseCase {
+	return &OrderUseCase{
+		orderRepo:   orderRepo,
+		eventRepo:   eventRepo,
+		attendees:   attendees,
+		authorizer:  authorizer,
+		refunds:     refunds,
+		promoCodes:  promoCodes,
+		invoices:    invoices,
+		provider:    provider,
+		notifier:    notifier,
+		reservation: reservation,
+	}
+}
+
+// Checkout registers the user for the event with a paid ticket type, holding
+// the seats for the user and their guests until the order expires, and
+// creates the payment the user completes at the order's CheckoutURL. The
+// registration is checked like RegisterForEvent's, and the overlapping events
+// are returned as a warning the same way. A promo code in req discounts the
+// order; an order discounted to nothing is confirmed at once without a
+// payment.
+func (uc *OrderUseCase) Checkout(ctx context.Context, eventID, userID uint, inviteToken string, req *entities.AttendeeRequest) (*entities.Order, []*entities.Event, error) {
+	registration, err := uc.attendees.reserveForOrder(ctx, eventID, userID, inviteToken, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attendee, ticketType := registration.attendee, registration.ticketType
+	subtotal := ticketType.Price * int64(attendee.Seats())
+	var promoCode *entities.PromoCode
+	var discount int64
+	if req.PromoCode != "" {
+		promoCode, discount, err = uc.promoCodes.quote(ctx, req.PromoCode, registration.event, ticketType, subtotal, time.Now())
+		if err != nil {
+			if releaseErr := uc.attendees.releaseReservation(ctx, attendee); releaseErr != nil {
+				return nil, nil, errors.Join(err, releaseErr)
+			}
+			return nil, nil, err
+		}
+	}
+
+	order := &entities.Order{
+		EventID:      eventID,
+		UserID:       userID,
+		AttendeeID:   attendee.ID,
+		TicketTypeID: ticketType.ID,
+		Quantity:     attendee.Seats(),
+		UnitPrice:    ticketType.Price,
+		Discount:     discount,
+		Amount:       subtotal - discount,
+		Currency:     ticketType.Currency,
+		Billing:      req.Billing,
+		Status:       entities.OrderStatusPending,
+		ExpiresAt:    time.Now().Add(uc.reservation),
+		Provider:     uc.provider.Name(),
+	}
+	if promoCode != nil {
+		order.PromoCode = promoCode.Code
+	}
+	if err := uc.orderRepo.Create(ctx, order); err != nil {
+		if releaseErr := uc.attendees.releaseReservation(ctx, attendee); releaseErr != nil {
+			return nil, nil, errors.Join(err, releaseErr)
+		}
+		return nil, nil, err
+	}
+
+	if promoCode != nil {
+		if err := uc.promoCodes.redeem(ctx, promoCode, order); err != nil {
+			if _, releaseErr := uc.orderRepo.Release(ctx, order.ID, entities.OrderStatusCancelled); releaseErr != nil {
+				return nil, nil, errors.Join(err, releaseErr)
+			}
+			return nil, nil, err
+		}
+	}
+
+	if order.Amount == 0 {
+		if _, err := uc.orderRepo.ConfirmPayment(ctx, order.ID, time.Now()); err != nil {
+			return nil, nil, err
+		}
+		order, err = uc.orderRepo.GetByID(ctx, order.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := uc.orderConfirmed(ctx, order); err != nil {
+			return nil, nil, err
+		}
+		return order, registration.conflicts, nil
+	}
+
+	payment, err := uc.provider.CreatePayment(ctx, services.PaymentRequest{
+		Reference:   paymentReference(order.ID),
+		Amount:      order.Amount,
+		Currency:    order.Currency,
+		Description: fmt.Sprintf("%d × %s - %s", order.Quantity, ticketType.Name, registration.event.Title),
+	})
+	if err != nil {
+		if _, releaseErr := uc.orderRepo.Release(ctx, order.ID, entities.OrderStatusFailed); releaseErr != nil {
+			return nil, nil, errors.Join(err, releaseErr)
+		}
+		return nil, nil, err
+	}
+
+	// Without its payment ID the buyer can't pay the order, so its seats are
+	// released. A payment made anyway is found by its reference and handled
+	// like a late payment.
+	if _, err := uc.orderRepo.SetPayment(ctx, order.ID, payment.ID, payment.CheckoutURL); err != nil {
+		if _, releaseErr := uc.orderRepo.Release(ctx, order.ID, entities.OrderStatusFailed); releaseErr != nil {
+			return nil, nil, errors.Join(err, releaseErr)
+		}
+		return nil, nil, err
+	}
+	order.PaymentID = payment.ID
+	order.CheckoutURL = payment.CheckoutURL
+
+	order.Event = *registration.event
+	order.TicketType = *ticketType
+	return order, registration.conflicts, nil
+}
+
+// GetOrder returns an order to its buyer or to the event's organizers.
+func (uc *OrderUseCase) GetOrder(ctx context.Context, userID, orderID uint) (*entities.Order, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if order.UserID == userID {
+		return order, nil
+	}
+
+	allowed, err := uc.authorizer.Can(ctx, &order.Event, userID, entities.PermissionViewOrders)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (uc *OrderUseCase) ListMyOrders(ctx context.Context, userID uint, limit, offset int) ([]*entities.Order, error) {
+	return uc.orderRepo.GetByUserID(ctx, userID, limit, offset)
+}
+
+// CancelOrder lets the buyer give up a pending order, releasing its seats.
+// A payment that still succeeds afterwards is handled like a late payment.
+func (uc *OrderUseCase) CancelOrder(ctx context.Context, userID, orderID uint) (*entities.Order, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil || order.UserID != userID {
+		if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	released, err := uc.orderRepo.Release(ctx, order.ID, entities.OrderStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	if !released {
+		return nil, fmt.Errorf("%w: only pending orders can be cancelled", ErrInvalidOrder)
+	}
+	order.Status = entities.OrderStatusCancelled
+	return order, nil
+}
+
+// OrderInvoice renders the invoice of a paid order, followed by the credit
+// notes of its refunds, for its buyer or the event's organizers. It returns
+// the document and its content type.
+func (uc *OrderUseCase) OrderInvoice(ctx context.Context, userID, orderID uint) ([]byte, string, error) {
+	order, err := uc.GetOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+	return uc.invoices.render(ctx, order)
+}
+
+// ListEventOrders returns the event's orders to its organizers, optionally
+// only the ones with the given status.
+func (uc *OrderUseCase) ListEventOrders(ctx context.Context, userID, eventID uint, status string, limit, offset int) ([]*entities.Order, error) {
+	if status != "" && !slices.Contains(orderStatuses, status) {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidOrder, status)
+	}
+	if err := uc.authorizeEvent(ctx, userID, eventID); err != nil {
+		return nil, err
+	}
+	return uc.orderRepo.GetByEventID(ctx, eventID, status, limit, offset)
+}
+
+// EventRevenue sums the event's paid orders, in total and per ticket type.
+func (uc *OrderUseCase) EventRevenue(ctx context.Context, userID, eventID uint) (*entities.EventRevenueResponse, error) {
+	if err := uc.authorizeEvent(ctx, userID, eventID); err != nil {
+		return nil, err
+	}
+
+	byTicketType, err := uc.orderRepo.RevenueByTicketType(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	revenue := &entities.EventRevenueResponse{EventID: eventID, Currency: entities.DefaultCurrency, ByTicketType: byTicketType}
+	for _, row := range byTicketType {
+		revenue.Currency = row.Currency
+		revenue.Orders += row.Orders
+		revenue.TicketsSold += row.TicketsSold
+		revenue.Amount += row.Amount
+		revenue.Refunded += row.Refunded
+	}
+	return revenue, nil
+}
+
+// HandlePaymentWebhook applies a payment event reported by the provider. It
+// is idempotent, since providers deliver webhooks at least once: a success
+// confirms the order's registration only the first time, and a failure
+// releases the order only while it is pending. A success for an order whose
+// reservation was already released confirms the registration if the seats
+// are still available; otherwise the order is left unfulfilled and refunded.
+// Notifications with a refund ID report the outcome of a refund.
+func (uc *OrderUseCase) HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := uc.provider.ParseWebhook(payload, signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	if event.RefundID != "" {
+		return uc.refunds.handleRefundEvent(ctx, event)
+	}
+
+	order, err := uc.orderForPayment(ctx, event)
+	if err != nil {
+		return err
+	}
+	if event.Amount != order.Amount || !strings.EqualFold(event.Currency, order.Currency) {
+		return fmt.Errorf("%w: the payment doesn't match the order amount", ErrInvalidWebhook)
+	}
+
+	switch event.Status {
+	case services.PaymentSucceeded:
+		return uc.paymentSucceeded(ctx, order, time.Now())
+	case services.PaymentFailed:
+		released, err := uc.orderRepo.Release(ctx, order.ID, entities.OrderStatusFailed)
+		if err != nil || !released {
+			return err
+		}
+		return uc.notifyBuyer(ctx, order,
+			fmt.Sprintf("Your payment for %s failed", order.Event.Title),
+			fmt.Sprintf("The payment of your order #%d for %s failed and your seats were released.", order.ID, order.Event.Title))
+	default:
+		return fmt.Errorf("%w: unknown payment status %q", ErrInvalidWebhook, event.Status)
+	}
+}
+
+// orderForPayment finds the order of the webhook's payment. An order whose
+// payment couldn't be recorded at checkout is found by the payment's
+// reference instead, and the payment is recorded then.
+func (uc *OrderUseCase) orderForPayment(ctx context.Context, event *services.PaymentEvent) (*entities.Order, error) {
+	order, err := uc.orderRepo.GetByPaymentID(ctx, uc.provider.Name(), event.PaymentID)
+	if err == nil {
+		return order, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	orderID, ok := orderIDFromReference(event.Reference)
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	order, err = uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if order.Provider != uc.provider.Name() {
+		return nil, ErrOrderNotFound
+	}
+	recorded, err := uc.orderRepo.SetPayment(ctx, order.ID, event.PaymentID, "")
+	if err != nil {
+		return nil, err
+	}
+	if !recorded {
+		// The order is paid by another payment.
+		return nil, ErrOrderNotFound
+	}
+	order.PaymentID = event.PaymentID
+	return order, nil
+}
+
+// paymentReference identifies an order on the payment provider's side.
+func paymentReference(orderID uint) string {
+	return fmt.Sprintf("order-%d", orderID)
+}
+
+func orderIDFromReference(reference string) (uint, bool) {
+	id, ok := strings.CutPrefix(reference, "order-")
+	if !ok {
+		return 0, false
+	}
+	orderID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || orderID == 0 {
+		return 0, false
+	}
+	return uint(orderID), true
+}
+
+func (uc *OrderUseCase) paymentSucceeded(ctx context.Context, order *entities.Order, paidAt time.Time) error {
+	confirmed, err := uc.orderRepo.ConfirmPayment(ctx, order.ID, paidAt)
+	if err != nil {
+		return err
+	}
+	if confirmed {
+		return uc.orderConfirmed(ctx, order)
+	}
+
+	// The order was no longer pending: either this is a repeated webhook or
+	// the reservation was released before the payment went through.
+	current, err := uc.orderRepo.GetByID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	switch current.Status {
+	case entities.OrderStatusExpired, entities.OrderStatusCancelled, entities.OrderStatusFailed:
+	default:
+		return nil
+	}
+
+	claimed, err := uc.orderRepo.MarkPaid(ctx, current.ID, current.Status, paidAt)
+	if err != nil || !claimed {
+		return err
+	}
+
+	err = ErrEventNotFound
+	if !current.Event.DeletedAt.Valid {
+		err = uc.attendees.confirmLatePayment(ctx, current)
+	}
+	switch {
+	case err == nil:
+		return uc.orderConfirmed(ctx, current)
+	case errors.Is(err, ErrEventFull) || errors.Is(err, ErrTicketSoldOut) || errors.Is(err, ErrEventNotFound):
+		if _, err := uc.orderRepo.UpdateStatus(ctx, current.ID, entities.OrderStatusPaid, entities.OrderStatusUnfulfilled); err != nil {
+			return err
+		}
+		err := uc.notifyBuyer(ctx, current,
+			fmt.Sprintf("Your order for %s could not be fulfilled", current.Event.Title),
+			fmt.Sprintf("Your payment for order #%d arrived after its reservation was released and there is no seat left for you at %s. The payment will be refunded.", current.ID, current.Event.Title))
+		if err != nil {
+			return err
+		}
+		return uc.refunds.RefundUnfulfilled(ctx, current)
+	default:
+		// Put the order back so the provider's retry gets another chance.
+		if _, revertErr := uc.orderRepo.UpdateStatus(ctx, current.ID, entities.OrderStatusPaid, current.Status); revertErr != nil {
+			return errors.Join(err, revertErr)
+		}
+		return err
+	}
+}
+
+// ExpireReservations releases the seats of the pending orders that expired by
+// now and returns how many orders expired.
+func (uc *OrderUseCase) ExpireReservations(ctx context.Context, now time.Time) (int, error) {
+	return uc.orderRepo.ExpirePending(ctx, now)
+}
+
+func (uc *OrderUseCase) authorizeEvent(ctx context.Context, userID, eventID uint) error {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return ErrEventNotFound
+	}
+	return uc.authorizer.Authorize(ctx, event, userID, entities.PermissionViewOrders)
+}
+
+// orderConfirmed issues the invoice of an order that was just paid and lets
+// the buyer know.
+func (uc *OrderUseCase) orderConfirmed(ctx context.Context, order *entities.Order) error {
+	if _, err := uc.invoices.issue(ctx, order.ID); err != nil {
+		return err
+	}
+	return uc.notifyConfirmed(ctx, order)
+}
+
+func (uc *OrderUseCase) notifyConfirmed(ctx context.Context, order *entities.Order) error {
+	return uc.notifyBuyer(ctx, order,
+		fmt.Sprintf("Your order for %s is confirmed", order.Event.Title),
+		fmt.Sprintf("We received the payment of your order #%d. You are confirmed for %s on %s.", order.ID, order.Event.Title, order.Event.DateTime.Format(time.RFC1123)))
+}
+
+func (uc *OrderUseCase) notifyBuyer(ctx context.Context, order *entities.Order, subject, body string) error {
+	return uc.notifier.Notify(ctx, services.Notification{
+		To:      order.User.Email,
+		Subject: subject,
+		Body:    body,
+	})
+}
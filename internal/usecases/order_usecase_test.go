@@ -0,0 +1,338 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/services"
+	"EventsAPI/internal/infrastructure/payments"
+)
+
+const testReservation = 15 * time.Minute
+
+// testPaymentProvider is the fake provider, with refunds failing while
+// refundErr is set.
+type testPaymentProvider struct {
+	*payments.FakeProvider
+	refundErr error
+}
+
+func (p *testPaymentProvider) Refund(ctx context.Context, req services.RefundRequest) (*services.RefundResult, error) {
+	if p.refundErr != nil {
+		return nil, p.refundErr
+	}
+	return p.FakeProvider.Refund(ctx, req)
+}
+
+// orderFixture wires the order use case to in-memory repositories and the
+// fake payment provider. Its event sells a single 25 EUR ticket, refunded in
+// full until it starts.
+type orderFixture struct {
+	store      *memoryStore
+	orders     *memoryOrderRepository
+	refunds    *memoryRefundRepository
+	provider   *testPaymentProvider
+	notifier   *recordingNotifier
+	attendees  *AttendeeUseCase
+	uc         *OrderUseCase
+	event      entities.Event
+	ticketType entities.TicketType
+}
+
+func newOrderFixture(t *testing.T) *orderFixture {
+	t.Helper()
+	ctx := context.Background()
+	store := newMemoryStore()
+	f := &orderFixture{
+		store:    store,
+		orders:   &memoryOrderRepository{store: store},
+		refunds:  &memoryRefundRepository{store: store},
+		provider: &testPaymentProvider{FakeProvider: payments.NewFakeProvider("secret")},
+		notifier: &recordingNotifier{},
+	}
+
+	organizer := &entities.User{Email: "organizer@example.com", FirstName: "Olga"}
+	if err := store.users.Create(ctx, organizer); err != nil {
+		t.Fatal(err)
+	}
+	f.event = entities.Event{
+		ID:           store.newID(),
+		Title:        "Go meetup",
+		DateTime:     time.Now().Add(30 * 24 * time.Hour),
+		UserID:       organizer.ID,
+		Visibility:   entities.EventVisibilityPublic,
+		RefundPolicy: []entities.RefundRule{{DaysBefore: 0, Percent: 100}},
+	}
+	store.events[f.event.ID] = f.event
+	f.ticketType = entities.TicketType{
+		ID:          store.newID(),
+		EventID:     f.event.ID,
+		Name:        "General",
+		Price:       2500,
+		Currency:    "EUR",
+		Quantity:    1,
+		MinPerOrder: 1,
+	}
+	store.ticketTypes[f.ticketType.ID] = f.ticketType
+
+	eventRepo := &memoryEventRepository{store: store}
+	attendeeRepo := &memoryAttendeeRepository{store: store}
+	authorizer := NewEventAuthorizer(nil, nil)
+	invitations := NewEventInvitationUseCase(nil, nil, eventRepo, store.users, attendeeRepo, authorizer, nil, f.notifier)
+	form := NewRegistrationFormUseCase(&memoryRegistrationQuestionRepository{}, eventRepo, authorizer, invitations)
+	tickets := NewTicketTypeUseCase(&memoryTicketTypeRepository{store: store}, attendeeRepo, eventRepo, authorizer, invitations, nil)
+	invoices := NewInvoiceUseCase(&memoryInvoiceRepository{store: store}, f.orders, nil, store.users, nil)
+	refunds := NewRefundUseCase(f.refunds, f.orders, authorizer, invoices, f.provider, f.notifier)
+	promoCodes := NewPromoCodeUseCase(&memoryPromoCodeRepository{store: store}, eventRepo, tickets, authorizer)
+	f.attendees = NewAttendeeUseCase(attendeeRepo, eventRepo, authorizer, invitations, form, tickets, refunds, f.notifier)
+	f.uc = NewOrderUseCase(f.orders, eventRepo, f.attendees, authorizer, refunds, promoCodes, invoices, f.provider, f.notifier, testReservation)
+	return f
+}
+
+func (f *orderFixture) newUser(t *testing.T, email string) uint {
+	t.Helper()
+	user := &entities.User{Email: email}
+	if err := f.store.users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user.ID
+}
+
+func (f *orderFixture) checkout(userID uint, promoCode string) (*entities.Order, error) {
+	order, _, err := f.uc.Checkout(context.Background(), f.event.ID, userID, "", &entities.AttendeeRequest{
+		TicketTypeID: &f.ticketType.ID,
+		PromoCode:    promoCode,
+	})
+	return order, err
+}
+
+// pay delivers a signed webhook reporting the payment's status, the way the
+// provider would.
+func (f *orderFixture) pay(paymentID, reference, status string, amount int64) error {
+	payload, err := json.Marshal(map[string]any{
+		"payment_id": paymentID,
+		"reference":  reference,
+		"status":     status,
+		"amount":     amount,
+		"currency":   "EUR",
+	})
+	if err != nil {
+		return err
+	}
+	return f.uc.HandlePaymentWebhook(context.Background(), payload, f.provider.Sign(payload))
+}
+
+func (f *orderFixture) assertOrder(t *testing.T, orderID uint, status string) {
+	t.Helper()
+	if got := f.store.orders[orderID].Status; got != status {
+		t.Errorf("order status = %q, want %q", got, status)
+	}
+}
+
+func (f *orderFixture) assertAttendee(t *testing.T, attendeeID uint, status string) {
+	t.Helper()
+	if got := f.store.attendees[attendeeID].Status; got != status {
+		t.Errorf("attendee status = %q, want %q", got, status)
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	f := newOrderFixture(t)
+	buyer := f.newUser(t, "buyer@example.com")
+
+	order, err := f.checkout(buyer, "")
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if order.PaymentID == "" || order.CheckoutURL == "" {
+		t.Errorf("order payment = %q at %q, want a payment to complete", order.PaymentID, order.CheckoutURL)
+	}
+	if order.Amount != 2500 || order.Quantity != 1 || order.Provider != "fake" {
+		t.Errorf("order = %d × %d via %q, want 1 × 2500 via fake", order.Quantity, order.Amount, order.Provider)
+	}
+	if saved := f.store.orders[order.ID]; saved.PaymentID != order.PaymentID {
+		t.Errorf("saved payment ID = %q, want %q", saved.PaymentID, order.PaymentID)
+	}
+	f.assertOrder(t, order.ID, entities.OrderStatusPending)
+	f.assertAttendee(t, order.AttendeeID, entities.AttendeeStatusAwaitingPayment)
+
+	if _, err := f.checkout(buyer, ""); !errors.Is(err, ErrPaymentPending) {
+		t.Errorf("second checkout error = %v, want ErrPaymentPending", err)
+	}
+	if _, err := f.checkout(f.newUser(t, "other@example.com"), ""); !errors.Is(err, ErrTicketSoldOut) {
+		t.Errorf("checkout of the held ticket error = %v, want ErrTicketSoldOut", err)
+	}
+}
+
+func TestPaymentWebhookIsIdempotent(t *testing.T) {
+	f := newOrderFixture(t)
+	order, err := f.checkout(f.newUser(t, "buyer@example.com"), "")
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	if err := f.pay(order.PaymentID, "", services.PaymentSucceeded, 1000); !errors.Is(err, ErrInvalidWebhook) {
+		t.Errorf("webhook with the wrong amount error = %v, want ErrInvalidWebhook", err)
+	}
+	if err := f.uc.HandlePaymentWebhook(context.Background(), []byte(`{}`), "bad"); !errors.Is(err, ErrInvalidWebhook) {
+		t.Errorf("unsigned webhook error = %v, want ErrInvalidWebhook", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := f.pay(order.PaymentID, "", services.PaymentSucceeded, order.Amount); err != nil {
+			t.Fatalf("delivery %d: %v", i+1, err)
+		}
+	}
+	f.assertOrder(t, order.ID, entities.OrderStatusPaid)
+	f.assertAttendee(t, order.AttendeeID, entities.AttendeeStatusConfirmed)
+	if len(f.store.invoices) != 1 {
+		t.Errorf("%d invoices issued, want 1", len(f.store.invoices))
+	}
+	if len(f.notifier.sent) != 1 {
+		t.Errorf("%d notifications sent, want 1", len(f.notifier.sent))
+	}
+
+	// A failure reported after the success doesn't undo it.
+	if err := f.pay(order.PaymentID, "", services.PaymentFailed, order.Amount); err != nil {
+		t.Fatalf("failed payment: %v", err)
+	}
+	f.assertOrder(t, order.ID, entities.OrderStatusPaid)
+}
+
+func TestPaymentWebhookFailedReleasesSeats(t *testing.T) {
+	f := newOrderFixture(t)
+	order, err := f.checkout(f.newUser(t, "buyer@example.com"), "")
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	if err := f.pay(order.PaymentID, "", services.PaymentFailed, order.Amount); err != nil {
+		t.Fatalf("failed payment: %v", err)
+	}
+	f.assertOrder(t, order.ID, entities.OrderStatusFailed)
+	f.assertAttendee(t, order.AttendeeID, entities.AttendeeStatusCancelled)
+
+	if _, err := f.checkout(f.newUser(t, "other@example.com"), ""); err != nil {
+		t.Errorf("checkout of the released ticket: %v", err)
+	}
+}
+
+func TestExpireReservations(t *testing.T) {
+	f := newOrderFixture(t)
+	order, err := f.checkout(f.newUser(t, "buyer@example.com"), "")
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	if expired, err := f.uc.ExpireReservations(context.Background(), time.Now()); err != nil || expired != 0 {
+		t.Errorf("ExpireReservations before the deadline = %d, %v; want 0, nil", expired, err)
+	}
+	later := time.Now().Add(testReservation + time.Minute)
+	if expired, err := f.uc.ExpireReservations(context.Background(), later); err != nil || expired != 1 {
+		t.Errorf("ExpireReservations = %d, %v; want 1, nil", expired, err)
+	}
+	if expired, err := f.uc.ExpireReservations(context.Background(), later); err != nil || expired != 0 {
+		t.Errorf("second ExpireReservations = %d, %v; want 0, nil", expired, err)
+	}
+	f.assertOrder(t, order.ID, entities.OrderStatusExpired)
+	f.assertAttendee(t, order.AttendeeID, entities.AttendeeStatusCancelled)
+}
+
+func TestLatePayment(t *testing.T) {
+	t.Run("seats left", func(t *testing.T) {
+		f := newOrderFixture(t)
+		order, err := f.checkout(f.newUser(t, "buyer@example.com"), "")
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		if _, err := f.uc.ExpireReservations(context.Background(), time.Now().Add(testReservation+time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if err := f.pay(order.PaymentID, "", services.PaymentSucceeded, order.Amount); err != nil {
+				t.Fatalf("delivery %d: %v", i+1, err)
+			}
+		}
+		f.assertOrder(t, order.ID, entities.OrderStatusPaid)
+		f.assertAttendee(t, order.AttendeeID, entities.AttendeeStatusConfirmed)
+		if len(f.store.invoices) != 1 || len(f.notifier.sent) != 1 {
+			t.Errorf("%d invoices and %d notifications, want 1 each", len(f.store.invoices), len(f.notifier.sent))
+		}
+	})
+
+	t.Run("sold out", func(t *testing.T) {
+		f := newOrderFixture(t)
+		order, err := f.checkout(f.newUser(t, "buyer@example.com"), "")
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		if _, err := f.uc.ExpireReservations(context.Background(), time.Now().Add(testReservation+time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		// Someone else takes the released seat before the payment arrives.
+		if _, err := f.checkout(f.newUser(t, "other@example.com"), ""); err != nil {
+			t.Fatalf("Checkout of the released seat: %v", err)
+		}
+
+		if err := f.pay(order.PaymentID, "", services.PaymentSucceeded, order.Amount); err != nil {
+			t.Fatalf("late payment: %v", err)
+		}
+		f.assertOrder(t, order.ID, entities.OrderStatusUnfulfilled)
+		f.assertAttendee(t, order.AttendeeID, entities.AttendeeStatusCancelled)
+		if saved := f.store.orders[order.ID]; saved.RefundStatus != entities.OrderRefundRefunded || saved.RefundedAmount != order.Amount {
+			t.Errorf("order refund = %q %d, want %q %d", saved.RefundStatus, saved.RefundedAmount, entities.OrderRefundRefunded, order.Amount)
+		}
+		// The invoice and its credit note.
+		if len(f.store.invoices) != 2 {
+			t.Errorf("%d invoices issued, want 2", len(f.store.invoices))
+		}
+
+		if err := f.pay(order.PaymentID, "", services.PaymentSucceeded, order.Amount); err != nil {
+			t.Fatalf("repeated late payment: %v", err)
+		}
+		if len(f.store.refunds) != 1 {
+			t.Errorf("%d refunds, want 1", len(f.store.refunds))
+		}
+	})
+}
+
+func TestPaymentWebhookFindsOrderByReference(t *testing.T) {
+	f := newOrderFixture(t)
+	f.orders.failSetPayment = errors.New("connection lost")
+	if _, err := f.checkout(f.newUser(t, "buyer@example.com"), ""); err == nil {
+		t.Fatal("Checkout succeeded without recording the payment")
+	}
+	f.orders.failSetPayment = nil
+
+	if len(f.store.orders) != 1 {
+		t.Fatalf("%d orders created, want 1", len(f.store.orders))
+	}
+	var order entities.Order
+	for _, order = range f.store.orders {
+		break
+	}
+	f.assertOrder(t, order.ID, entities.OrderStatusFailed)
+	f.assertAttendee(t, order.AttendeeID, entities.AttendeeStatusCancelled)
+
+	if err := f.pay("fake_lost", "order-999", services.PaymentSucceeded, order.Amount); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("webhook for an unknown reference error = %v, want ErrOrderNotFound", err)
+	}
+	if err := f.pay("fake_lost", paymentReference(order.ID), services.PaymentSucceeded, order.Amount); err != nil {
+		t.Fatalf("webhook by reference: %v", err)
+	}
+	f.assertOrder(t, order.ID, entities.OrderStatusPaid)
+	f.assertAttendee(t, order.AttendeeID, entities.AttendeeStatusConfirmed)
+	if got := f.store.orders[order.ID].PaymentID; got != "fake_lost" {
+		t.Errorf("payment ID = %q, want fake_lost", got)
+	}
+
+	// Another payment for the same order isn't taken for it.
+	if err := f.pay("fake_other", paymentReference(order.ID), services.PaymentSucceeded, order.Amount); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("second payment by reference error = %v, want ErrOrderNotFound", err)
+	}
+}
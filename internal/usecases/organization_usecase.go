@@ -0,0 +1,386 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
+	"EventsAPI/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const organizationInvitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrMemberNotFound       = errors.New("member not found")
+	ErrAlreadyMember        = errors.New("user is already a member of the organization")
+	ErrInvalidInvitation    = errors.New("invalid or expired invitation")
+	ErrOwnerRoleChange      = errors.New("the owner's membership can only change through an ownership transfer")
+)
+
+type OrganizationUseCase struct {
+	organizationRepo repositories.OrganizationRepository
+	invitationRepo   repositories.OrganizationInvitationRepository
+	eventRepo        repositories.EventRepository
+	userRepo         repositories.UserRepository
+	notifier         services.Notifier
+}
+
+func NewOrganizationUseCase(
+	organizationRepo repositories.OrganizationRepository,
+	invitationRepo repositories.OrganizationInvitationRepository,
+	eventRepo repositories.EventRepository,
+	userRepo repositories.UserRepository,
+	notifier services.Notifier,
+) *OrganizationUseCase {
+	return &OrganizationUseCase{
+		organizationRepo: organizationRepo,
+		invitationRepo:   invitationRepo,
+		eventRepo:        eventRepo,
+		userRepo:         userRepo,
+		notifier:         notifier,
+	}
+}
+
+// CreateOrganization creates an organization owned by userID.
+func (uc *OrganizationUseCase) CreateOrganization(ctx context.Context, userID uint, req *entities.OrganizationRequest) (*entities.OrganizationResponse, error) {
+	organization := &entities.Organization{Name: req.Name}
+	if err := uc.organizationRepo.Create(ctx, organization, userID); err != nil {
+		return nil, err
+	}
+
+	return &entities.OrganizationResponse{
+		ID:        organization.ID,
+		Name:      organization.Name,
+		Role:      entities.OrganizationRoleOwner,
+		CreatedAt: organization.CreatedAt,
+	}, nil
+}
+
+func (uc *OrganizationUseCase) ListMyOrganizations(ctx context.Context, userID uint) ([]entities.OrganizationResponse, error) {
+	memberships, err := uc.organizationRepo.GetMembershipsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entities.OrganizationResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		response = append(response, entities.OrganizationResponse{
+			ID:        membership.Organization.ID,
+			Name:      membership.Organization.Name,
+			Role:      membership.Role,
+			CreatedAt: membership.Organization.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// GetOrganization returns the organization if userID is one of its members.
+func (uc *OrganizationUseCase) GetOrganization(ctx context.Context, userID, organizationID uint) (*entities.OrganizationResponse, error) {
+	member, err := uc.requireMember(ctx, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	organization, err := uc.organizationRepo.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOrganizationResponse(organization, member.Role), nil
+}
+
+// UpdateBilling sets how the organization appears as the seller on the
+// invoices of its events. Invoices already issued keep the previous details.
+func (uc *OrganizationUseCase) UpdateBilling(ctx context.Context, userID, organizationID uint, req *entities.OrganizationBillingRequest) (*entities.OrganizationResponse, error) {
+	member, err := uc.requirePermission(ctx, organizationID, userID, entities.PermissionManageBilling)
+	if err != nil {
+		return nil, err
+	}
+
+	organization, err := uc.organizationRepo.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	organization.LegalName = strings.TrimSpace(req.LegalName)
+	organization.TaxID = strings.TrimSpace(req.TaxID)
+	organization.BillingAddress = strings.TrimSpace(req.BillingAddress)
+	if err := uc.organizationRepo.Update(ctx, organization); err != nil {
+		return nil, err
+	}
+	return newOrganizationResponse(organization, member.Role), nil
+}
+
+func (uc *OrganizationUseCase) ListMembers(ctx context.Context, userID, organizationID uint) ([]entities.OrganizationMemberResponse, error) {
+	if _, err := uc.requireMember(ctx, organizationID, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := uc.organizationRepo.GetMembers(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entities.OrganizationMemberResponse, 0, len(members))
+	for _, member := range members {
+		response = append(response, entities.OrganizationMemberResponse{
+			UserID:    member.UserID,
+			Email:     member.User.Email,
+			FirstName: member.User.FirstName,
+			LastName:  member.User.LastName,
+			Role:      member.Role,
+		})
+	}
+	return response, nil
+}
+
+// ListEvents returns the organization's events to its members.
+func (uc *OrganizationUseCase) ListEvents(ctx context.Context, userID, organizationID uint, limit, offset int) ([]*entities.Event, error) {
+	if _, err := uc.requireMember(ctx, organizationID, userID); err != nil {
+		return nil, err
+	}
+	return uc.eventRepo.GetByOrganizationID(ctx, organizationID, limit, offset)
+}
+
+// UpdateMemberRole changes a member's role. Only the owner may grant or
+// revoke the admin role.
+func (uc *OrganizationUseCase) UpdateMemberRole(ctx context.Context, userID, organizationID, memberUserID uint, role string) error {
+	caller, err := uc.requirePermission(ctx, organizationID, userID, entities.PermissionManageMembers)
+	if err != nil {
+		return err
+	}
+
+	member, err := uc.getMember(ctx, organizationID, memberUserID)
+	if err != nil {
+		return err
+	}
+	if member.Role == entities.OrganizationRoleOwner {
+		return ErrOwnerRoleChange
+	}
+	if (member.Role == entities.OrganizationRoleAdmin || role == entities.OrganizationRoleAdmin) && caller.Role != entities.OrganizationRoleOwner {
+		return ErrForbidden
+	}
+
+	return uc.organizationRepo.UpdateMemberRole(ctx, organizationID, memberUserID, role)
+}
+
+// RemoveMember removes a member. Members may always leave on their own, except
+// for the owner, who has to transfer ownership first.
+func (uc *OrganizationUseCase) RemoveMember(ctx context.Context, userID, organizationID, memberUserID uint) error {
+	caller, err := uc.requireMember(ctx, organizationID, userID)
+	if err != nil {
+		return err
+	}
+
+	member, err := uc.getMember(ctx, organizationID, memberUserID)
+	if err != nil {
+		return err
+	}
+	if member.Role == entities.OrganizationRoleOwner {
+		return ErrOwnerRoleChange
+	}
+
+	if userID != memberUserID {
+		if !entities.OrganizationRoleHasPermission(caller.Role, entities.PermissionManageMembers) {
+			return ErrForbidden
+		}
+		if member.Role == entities.OrganizationRoleAdmin && caller.Role != entities.OrganizationRoleOwner {
+			return ErrForbidden
+		}
+	}
+
+	return uc.organizationRepo.RemoveMember(ctx, organizationID, memberUserID)
+}
+
+// InviteMember emails an invitation token to join the organization.
+func (uc *OrganizationUseCase) InviteMember(ctx context.Context, userID, organizationID uint, req *entities.OrganizationInvitationRequest) (*entities.OrganizationInvitationResponse, error) {
+	caller, err := uc.requirePermission(ctx, organizationID, userID, entities.PermissionManageMembers)
+	if err != nil {
+		return nil, err
+	}
+	if req.Role == entities.OrganizationRoleAdmin && caller.Role != entities.OrganizationRoleOwner {
+		return nil, ErrForbidden
+	}
+
+	email := strings.ToLower(req.Email)
+	invitee, err := uc.userRepo.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if invitee != nil {
+		if _, err := uc.getMember(ctx, organizationID, invitee.ID); err == nil {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	organization, err := uc.organizationRepo.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &entities.OrganizationInvitation{
+		OrganizationID: organizationID,
+		Email:          email,
+		Role:           req.Role,
+		TokenHash:      utils.HashToken(token),
+		InvitedByID:    userID,
+		ExpiresAt:      time.Now().Add(organizationInvitationTTL),
+	}
+	if err := uc.invitationRepo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	err = uc.notifier.Notify(ctx, services.Notification{
+		To:      email,
+		Subject: fmt.Sprintf("You have been invited to join %s", organization.Name),
+		Body:    fmt.Sprintf("You have been invited to join %s as %s. Accept the invitation with this token: %s", organization.Name, req.Role, token),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.OrganizationInvitationResponse{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		ExpiresAt: invitation.ExpiresAt,
+	}, nil
+}
+
+func (uc *OrganizationUseCase) ListInvitations(ctx context.Context, userID, organizationID uint) ([]entities.OrganizationInvitationResponse, error) {
+	if _, err := uc.requirePermission(ctx, organizationID, userID, entities.PermissionManageMembers); err != nil {
+		return nil, err
+	}
+
+	invitations, err := uc.invitationRepo.GetPendingByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entities.OrganizationInvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		response = append(response, entities.OrganizationInvitationResponse{
+			ID:        invitation.ID,
+			Email:     invitation.Email,
+			Role:      invitation.Role,
+			ExpiresAt: invitation.ExpiresAt,
+		})
+	}
+	return response, nil
+}
+
+// AcceptInvitation adds userID to the organization. The invitation must have
+// been sent to the user's email address.
+func (uc *OrganizationUseCase) AcceptInvitation(ctx context.Context, userID uint, token string) (*entities.OrganizationResponse, error) {
+	invitation, err := uc.invitationRepo.GetByTokenHash(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil || time.Now().After(invitation.ExpiresAt) {
+		return nil, ErrInvalidInvitation
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvalidInvitation
+	}
+
+	if _, err := uc.getMember(ctx, invitation.OrganizationID, userID); err == nil {
+		return nil, ErrAlreadyMember
+	}
+
+	err = uc.organizationRepo.AddMember(ctx, &entities.OrganizationMember{
+		OrganizationID: invitation.OrganizationID,
+		UserID:         userID,
+		Role:           invitation.Role,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.invitationRepo.MarkAccepted(ctx, invitation.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return uc.GetOrganization(ctx, userID, invitation.OrganizationID)
+}
+
+// TransferOwnership hands the organization over to another member. The
+// previous owner stays on as admin.
+func (uc *OrganizationUseCase) TransferOwnership(ctx context.Context, userID, organizationID, newOwnerID uint) error {
+	if _, err := uc.requirePermission(ctx, organizationID, userID, entities.PermissionTransferOwnership); err != nil {
+		return err
+	}
+	if _, err := uc.getMember(ctx, organizationID, newOwnerID); err != nil {
+		return err
+	}
+	if newOwnerID == userID {
+		return nil
+	}
+
+	return uc.organizationRepo.TransferOwnership(ctx, organizationID, userID, newOwnerID)
+}
+
+// requireMember returns the caller's membership. Non members get
+// ErrOrganizationNotFound so organizations can't be probed.
+func (uc *OrganizationUseCase) requireMember(ctx context.Context, organizationID, userID uint) (*entities.OrganizationMember, error) {
+	member, err := uc.organizationRepo.GetMember(ctx, organizationID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+func (uc *OrganizationUseCase) requirePermission(ctx context.Context, organizationID, userID uint, permission entities.Permission) (*entities.OrganizationMember, error) {
+	member, err := uc.requireMember(ctx, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !entities.OrganizationRoleHasPermission(member.Role, permission) {
+		return nil, ErrForbidden
+	}
+	return member, nil
+}
+
+func (uc *OrganizationUseCase) getMember(ctx context.Context, organizationID, userID uint) (*entities.OrganizationMember, error) {
+	member, err := uc.organizationRepo.GetMember(ctx, organizationID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+func newOrganizationResponse(organization *entities.Organization, role string) *entities.OrganizationResponse {
+	return &entities.OrganizationResponse{
+		ID:             organization.ID,
+		Name:           organization.Name,
+		LegalName:      organization.LegalName,
+		TaxID:          organization.TaxID,
+		BillingAddress: organization.BillingAddress,
+		Role:           role,
+		CreatedAt:      organization.CreatedAt,
+	}
+}
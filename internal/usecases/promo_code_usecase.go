@@ -0,0 +1,346 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPromoCodeNotFound    = errors.New("promo code not found")
+	ErrPromoCodeExists      = errors.New("a promo code with this code already exists")
+	ErrInvalidPromoCode     = errors.New("invalid promo code")
+	ErrPromoCodeUnavailable = errors.New("promo code is not available")
+)
+
+// PromoCodeUseCase manages the promo codes of events and organizations and
+// applies them to orders.
+type PromoCodeUseCase struct {
+	promoCodeRepo repositories.PromoCodeRepository
+	eventRepo     repositories.EventRepository
+	tickets       *TicketTypeUseCase
+	authorizer    *EventAuthorizer
+}
+
+func NewPromoCodeUseCase(
+	promoCodeRepo repositories.PromoCodeRepository,
+	eventRepo repositories.EventRepository,
+	tickets *TicketTypeUseCase,
+	authorizer *EventAuthorizer,
+) *PromoCodeUseCase {
+	return &PromoCodeUseCase{
+		promoCodeRepo: promoCodeRepo,
+		eventRepo:     eventRepo,
+		tickets:       tickets,
+		authorizer:    authorizer,
+	}
+}
+
+// CreatePromoCode creates a promo code for an event, or for every event of an
+// organization. Codes are case-insensitive and unique within their event or
+// organization; an event's own code takes precedence over its
+// organization's code with the same text.
+func (uc *PromoCodeUseCase) CreatePromoCode(ctx context.Context, userID uint, req *entities.PromoCodeRequest) (*entities.PromoCode, error) {
+	if err := uc.authorizeScope(ctx, userID, req.EventID, req.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	promoCode := &entities.PromoCode{EventID: req.EventID, OrganizationID: req.OrganizationID, CreatedByID: userID}
+	if err := uc.applyPromoCodeRequest(ctx, promoCode, req); err != nil {
+		return nil, err
+	}
+	if err := uc.promoCodeRepo.Create(ctx, promoCode); err != nil {
+		return nil, err
+	}
+	return promoCode, nil
+}
+
+// ListPromoCodes returns the promo codes of an event or of an organization,
+// with how many times each one is redeemed by pending or paid orders.
+func (uc *PromoCodeUseCase) ListPromoCodes(ctx context.Context, userID uint, eventID, organizationID *uint) ([]*entities.PromoCode, map[uint]int, error) {
+	if (eventID == nil) == (organizationID == nil) {
+		return nil, nil, fmt.Errorf("%w: filter by either event_id or organization_id", ErrInvalidPromoCode)
+	}
+	if err := uc.authorizeScope(ctx, userID, eventID, organizationID); err != nil {
+		return nil, nil, err
+	}
+
+	var promoCodes []*entities.PromoCode
+	var err error
+	if eventID != nil {
+		promoCodes, err = uc.promoCodeRepo.GetByEventID(ctx, *eventID)
+	} else {
+		promoCodes, err = uc.promoCodeRepo.GetByOrganizationID(ctx, *organizationID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	redemptions, err := uc.countRedemptions(ctx, promoCodes...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return promoCodes, redemptions, nil
+}
+
+// GetPromoCode returns a promo code with how many times it is redeemed by
+// pending or paid orders.
+func (uc *PromoCodeUseCase) GetPromoCode(ctx context.Context, userID, id uint) (*entities.PromoCode, int, error) {
+	promoCode, err := uc.authorizedPromoCode(ctx, userID, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	redemptions, err := uc.countRedemptions(ctx, promoCode)
+	if err != nil {
+		return nil, 0, err
+	}
+	return promoCode, redemptions[promoCode.ID], nil
+}
+
+// UpdatePromoCode replaces a promo code's definition and returns it with how
+// many times it is redeemed. The code stays with its event or organization,
+// and orders that already redeemed it keep their discount.
+func (uc *PromoCodeUseCase) UpdatePromoCode(ctx context.Context, userID, id uint, req *entities.PromoCodeRequest) (*entities.PromoCode, int, error) {
+	promoCode, err := uc.authorizedPromoCode(ctx, userID, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !sameID(promoCode.EventID, req.EventID) || !sameID(promoCode.OrganizationID, req.OrganizationID) {
+		return nil, 0, fmt.Errorf("%w: a promo code can't move to another event or organization", ErrInvalidPromoCode)
+	}
+
+	if err := uc.applyPromoCodeRequest(ctx, promoCode, req); err != nil {
+		return nil, 0, err
+	}
+	if err := uc.promoCodeRepo.Update(ctx, promoCode); err != nil {
+		return nil, 0, err
+	}
+
+	redemptions, err := uc.countRedemptions(ctx, promoCode)
+	if err != nil {
+		return nil, 0, err
+	}
+	return promoCode, redemptions[promoCode.ID], nil
+}
+
+// DeletePromoCode removes a promo code. Its redemptions are kept for the
+// orders that used it.
+func (uc *PromoCodeUseCase) DeletePromoCode(ctx context.Context, userID, id uint) error {
+	if _, err := uc.authorizedPromoCode(ctx, userID, id); err != nil {
+		return err
+	}
+	return uc.promoCodeRepo.Delete(ctx, id)
+}
+
+// GetRedemptions returns a promo code with its redemptions, newest first,
+// for its report.
+func (uc *PromoCodeUseCase) GetRedemptions(ctx context.Context, userID, id uint) (*entities.PromoCode, []*entities.PromoRedemption, error) {
+	promoCode, err := uc.authorizedPromoCode(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	redemptions, err := uc.promoCodeRepo.GetRedemptions(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return promoCode, redemptions, nil
+}
+
+// quote finds the promo code for an order of the ticket type and returns the
+// discount it gives on subtotal. Whether the code has uses left is only
+// checked when the order redeems it.
+func (uc *PromoCodeUseCase) quote(ctx context.Context, code string, event *entities.Event, ticketType *entities.TicketType, subtotal int64, now time.Time) (*entities.PromoCode, int64, error) {
+	promoCode, err := uc.promoCodeRepo.FindForEvent(ctx, strings.TrimSpace(code), event.ID, event.OrganizationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, fmt.Errorf("%w: unknown code %q", ErrInvalidPromoCode, code)
+		}
+		return nil, 0, err
+	}
+
+	if promoCode.ValidFrom != nil && now.Before(*promoCode.ValidFrom) {
+		return nil, 0, fmt.Errorf("%w: %s is valid from %s", ErrPromoCodeUnavailable, promoCode.Code, promoCode.ValidFrom.Format(time.RFC3339))
+	}
+	if !promoCode.ValidAt(now) {
+		return nil, 0, fmt.Errorf("%w: %s expired", ErrPromoCodeUnavailable, promoCode.Code)
+	}
+	if !promoCode.AppliesTo(ticketType.ID) {
+		return nil, 0, fmt.Errorf("%w: %s doesn't apply to %s", ErrInvalidPromoCode, promoCode.Code, ticketType.Name)
+	}
+	if promoCode.DiscountType == entities.DiscountFixed && promoCode.Currency != ticketType.Currency {
+		return nil, 0, fmt.Errorf("%w: %s discounts tickets sold in %s", ErrInvalidPromoCode, promoCode.Code, promoCode.Currency)
+	}
+	return promoCode, promoCode.Discount(subtotal), nil
+}
+
+// redeem counts the order against the promo code's limits, failing with
+// ErrPromoCodeUnavailable when the code or the buyer's share of it is used
+// up. Concurrent orders are serialized on the code, so its limits hold.
+func (uc *PromoCodeUseCase) redeem(ctx context.Context, promoCode *entities.PromoCode, order *entities.Order) error {
+	redemption := &entities.PromoRedemption{
+		PromoCodeID: promoCode.ID,
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		Discount:    order.Discount,
+	}
+	err := uc.promoCodeRepo.Redeem(ctx, redemption, func(redemptions, userRedemptions int) error {
+		if promoCode.MaxRedemptions > 0 && redemptions >= promoCode.MaxRedemptions {
+			return fmt.Errorf("%w: %s has been used up", ErrPromoCodeUnavailable, promoCode.Code)
+		}
+		if promoCode.MaxPerUser > 0 && userRedemptions >= promoCode.MaxPerUser {
+			return fmt.Errorf("%w: you already used %s %d times", ErrPromoCodeUnavailable, promoCode.Code, userRedemptions)
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The code was deleted since it was quoted.
+		return fmt.Errorf("%w: unknown code %q", ErrInvalidPromoCode, promoCode.Code)
+	}
+	return err
+}
+
+func (uc *PromoCodeUseCase) countRedemptions(ctx context.Context, promoCodes ...*entities.PromoCode) (map[uint]int, error) {
+	if len(promoCodes) == 0 {
+		return map[uint]int{}, nil
+	}
+	ids := make([]uint, len(promoCodes))
+	for i, promoCode := range promoCodes {
+		ids[i] = promoCode.ID
+	}
+	return uc.promoCodeRepo.CountRedemptions(ctx, ids)
+}
+
+func (uc *PromoCodeUseCase) authorizedPromoCode(ctx context.Context, userID, id uint) (*entities.PromoCode, error) {
+	promoCode, err := uc.promoCodeRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPromoCodeNotFound
+		}
+		return nil, err
+	}
+
+	if err := uc.authorizeScope(ctx, userID, promoCode.EventID, promoCode.OrganizationID); err != nil {
+		if errors.Is(err, ErrEventNotFound) {
+			return nil, ErrPromoCodeNotFound
+		}
+		return nil, err
+	}
+	return promoCode, nil
+}
+
+// authorizeScope checks that the user manages the promo codes of the event,
+// or of the organization when eventID is nil.
+func (uc *PromoCodeUseCase) authorizeScope(ctx context.Context, userID uint, eventID, organizationID *uint) error {
+	if eventID == nil {
+		return uc.authorizer.AuthorizeInOrganization(ctx, *organizationID, userID, entities.PermissionManagePromoCodes)
+	}
+
+	event, err := uc.eventRepo.GetByID(ctx, *eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEventNotFound
+		}
+		return err
+	}
+	return uc.authorizer.Authorize(ctx, event, userID, entities.PermissionManagePromoCodes)
+}
+
+// applyPromoCodeRequest validates a promo code definition and copies it over.
+// Fixed discounts default to the default currency, and the ticket types a
+// code is restricted to must belong to its event, or to an event of its
+// organization.
+func (uc *PromoCodeUseCase) applyPromoCodeRequest(ctx context.Context, promoCode *entities.PromoCode, req *entities.PromoCodeRequest) error {
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if code == "" || strings.IndexFunc(code, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("%w: the code can't be empty or contain spaces", ErrInvalidPromoCode)
+	}
+	currency := ""
+	switch req.DiscountType {
+	case entities.DiscountPercentage:
+		if req.DiscountValue > 100 {
+			return fmt.Errorf("%w: a percentage discount can't exceed 100", ErrInvalidPromoCode)
+		}
+	case entities.DiscountFixed:
+		currency = strings.ToUpper(req.Currency)
+		if currency == "" {
+			currency = entities.DefaultCurrency
+		}
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidUntil.After(*req.ValidFrom) {
+		return fmt.Errorf("%w: valid_until must be after valid_from", ErrInvalidPromoCode)
+	}
+
+	ticketTypeIDs := []uint{}
+	for _, ticketTypeID := range req.TicketTypeIDs {
+		if slices.Contains(ticketTypeIDs, ticketTypeID) {
+			continue
+		}
+		if err := uc.checkTicketType(ctx, promoCode, ticketTypeID); err != nil {
+			return err
+		}
+		ticketTypeIDs = append(ticketTypeIDs, ticketTypeID)
+	}
+
+	promoCode.Code = code
+	exists, err := uc.promoCodeRepo.ExistsInScope(ctx, promoCode)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrPromoCodeExists
+	}
+
+	promoCode.DiscountType = req.DiscountType
+	promoCode.DiscountValue = req.DiscountValue
+	promoCode.Currency = currency
+	promoCode.MaxRedemptions = req.MaxRedemptions
+	promoCode.MaxPerUser = req.MaxPerUser
+	promoCode.ValidFrom = req.ValidFrom
+	promoCode.ValidUntil = req.ValidUntil
+	promoCode.TicketTypeIDs = ticketTypeIDs
+	return nil
+}
+
+func (uc *PromoCodeUseCase) checkTicketType(ctx context.Context, promoCode *entities.PromoCode, ticketTypeID uint) error {
+	ticketType, err := uc.tickets.GetTicketType(ctx, ticketTypeID)
+	if err != nil {
+		if errors.Is(err, ErrTicketTypeNotFound) {
+			return fmt.Errorf("%w: unknown ticket type %d", ErrInvalidPromoCode, ticketTypeID)
+		}
+		return err
+	}
+
+	if promoCode.EventID != nil {
+		if ticketType.EventID != *promoCode.EventID {
+			return fmt.Errorf("%w: ticket type %d is not of the event", ErrInvalidPromoCode, ticketTypeID)
+		}
+		return nil
+	}
+	event, err := uc.eventRepo.GetByID(ctx, ticketType.EventID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if event == nil || !sameID(event.OrganizationID, promoCode.OrganizationID) {
+		return fmt.Errorf("%w: ticket type %d is not of an event of the organization", ErrInvalidPromoCode, ticketTypeID)
+	}
+	return nil
+}
+
+// sameID reports whether two optional IDs are equal.
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,96 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"EventsAPI/internal/domain/entities"
+)
+
+// promoFixture is an order fixture whose event belongs to an organization and
+// sells 10 tickets, with a second event of the same organization.
+func promoFixture(t *testing.T) (*orderFixture, entities.Event, entities.TicketType) {
+	t.Helper()
+	f := newOrderFixture(t)
+	organizationID := f.store.newID()
+	f.event.OrganizationID = &organizationID
+	f.store.events[f.event.ID] = f.event
+	f.ticketType.Quantity = 10
+	f.store.ticketTypes[f.ticketType.ID] = f.ticketType
+
+	other := f.event
+	other.ID = f.store.newID()
+	other.Title = "Go workshop"
+	f.store.events[other.ID] = other
+	otherTicket := f.ticketType
+	otherTicket.ID = f.store.newID()
+	otherTicket.EventID = other.ID
+	f.store.ticketTypes[otherTicket.ID] = otherTicket
+	return f, other, otherTicket
+}
+
+func (f *orderFixture) addPromoCode(promoCode entities.PromoCode) {
+	promoCode.ID = f.store.newID()
+	promoCode.DiscountType = entities.DiscountPercentage
+	promoCode.DiscountValue = 50
+	f.store.promoCodes[promoCode.ID] = promoCode
+}
+
+func TestCheckoutPromoCodeUsageLimit(t *testing.T) {
+	f, _, _ := promoFixture(t)
+	f.addPromoCode(entities.PromoCode{Code: "HALF", EventID: &f.event.ID, MaxRedemptions: 1})
+
+	first, err := f.checkout(f.newUser(t, "first@example.com"), "half")
+	if err != nil {
+		t.Fatalf("first checkout: %v", err)
+	}
+	if first.Amount != 1250 || first.Discount != 1250 || first.PromoCode != "HALF" {
+		t.Errorf("order = %d with %d off by %q, want 1250 with 1250 off by HALF", first.Amount, first.Discount, first.PromoCode)
+	}
+
+	second := f.newUser(t, "second@example.com")
+	if _, err := f.checkout(second, "HALF"); !errors.Is(err, ErrPromoCodeUnavailable) {
+		t.Fatalf("second checkout error = %v, want ErrPromoCodeUnavailable", err)
+	}
+	released := 0
+	for _, order := range f.store.orders {
+		if order.UserID == second {
+			f.assertOrder(t, order.ID, entities.OrderStatusCancelled)
+			f.assertAttendee(t, order.AttendeeID, entities.AttendeeStatusCancelled)
+			released++
+		}
+	}
+	if released != 1 {
+		t.Errorf("%d orders of the second user, want 1 released", released)
+	}
+
+	// Cancelling the first order gives its use of the code back.
+	if _, err := f.uc.CancelOrder(context.Background(), first.UserID, first.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if _, err := f.checkout(f.newUser(t, "third@example.com"), "HALF"); err != nil {
+		t.Errorf("checkout after the cancellation: %v", err)
+	}
+}
+
+func TestCheckoutPromoCodePerUserLimit(t *testing.T) {
+	f, other, otherTicket := promoFixture(t)
+	f.addPromoCode(entities.PromoCode{Code: "MEMBER", OrganizationID: f.event.OrganizationID, MaxPerUser: 1})
+	buyer := f.newUser(t, "buyer@example.com")
+
+	if _, err := f.checkout(buyer, "MEMBER"); err != nil {
+		t.Fatalf("first checkout: %v", err)
+	}
+	_, _, err := f.uc.Checkout(context.Background(), other.ID, buyer, "", &entities.AttendeeRequest{
+		TicketTypeID: &otherTicket.ID,
+		PromoCode:    "MEMBER",
+	})
+	if !errors.Is(err, ErrPromoCodeUnavailable) {
+		t.Fatalf("second checkout by the same user error = %v, want ErrPromoCodeUnavailable", err)
+	}
+
+	if _, err := f.checkout(f.newUser(t, "other@example.com"), "MEMBER"); err != nil {
+		t.Errorf("checkout by another user: %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+	"EventsAPI/internal/domain/services"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefund   = errors.New("invalid refund")
+	ErrRefundNotFound  = errors.New("refund not found")
+	errNothingToRefund = errors.New("nothing left to refund")
+)
+
+// RefundUseCase gives back the money of paid orders: automatically, by the
+// event's refund policy when an attendee cancels or in full when the event is
+// cancelled, and manually when an organizer decides to.
+type RefundUseCase struct {
+	refundRepo repositories.RefundRepository
+	orderRepo  repositories.OrderRepository
+	authorizer *EventAuthorizer
+	invoices   *InvoiceUseCase
+	provider   services.PaymentProvider
+	notifier   services.Notifier
+}
+
+func NewRefundUseCase(
+	refundRepo repositories.RefundRepository,
+	orderRepo repositories.OrderRepository,
+	authorizer *EventAuthorizer,
+	invoices *InvoiceUseCase,
+	provider services.PaymentProvider,
+	notifier services.Notifier,
+) *RefundUseCase {
+	return &RefundUseCase{
+		refundRepo: refundRepo,
+		orderRepo:  orderRepo,
+		authorizer: authorizer,
+		invoices:   invoices,
+		provider:   provider,
+		notifier:   notifier,
+	}
+}
+
+// cancellationRefund prepares the refund owed for a registration cancelled at
+// cancelledAt, by the event's refund policy, for the attendee repository to
+// create together with the cancellation. It returns a nil refund when there
+// is no paid order or the cancellation is too late for the policy. The refund
+// is sent to the provider with submit once it is saved.
+func (uc *RefundUseCase) cancellationRefund(ctx context.Context, event *entities.Event, attendee *entities.Attendee, cancelledAt time.Time) (*entities.Order, *entities.Refund, func(order *entities.Order, refunded int64) error, error) {
+	order, err := uc.orderRepo.GetPaidByAttendeeID(ctx, attendee.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, nil
+		}
+		return nil, nil, nil, err
+	}
+
+	amount := order.Amount * int64(event.RefundPercent(cancelledAt)) / 100
+	if amount == 0 {
+		return nil, nil, nil, nil
+	}
+	refund, check := uc.newRefund(order, amount, entities.RefundReasonAttendeeCancelled, nil)
+	return order, refund, check, nil
+}
+
+// RefundEvent handles the cancellation of an event: its pending orders are
+// cancelled and its paid orders refunded in full. A refund the provider
+// rejects is recorded as failed for an organizer to retry.
+func (uc *RefundUseCase) RefundEvent(ctx context.Context, event *entities.Event) error {
+	if _, err := uc.orderRepo.ReleaseByEventID(ctx, event.ID, entities.OrderStatusCancelled); err != nil {
+		return err
+	}
+
+	orders, err := uc.orderRepo.GetPaidByEventID(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		_, err := uc.refund(ctx, order, 0, entities.RefundReasonEventCancelled, nil)
+		if err != nil && !errors.Is(err, errNothingToRefund) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefundUnfulfilled refunds in full an order paid after its seats were gone.
+func (uc *RefundUseCase) RefundUnfulfilled(ctx context.Context, order *entities.Order) error {
+	_, err := uc.refund(ctx, order, 0, entities.RefundReasonUnfulfilled, nil)
+	if errors.Is(err, errNothingToRefund) {
+		return nil
+	}
+	return err
+}
+
+// IssueRefund lets an organizer refund a paid or unfulfilled order
+// regardless of the refund policy, by default whatever hasn't been refunded
+// yet. The registration is left as it is.
+func (uc *RefundUseCase) IssueRefund(ctx context.Context, userID, orderID uint, req *entities.OrderRefundRequest) (*entities.Refund, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if err := uc.authorizer.Authorize(ctx, &order.Event, userID, entities.PermissionRefundOrders); err != nil {
+		return nil, err
+	}
+	if order.Status != entities.OrderStatusPaid && order.Status != entities.OrderStatusUnfulfilled {
+		return nil, fmt.Errorf("%w: only paid orders can be refunded", ErrInvalidRefund)
+	}
+
+	var amount int64
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+	reason := req.Reason
+	if reason == "" {
+		reason = "manual"
+	}
+
+	refund, err := uc.refund(ctx, order, amount, reason, &userID)
+	if errors.Is(err, errNothingToRefund) {
+		return nil, fmt.Errorf("%w: the order was already refunded", ErrInvalidRefund)
+	}
+	return refund, err
+}
+
+// handleRefundEvent applies a webhook reporting the outcome of a pending
+// refund. Repeated notifications are ignored.
+func (uc *RefundUseCase) handleRefundEvent(ctx context.Context, event *services.PaymentEvent) error {
+	refund, err := uc.refundRepo.GetByProviderRefundID(ctx, uc.provider.Name(), event.RefundID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRefundNotFound
+		}
+		return err
+	}
+	if event.Amount != refund.Amount {
+		return fmt.Errorf("%w: the refund doesn't match its amount", ErrInvalidWebhook)
+	}
+
+	status := entities.RefundStatusSucceeded
+	message := ""
+	if event.Status == services.PaymentFailed {
+		status = entities.RefundStatusFailed
+		message = "rejected by the payment provider"
+	}
+	completed, err := uc.refundRepo.Complete(ctx, refund.ID, status, message)
+	if err != nil || !completed || status != entities.RefundStatusSucceeded {
+		return err
+	}
+
+	order, err := uc.orderRepo.GetByID(ctx, refund.OrderID)
+	if err != nil {
+		return err
+	}
+	refund.Status = status
+	return uc.refunded(ctx, order, refund)
+}
+
+// refund asks the provider to give back amount of the order, or all that
+// hasn't been refunded yet when amount is 0. Automatic refunds are capped at
+// what is left; a manual refund, with the organizer as requestedBy, can't
+// exceed it. It returns errNothingToRefund when the order was already fully
+// refunded.
+func (uc *RefundUseCase) refund(ctx context.Context, order *entities.Order, amount int64, reason string, requestedBy *uint) (*entities.Refund, error) {
+	refund, check := uc.newRefund(order, amount, reason, requestedBy)
+	if err := uc.refundRepo.CreateWithinOrder(ctx, refund, check); err != nil {
+		return nil, err
+	}
+	return uc.submit(ctx, order, refund)
+}
+
+// newRefund returns a pending refund of the order and the check that sets its
+// amount, for RefundRepository.CreateWithinOrder, as described by refund.
+func (uc *RefundUseCase) newRefund(order *entities.Order, amount int64, reason string, requestedBy *uint) (*entities.Refund, func(order *entities.Order, refunded int64) error) {
+	refund := &entities.Refund{
+		OrderID:       order.ID,
+		Currency:      order.Currency,
+		Status:        entities.RefundStatusPending,
+		Reason:        reason,
+		RequestedByID: requestedBy,
+		Provider:      uc.provider.Name(),
+	}
+	return refund, func(order *entities.Order, refunded int64) error {
+		remaining := order.Amount - refunded
+		if remaining <= 0 {
+			return errNothingToRefund
+		}
+		switch {
+		case amount == 0:
+			refund.Amount = remaining
+		case amount > remaining && requestedBy != nil:
+			return fmt.Errorf("%w: at most %d can still be refunded", ErrInvalidRefund, remaining)
+		default:
+			refund.Amount = min(amount, remaining)
+		}
+		return nil
+	}
+}
+
+// submit asks the provider for a refund that was just saved as pending.
+func (uc *RefundUseCase) submit(ctx context.Context, order *entities.Order, refund *entities.Refund) (*entities.Refund, error) {
+	result, err := uc.provider.Refund(ctx, services.RefundRequest{
+		PaymentID: order.PaymentID,
+		Reference: fmt.Sprintf("refund-%d", refund.ID),
+		Amount:    refund.Amount,
+		Currency:  refund.Currency,
+	})
+	if err != nil {
+		// Keep the failed refund on record so an organizer can retry it.
+		refund.Status = entities.RefundStatusFailed
+		refund.FailureMessage = err.Error()
+		return refund, uc.refundRepo.Update(ctx, refund)
+	}
+
+	refund.ProviderRefundID = result.ID
+	switch result.Status {
+	case services.PaymentSucceeded:
+		refund.Status = entities.RefundStatusSucceeded
+	case services.PaymentFailed:
+		refund.Status = entities.RefundStatusFailed
+		refund.FailureMessage = "rejected by the payment provider"
+	}
+	if err := uc.refundRepo.Update(ctx, refund); err != nil {
+		return nil, err
+	}
+	if refund.Status == entities.RefundStatusSucceeded {
+		if err := uc.refunded(ctx, order, refund); err != nil {
+			return nil, err
+		}
+	}
+	return refund, nil
+}
+
+// refunded issues the credit note of a refund that just succeeded and lets
+// the buyer know.
+func (uc *RefundUseCase) refunded(ctx context.Context, order *entities.Order, refund *entities.Refund) error {
+	if _, err := uc.invoices.issue(ctx, order.ID); err != nil {
+		return err
+	}
+	return uc.notifyRefunded(ctx, order, refund)
+}
+
+func (uc *RefundUseCase) notifyRefunded(ctx context.Context, order *entities.Order, refund *entities.Refund) error {
+	return uc.notifier.Notify(ctx, services.Notification{
+		To:      order.User.Email,
+		Subject: fmt.Sprintf("Refund for %s", order.Event.Title),
+		Body:    fmt.Sprintf("We refunded %s of your order #%d for %s.", formatAmount(refund.Amount, refund.Currency), order.ID, order.Event.Title),
+	})
+}
+
+// formatAmount formats an amount in minor units with two decimals.
+func formatAmount(amount int64, currency string) string {
+	return fmt.Sprintf("%d.%02d %s", amount/100, amount%100, currency)
+}
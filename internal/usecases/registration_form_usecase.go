@@ -0,0 +1,416 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrQuestionNotFound = errors.New("registration question not found")
+	ErrInvalidQuestion  = errors.New("invalid registration question")
+	ErrInvalidAnswers   = errors.New("invalid registration answers")
+)
+
+// RegistrationFormUseCase manages the registration questions of an event and
+// validates the answers submitted with a registration.
+type RegistrationFormUseCase struct {
+	questionRepo repositories.RegistrationQuestionRepository
+	eventRepo    repositories.EventRepository
+	authorizer   *EventAuthorizer
+	invitations  *EventInvitationUseCase
+}
+
+func NewRegistrationFormUseCase(
+	questionRepo repositories.RegistrationQuestionRepository,
+	eventRepo repositories.EventRepository,
+	authorizer *EventAuthorizer,
+	invitations *EventInvitationUseCase,
+) *RegistrationFormUseCase {
+	return &RegistrationFormUseCase{
+		questionRepo: questionRepo,
+		eventRepo:    eventRepo,
+		authorizer:   authorizer,
+		invitations:  invitations,
+	}
+}
+
+// ListQuestions returns the registration form to anyone who can see the event.
+func (uc *RegistrationFormUseCase) ListQuestions(ctx context.Context, userID, eventID uint, inviteToken string) ([]*entities.RegistrationQuestion, error) {
+	event, err := uc.getEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := uc.invitations.CanView(ctx, event, userID, inviteToken)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrEventNotFound
+	}
+
+	return uc.questionRepo.GetByEventID(ctx, eventID)
+}
+
+func (uc *RegistrationFormUseCase) CreateQuestion(ctx context.Context, userID, eventID uint, req *entities.RegistrationQuestionRequest) (*entities.RegistrationQuestion, error) {
+	if _, err := uc.authorizedEvent(ctx, userID, eventID, entities.PermissionEditEvent); err != nil {
+		return nil, err
+	}
+
+	question := &entities.RegistrationQuestion{EventID: eventID}
+	if err := applyQuestionRequest(question, req); err != nil {
+		return nil, err
+	}
+	if err := uc.questionRepo.Create(ctx, question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// UpdateQuestion replaces a question's definition. Existing answers are kept
+// as they were submitted.
+func (uc *RegistrationFormUseCase) UpdateQuestion(ctx context.Context, userID, eventID, questionID uint, req *entities.RegistrationQuestionRequest) (*entities.RegistrationQuestion, error) {
+	question, err := uc.authorizedQuestion(ctx, userID, eventID, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyQuestionRequest(question, req); err != nil {
+		return nil, err
+	}
+	if err := uc.questionRepo.Update(ctx, question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// DeleteQuestion removes a question and every answer to it.
+func (uc *RegistrationFormUseCase) DeleteQuestion(ctx context.Context, userID, eventID, questionID uint) error {
+	if _, err := uc.authorizedQuestion(ctx, userID, eventID, questionID); err != nil {
+		return err
+	}
+	return uc.questionRepo.Delete(ctx, questionID)
+}
+
+// AnswerStats aggregates the answers of the pending and confirmed
+// registrations, question by question.
+func (uc *RegistrationFormUseCase) AnswerStats(ctx context.Context, userID, eventID uint) ([]entities.QuestionStatsResponse, error) {
+	if _, err := uc.authorizedEvent(ctx, userID, eventID, entities.PermissionViewAttendees); err != nil {
+		return nil, err
+	}
+
+	questions, err := uc.questionRepo.GetByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := uc.questionRepo.GetAnswersByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	byQuestion := make(map[uint][]*entities.RegistrationAnswer)
+	for _, answer := range answers {
+		byQuestion[answer.QuestionID] = append(byQuestion[answer.QuestionID], answer)
+	}
+
+	stats := make([]entities.QuestionStatsResponse, 0, len(questions))
+	for _, question := range questions {
+		stats = append(stats, questionStats(question, byQuestion[question.ID]))
+	}
+	return stats, nil
+}
+
+// ValidateAnswers checks the submitted answers against the event's form and
+// returns them normalized, ready to be stored. Errors wrap ErrInvalidAnswers.
+func (uc *RegistrationFormUseCase) ValidateAnswers(ctx context.Context, eventID uint, submitted []entities.RegistrationAnswerRequest) ([]entities.RegistrationAnswer, error) {
+	questions, err := uc.questionRepo.GetByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[uint]json.RawMessage, len(submitted))
+	for _, answer := range submitted {
+		if !slices.ContainsFunc(questions, func(q *entities.RegistrationQuestion) bool { return q.ID == answer.QuestionID }) {
+			return nil, fmt.Errorf("%w: question %d is not part of this event", ErrInvalidAnswers, answer.QuestionID)
+		}
+		if _, duplicate := values[answer.QuestionID]; duplicate {
+			return nil, fmt.Errorf("%w: question %d is answered twice", ErrInvalidAnswers, answer.QuestionID)
+		}
+		values[answer.QuestionID] = answer.Value
+	}
+
+	answers := make([]entities.RegistrationAnswer, 0, len(submitted))
+	for _, question := range questions {
+		raw, answered := values[question.ID]
+		if answered && (len(raw) == 0 || string(raw) == "null") {
+			answered = false
+		}
+
+		value, err := normalizeAnswer(question, raw, answered)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q %s", ErrInvalidAnswers, question.Label, err.Error())
+		}
+		if value != nil {
+			answers = append(answers, entities.RegistrationAnswer{QuestionID: question.ID, Value: value})
+		}
+	}
+	return answers, nil
+}
+
+// ReplaceAnswers stores the validated answers of a registration, dropping the
+// previous ones.
+func (uc *RegistrationFormUseCase) ReplaceAnswers(ctx context.Context, attendeeID uint, answers []entities.RegistrationAnswer) error {
+	return uc.questionRepo.ReplaceAnswers(ctx, attendeeID, answers)
+}
+
+func (uc *RegistrationFormUseCase) getEvent(ctx context.Context, eventID uint) (*entities.Event, error) {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+func (uc *RegistrationFormUseCase) authorizedEvent(ctx context.Context, userID, eventID uint, permission entities.Permission) (*entities.Event, error) {
+	event, err := uc.getEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.authorizer.Authorize(ctx, event, userID, permission); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (uc *RegistrationFormUseCase) authorizedQuestion(ctx context.Context, userID, eventID, questionID uint) (*entities.RegistrationQuestion, error) {
+	if _, err := uc.authorizedEvent(ctx, userID, eventID, entities.PermissionEditEvent); err != nil {
+		return nil, err
+	}
+
+	question, err := uc.questionRepo.GetByID(ctx, questionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	if question.EventID != eventID {
+		return nil, ErrQuestionNotFound
+	}
+	return question, nil
+}
+
+// applyQuestionRequest validates a question definition and copies it over.
+func applyQuestionRequest(question *entities.RegistrationQuestion, req *entities.RegistrationQuestionRequest) error {
+	isChoice := req.Type == entities.QuestionTypeSingleChoice || req.Type == entities.QuestionTypeMultiChoice
+
+	options := make([]string, 0, len(req.Options))
+	for _, option := range req.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || slices.Contains(options, option) {
+			return fmt.Errorf("%w: options must be unique and not empty", ErrInvalidQuestion)
+		}
+		options = append(options, option)
+	}
+	if isChoice && len(options) == 0 {
+		return fmt.Errorf("%w: choice questions need options", ErrInvalidQuestion)
+	}
+	if !isChoice && len(options) > 0 {
+		return fmt.Errorf("%w: only choice questions take options", ErrInvalidQuestion)
+	}
+
+	rules := req.Rules
+	if rules.Pattern != "" {
+		if _, err := regexp.Compile(rules.Pattern); err != nil {
+			return fmt.Errorf("%w: invalid pattern: %s", ErrInvalidQuestion, err.Error())
+		}
+	}
+	if rules.MinLength != nil && rules.MaxLength != nil && *rules.MinLength > *rules.MaxLength {
+		return fmt.Errorf("%w: min_length is greater than max_length", ErrInvalidQuestion)
+	}
+	if rules.Min != nil && rules.Max != nil && *rules.Min > *rules.Max {
+		return fmt.Errorf("%w: min is greater than max", ErrInvalidQuestion)
+	}
+	if rules.MinSelections != nil && rules.MaxSelections != nil && *rules.MinSelections > *rules.MaxSelections {
+		return fmt.Errorf("%w: min_selections is greater than max_selections", ErrInvalidQuestion)
+	}
+
+	question.Label = strings.TrimSpace(req.Label)
+	question.Type = req.Type
+	question.Required = req.Required
+	question.Options = options
+	question.Rules = rules
+	question.Position = req.Position
+	return nil
+}
+
+// normalizeAnswer validates a single answer. It returns nil for an optional
+// question that wasn't answered.
+func normalizeAnswer(question *entities.RegistrationQuestion, raw json.RawMessage, answered bool) ([]string, error) {
+	if !answered {
+		if question.Required {
+			return nil, errors.New("is required")
+		}
+		return nil, nil
+	}
+	rules := question.Rules
+
+	switch question.Type {
+	case entities.QuestionTypeText:
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, errors.New("must be a string")
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			if question.Required {
+				return nil, errors.New("is required")
+			}
+			return nil, nil
+		}
+		length := utf8.RuneCountInString(text)
+		if rules.MinLength != nil && length < *rules.MinLength {
+			return nil, fmt.Errorf("must be at least %d characters long", *rules.MinLength)
+		}
+		if rules.MaxLength != nil && length > *rules.MaxLength {
+			return nil, fmt.Errorf("must be at most %d characters long", *rules.MaxLength)
+		}
+		if rules.Pattern != "" {
+			if matched, _ := regexp.MatchString(rules.Pattern, text); !matched {
+				return nil, errors.New("has an invalid format")
+			}
+		}
+		return []string{text}, nil
+
+	case entities.QuestionTypeNumber:
+		var number float64
+		if err := json.Unmarshal(raw, &number); err != nil {
+			return nil, errors.New("must be a number")
+		}
+		if rules.Integer && number != math.Trunc(number) {
+			return nil, errors.New("must be a whole number")
+		}
+		if rules.Min != nil && number < *rules.Min {
+			return nil, fmt.Errorf("must be at least %g", *rules.Min)
+		}
+		if rules.Max != nil && number > *rules.Max {
+			return nil, fmt.Errorf("must be at most %g", *rules.Max)
+		}
+		return []string{strconv.FormatFloat(number, 'f', -1, 64)}, nil
+
+	case entities.QuestionTypeCheckbox:
+		var checked bool
+		if err := json.Unmarshal(raw, &checked); err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		if question.Required && !checked {
+			return nil, errors.New("must be checked")
+		}
+		return []string{strconv.FormatBool(checked)}, nil
+
+	case entities.QuestionTypeSingleChoice:
+		var choice string
+		if err := json.Unmarshal(raw, &choice); err != nil {
+			return nil, errors.New("must be one of the options")
+		}
+		if !slices.Contains(question.Options, choice) {
+			return nil, errors.New("must be one of the options")
+		}
+		return []string{choice}, nil
+
+	case entities.QuestionTypeMultiChoice:
+		var choices []string
+		if err := json.Unmarshal(raw, &choices); err != nil {
+			return nil, errors.New("must be a list of options")
+		}
+		selected := make([]string, 0, len(choices))
+		for _, choice := range choices {
+			if !slices.Contains(question.Options, choice) {
+				return nil, fmt.Errorf("has an unknown option %q", choice)
+			}
+			if !slices.Contains(selected, choice) {
+				selected = append(selected, choice)
+			}
+		}
+		if len(selected) == 0 && question.Required {
+			return nil, errors.New("is required")
+		}
+		if rules.MinSelections != nil && len(selected) < *rules.MinSelections {
+			return nil, fmt.Errorf("needs at least %d options", *rules.MinSelections)
+		}
+		if rules.MaxSelections != nil && len(selected) > *rules.MaxSelections {
+			return nil, fmt.Errorf("allows at most %d options", *rules.MaxSelections)
+		}
+		if len(selected) == 0 {
+			return nil, nil
+		}
+		return selected, nil
+	}
+
+	return nil, fmt.Errorf("has an unsupported type %q", question.Type)
+}
+
+func questionStats(question *entities.RegistrationQuestion, answers []*entities.RegistrationAnswer) entities.QuestionStatsResponse {
+	stats := entities.QuestionStatsResponse{
+		QuestionID: question.ID,
+		Label:      question.Label,
+		Type:       question.Type,
+		Responses:  len(answers),
+	}
+
+	switch question.Type {
+	case entities.QuestionTypeSingleChoice, entities.QuestionTypeMultiChoice, entities.QuestionTypeCheckbox:
+		stats.OptionCounts = make(map[string]int)
+		for _, option := range question.Options {
+			stats.OptionCounts[option] = 0
+		}
+		for _, answer := range answers {
+			for _, value := range answer.Value {
+				stats.OptionCounts[value]++
+			}
+		}
+
+	case entities.QuestionTypeNumber:
+		var sum float64
+		var count int
+		for _, answer := range answers {
+			if len(answer.Value) != 1 {
+				continue
+			}
+			number, err := strconv.ParseFloat(answer.Value[0], 64)
+			if err != nil {
+				continue
+			}
+			if stats.Min == nil || number < *stats.Min {
+				stats.Min = &number
+			}
+			if stats.Max == nil || number > *stats.Max {
+				stats.Max = &number
+			}
+			sum += number
+			count++
+		}
+		if count > 0 {
+			average := sum / float64(count)
+			stats.Average = &average
+		}
+	}
+	return stats
+}
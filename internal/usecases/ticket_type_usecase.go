@@ -0,0 +1,349 @@
+package usecases
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTicketTypeNotFound    = errors.New("ticket type not found")
+	ErrInvalidTicketType     = errors.New("invalid ticket type")
+	ErrTicketTypeRequired    = errors.New("the event requires choosing a ticket type")
+	ErrTicketTypeInUse       = errors.New("ticket type has registrations")
+	ErrLastTicketType        = errors.New("the last ticket type of an event without max_capacity cannot be deleted")
+	ErrTicketNotOnSale       = errors.New("ticket type is not on sale")
+	ErrTicketSoldOut         = errors.New("ticket type is sold out")
+	ErrInvalidTicketQuantity = errors.New("invalid number of tickets for this ticket type")
+)
+
+// TicketTypeUseCase manages the ticket types of an event and picks the ticket
+// type of a registration.
+type TicketTypeUseCase struct {
+	ticketTypeRepo repositories.TicketTypeRepository
+	attendeeRepo   repositories.AttendeeRepository
+	eventRepo      repositories.EventRepository
+	authorizer     *EventAuthorizer
+	invitations    *EventInvitationUseCase
+	venues         *VenueUseCase
+}
+
+func NewTicketTypeUseCase(
+	ticketTypeRepo repositories.TicketTypeRepository,
+	attendeeRepo repositories.AttendeeRepository,
+	eventRepo repositories.EventRepository,
+	authorizer *EventAuthorizer,
+	invitations *EventInvitationUseCase,
+	venues *VenueUseCase,
+) *TicketTypeUseCase {
+	return &TicketTypeUseCase{
+		ticketTypeRepo: ticketTypeRepo,
+		attendeeRepo:   attendeeRepo,
+		eventRepo:      eventRepo,
+		authorizer:     authorizer,
+		invitations:    invitations,
+		venues:         venues,
+	}
+}
+
+// ListTicketTypes returns the event's ticket types with the seats taken to
+// anyone who can see the event. Organizers get every ticket type and
+// organizer is true; other users only get the hidden ticket types unlocked by
+// unlockCode.
+func (uc *TicketTypeUseCase) ListTicketTypes(ctx context.Context, userID, eventID uint, inviteToken, unlockCode string) (availability []entities.TicketTypeAvailability, organizer bool, err error) {
+	event, err := uc.getEvent(ctx, eventID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	organizer, err = uc.authorizer.Can(ctx, event, userID, entities.PermissionEditEvent)
+	if err != nil {
+		return nil, false, err
+	}
+	if !organizer {
+		allowed, err := uc.invitations.CanView(ctx, event, userID, inviteToken)
+		if err != nil {
+			return nil, false, err
+		}
+		if !allowed {
+			return nil, false, ErrEventNotFound
+		}
+	}
+
+	ticketTypes, err := uc.ticketTypeRepo.GetByEventID(ctx, eventID)
+	if err != nil {
+		return nil, false, err
+	}
+	taken, err := uc.attendeeRepo.CountSeatsByTicketType(ctx, eventID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	availability = make([]entities.TicketTypeAvailability, 0, len(ticketTypes))
+	for _, ticketType := range ticketTypes {
+		if organizer || unlocks(ticketType, unlockCode) {
+			availability = append(availability, entities.TicketTypeAvailability{TicketType: ticketType, Taken: taken[ticketType.ID]})
+		}
+	}
+	return availability, organizer, nil
+}
+
+func (uc *TicketTypeUseCase) CreateTicketType(ctx context.Context, userID, eventID uint, req *entities.TicketTypeRequest) (*entities.TicketType, error) {
+	event, err := uc.authorizedEvent(ctx, userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketType := &entities.TicketType{EventID: eventID}
+	if err := uc.applyTicketTypeRequest(ctx, event, ticketType, req); err != nil {
+		return nil, err
+	}
+	if err := uc.ticketTypeRepo.Create(ctx, ticketType); err != nil {
+		return nil, err
+	}
+	return ticketType, nil
+}
+
+// UpdateTicketType replaces a ticket type's definition. Its quantity can't
+// drop below the seats already taken.
+func (uc *TicketTypeUseCase) UpdateTicketType(ctx context.Context, userID, eventID, ticketTypeID uint, req *entities.TicketTypeRequest) (*entities.TicketType, error) {
+	event, err := uc.authorizedEvent(ctx, userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	ticketType, err := uc.eventTicketType(ctx, eventID, ticketTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.applyTicketTypeRequest(ctx, event, ticketType, req); err != nil {
+		return nil, err
+	}
+	if err := uc.ticketTypeRepo.Update(ctx, ticketType); err != nil {
+		return nil, err
+	}
+	return ticketType, nil
+}
+
+// DeleteTicketType removes a ticket type without pending or confirmed
+// registrations. Events without max_capacity keep at least one ticket type,
+// as their ticket types are all that limits them.
+func (uc *TicketTypeUseCase) DeleteTicketType(ctx context.Context, userID, eventID, ticketTypeID uint) error {
+	event, err := uc.authorizedEvent(ctx, userID, eventID)
+	if err != nil {
+		return err
+	}
+	if _, err := uc.eventTicketType(ctx, eventID, ticketTypeID); err != nil {
+		return err
+	}
+	if event.MaxCapacity == 0 {
+		ticketTypes, err := uc.ticketTypeRepo.GetByEventID(ctx, eventID)
+		if err != nil {
+			return err
+		}
+		if len(ticketTypes) <= 1 {
+			return ErrLastTicketType
+		}
+	}
+
+	registrations, err := uc.ticketTypeRepo.CountRegistrations(ctx, ticketTypeID)
+	if err != nil {
+		return err
+	}
+	if registrations > 0 {
+		return ErrTicketTypeInUse
+	}
+	return uc.ticketTypeRepo.Delete(ctx, ticketTypeID)
+}
+
+// GetTicketType returns a ticket type by ID.
+func (uc *TicketTypeUseCase) GetTicketType(ctx context.Context, id uint) (*entities.TicketType, error) {
+	ticketType, err := uc.ticketTypeRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTicketTypeNotFound
+		}
+		return nil, err
+	}
+	return ticketType, nil
+}
+
+// SelectTicketType picks the ticket type for a registration of the given
+// number of seats. Events without ticket types take no ticket type; otherwise
+// ticketTypeID is required, must be on sale and, when hidden, unlocked by
+// unlockCode. The seats must fit the ticket type's per-order limits; whether
+// they are still available, within both the ticket type's quantity and the
+// event's capacity, is checked when the registration is saved. Paid ticket
+// types are bought through an order.
+func (uc *TicketTypeUseCase) SelectTicketType(ctx context.Context, event *entities.Event, ticketTypeID *uint, unlockCode string, seats int, now time.Time) (*entities.TicketType, error) {
+	if ticketTypeID == nil {
+		ticketTypes, err := uc.ticketTypeRepo.GetByEventID(ctx, event.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(ticketTypes) > 0 {
+			return nil, ErrTicketTypeRequired
+		}
+		return nil, nil
+	}
+
+	ticketType, err := uc.eventTicketType(ctx, event.ID, *ticketTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if !unlocks(ticketType, unlockCode) {
+		return nil, ErrTicketTypeNotFound
+	}
+	if !ticketType.OnSale(now) {
+		return nil, fmt.Errorf("%w: %s", ErrTicketNotOnSale, ticketType.Name)
+	}
+	if err := checkTicketQuantity(ticketType, seats); err != nil {
+		return nil, err
+	}
+	return ticketType, nil
+}
+
+// checkTicketQuantity checks seats against the ticket type's per-order
+// limits.
+func checkTicketQuantity(ticketType *entities.TicketType, seats int) error {
+	if seats < ticketType.MinPerOrder {
+		return fmt.Errorf("%w: at least %d per order", ErrInvalidTicketQuantity, ticketType.MinPerOrder)
+	}
+	if ticketType.MaxPerOrder > 0 && seats > ticketType.MaxPerOrder {
+		return fmt.Errorf("%w: at most %d per order", ErrInvalidTicketQuantity, ticketType.MaxPerOrder)
+	}
+	return nil
+}
+
+func (uc *TicketTypeUseCase) getEvent(ctx context.Context, eventID uint) (*entities.Event, error) {
+	event, err := uc.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+func (uc *TicketTypeUseCase) authorizedEvent(ctx context.Context, userID, eventID uint) (*entities.Event, error) {
+	event, err := uc.getEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.authorizer.Authorize(ctx, event, userID, entities.PermissionEditEvent); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (uc *TicketTypeUseCase) eventTicketType(ctx context.Context, eventID, ticketTypeID uint) (*entities.TicketType, error) {
+	ticketType, err := uc.GetTicketType(ctx, ticketTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if ticketType.EventID != eventID {
+		return nil, ErrTicketTypeNotFound
+	}
+	return ticketType, nil
+}
+
+// applyTicketTypeRequest validates a ticket type definition against the
+// event's other ticket types and copies it over. Every ticket type of an
+// event uses the same currency, and when the event has no overall capacity
+// the ticket types together must fit its room.
+func (uc *TicketTypeUseCase) applyTicketTypeRequest(ctx context.Context, event *entities.Event, ticketType *entities.TicketType, req *entities.TicketTypeRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTicketType)
+	}
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = entities.DefaultCurrency
+	}
+	if req.SalesStartAt != nil && req.SalesEndAt != nil && !req.SalesEndAt.After(*req.SalesStartAt) {
+		return fmt.Errorf("%w: sales_end_at must be after sales_start_at", ErrInvalidTicketType)
+	}
+	minPerOrder := max(req.MinPerOrder, 1)
+	if minPerOrder > req.Quantity {
+		return fmt.Errorf("%w: min_per_order is larger than the quantity", ErrInvalidTicketType)
+	}
+	if req.MaxPerOrder > 0 && req.MaxPerOrder < minPerOrder {
+		return fmt.Errorf("%w: max_per_order is smaller than min_per_order", ErrInvalidTicketType)
+	}
+	unlockCode := strings.TrimSpace(req.UnlockCode)
+	if req.Hidden && unlockCode == "" {
+		return fmt.Errorf("%w: hidden ticket types need an unlock_code", ErrInvalidTicketType)
+	}
+	if !req.Hidden {
+		unlockCode = ""
+	}
+
+	others, err := uc.ticketTypeRepo.GetByEventID(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	total := req.Quantity
+	for _, other := range others {
+		if other.ID == ticketType.ID {
+			continue
+		}
+		if other.Currency != currency {
+			return fmt.Errorf("%w: the event's tickets are sold in %s", ErrInvalidTicketType, other.Currency)
+		}
+		total += other.Quantity
+	}
+
+	if ticketType.ID != 0 {
+		taken, err := uc.attendeeRepo.CountSeatsByTicketType(ctx, event.ID)
+		if err != nil {
+			return err
+		}
+		if req.Quantity < taken[ticketType.ID] {
+			return fmt.Errorf("%w: %d seats are already taken", ErrInvalidTicketType, taken[ticketType.ID])
+		}
+	}
+
+	if event.MaxCapacity == 0 && event.VenueID != nil && event.RoomID != nil {
+		room, err := uc.venues.GetRoom(ctx, *event.VenueID, *event.RoomID)
+		if err != nil {
+			return err
+		}
+		if total > room.Capacity {
+			return fmt.Errorf("%w: %q holds %d people", ErrRoomTooSmall, room.Name, room.Capacity)
+		}
+	}
+
+	ticketType.Name = name
+	ticketType.Description = req.Description
+	ticketType.Price = req.Price
+	ticketType.Currency = currency
+	ticketType.Quantity = req.Quantity
+	ticketType.SalesStartAt = req.SalesStartAt
+	ticketType.SalesEndAt = req.SalesEndAt
+	ticketType.MinPerOrder = minPerOrder
+	ticketType.MaxPerOrder = req.MaxPerOrder
+	ticketType.Hidden = req.Hidden
+	ticketType.UnlockCode = unlockCode
+	ticketType.Position = req.Position
+	return nil
+}
+
+// unlocks reports whether the ticket type is offered with unlockCode: visible
+// ticket types always are, hidden ones only with their code.
+func unlocks(ticketType *entities.TicketType, unlockCode string) bool {
+	if !ticketType.Hidden {
+		return true
+	}
+	code := strings.TrimSpace(unlockCode)
+	return code != "" && subtle.ConstantTimeCompare([]byte(code), []byte(ticketType.UnlockCode)) == 1
+}
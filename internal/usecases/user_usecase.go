@@ -0,0 +1,67 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type UserUseCase struct {
+	userRepo repositories.UserRepository
+}
+
+func NewUserUseCase(userRepo repositories.UserRepository) *UserUseCase {
+	return &UserUseCase{userRepo: userRepo}
+}
+
+func (uc *UserUseCase) GetPreferences(ctx context.Context, userID uint) (*entities.UserPreferencesResponse, error) {
+	user, err := uc.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.UserPreferencesResponse{
+		HideFromAttendeeLists: user.HideFromAttendeeLists,
+	}, nil
+}
+
+func (uc *UserUseCase) UpdatePreferences(ctx context.Context, userID uint, req *entities.UserPreferencesRequest) (*entities.UserPreferencesResponse, error) {
+	user, err := uc.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.HideFromAttendeeLists = *req.HideFromAttendeeLists
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &entities.UserPreferencesResponse{
+		HideFromAttendeeLists: user.HideFromAttendeeLists,
+	}, nil
+}
+
+// SeedAdmins makes the accounts with the given emails site administrators and
+// returns how many were promoted. Accounts created later are promoted the next
+// time it runs; it never revokes the flag.
+func (uc *UserUseCase) SeedAdmins(ctx context.Context, emails []string) (int64, error) {
+	if len(emails) == 0 {
+		return 0, nil
+	}
+	return uc.userRepo.GrantAdmin(ctx, emails)
+}
+
+func (uc *UserUseCase) getUser(ctx context.Context, userID uint) (*entities.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,37 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"EventsAPI/internal/domain/entities"
+)
+
+func TestSeedAdmins(t *testing.T) {
+	users := &memoryUserRepository{users: []*entities.User{
+		{ID: 1, Email: "Ada@Example.com"},
+		{ID: 2, Email: "grace@example.com", IsAdmin: true},
+		{ID: 3, Email: "alan@example.com"},
+	}}
+	uc := NewUserUseCase(users)
+
+	granted, err := uc.SeedAdmins(context.Background(), []string{"ada@example.com", "grace@example.com", "nobody@example.com"})
+	if err != nil {
+		t.Fatalf("SeedAdmins: %v", err)
+	}
+	if granted != 1 {
+		t.Errorf("granted = %d, want 1", granted)
+	}
+	for _, want := range []struct {
+		id    uint
+		admin bool
+	}{{1, true}, {2, true}, {3, false}} {
+		if user, _ := users.GetByID(context.Background(), want.id); user.IsAdmin != want.admin {
+			t.Errorf("user %d IsAdmin = %v, want %v", want.id, user.IsAdmin, want.admin)
+		}
+	}
+
+	if granted, err := uc.SeedAdmins(context.Background(), nil); err != nil || granted != 0 {
+		t.Errorf("SeedAdmins(nil) = %d, %v; want 0, nil", granted, err)
+	}
+}
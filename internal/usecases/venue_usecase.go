@@ -0,0 +1,184 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"EventsAPI/internal/domain/entities"
+	"EventsAPI/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrVenueNotFound = errors.New("venue not found")
+	ErrRoomNotFound  = errors.New("room not found")
+)
+
+// VenueUseCase manages venues and their rooms.
+type VenueUseCase struct {
+	venueRepo  repositories.VenueRepository
+	authorizer *EventAuthorizer
+}
+
+func NewVenueUseCase(venueRepo repositories.VenueRepository, authorizer *EventAuthorizer) *VenueUseCase {
+	return &VenueUseCase{venueRepo: venueRepo, authorizer: authorizer}
+}
+
+// CreateVenue creates a personal venue, or an organization venue when
+// req.OrganizationID is set and the user may manage its venues.
+func (uc *VenueUseCase) CreateVenue(ctx context.Context, userID uint, req *entities.VenueRequest) (*entities.Venue, error) {
+	if req.OrganizationID != nil {
+		err := uc.authorizer.AuthorizeInOrganization(ctx, *req.OrganizationID, userID, entities.PermissionManageVenues)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	venue := &entities.Venue{UserID: userID, OrganizationID: req.OrganizationID}
+	applyVenueRequest(venue, req)
+	if err := uc.venueRepo.Create(ctx, venue); err != nil {
+		return nil, err
+	}
+	return venue, nil
+}
+
+func (uc *VenueUseCase) ListVenues(ctx context.Context, limit, offset int) ([]*entities.Venue, error) {
+	return uc.venueRepo.List(ctx, limit, offset)
+}
+
+func (uc *VenueUseCase) GetVenue(ctx context.Context, id uint) (*entities.Venue, error) {
+	venue, err := uc.venueRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVenueNotFound
+		}
+		return nil, err
+	}
+	return venue, nil
+}
+
+// UpdateVenue applies req to the venue. Moving it to another organization
+// also requires permission to manage that organization's venues.
+func (uc *VenueUseCase) UpdateVenue(ctx context.Context, userID, id uint, req *entities.VenueRequest) (*entities.Venue, error) {
+	venue, err := uc.authorizedVenue(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.OrganizationID != nil && (venue.OrganizationID == nil || *venue.OrganizationID != *req.OrganizationID) {
+		err := uc.authorizer.AuthorizeInOrganization(ctx, *req.OrganizationID, userID, entities.PermissionManageVenues)
+		if err != nil {
+			return nil, err
+		}
+		venue.OrganizationID = req.OrganizationID
+	}
+
+	applyVenueRequest(venue, req)
+	if err := uc.venueRepo.Update(ctx, venue); err != nil {
+		return nil, err
+	}
+	return venue, nil
+}
+
+// DeleteVenue removes the venue and its rooms. Events held there keep their
+// location text.
+func (uc *VenueUseCase) DeleteVenue(ctx context.Context, userID, id uint) error {
+	if _, err := uc.authorizedVenue(ctx, userID, id); err != nil {
+		return err
+	}
+	return uc.venueRepo.Delete(ctx, id)
+}
+
+func (uc *VenueUseCase) AddRoom(ctx context.Context, userID, venueID uint, req *entities.VenueRoomRequest) (*entities.VenueRoom, error) {
+	if _, err := uc.authorizedVenue(ctx, userID, venueID); err != nil {
+		return nil, err
+	}
+
+	room := &entities.VenueRoom{VenueID: venueID}
+	applyVenueRoomRequest(room, req)
+	if err := uc.venueRepo.CreateRoom(ctx, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+func (uc *VenueUseCase) UpdateRoom(ctx context.Context, userID, venueID, roomID uint, req *entities.VenueRoomRequest) (*entities.VenueRoom, error) {
+	room, err := uc.authorizedRoom(ctx, userID, venueID, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyVenueRoomRequest(room, req)
+	if err := uc.venueRepo.UpdateRoom(ctx, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+func (uc *VenueUseCase) DeleteRoom(ctx context.Context, userID, venueID, roomID uint) error {
+	if _, err := uc.authorizedRoom(ctx, userID, venueID, roomID); err != nil {
+		return err
+	}
+	return uc.venueRepo.DeleteRoom(ctx, roomID)
+}
+
+// GetRoom returns the venue's room, or ErrRoomNotFound when it belongs to
+// another venue.
+func (uc *VenueUseCase) GetRoom(ctx context.Context, venueID, roomID uint) (*entities.VenueRoom, error) {
+	room, err := uc.venueRepo.GetRoom(ctx, roomID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoomNotFound
+		}
+		return nil, err
+	}
+	if room.VenueID != venueID {
+		return nil, ErrRoomNotFound
+	}
+	return room, nil
+}
+
+// authorizedVenue loads the venue and checks that the user may manage it.
+func (uc *VenueUseCase) authorizedVenue(ctx context.Context, userID, id uint) (*entities.Venue, error) {
+	venue, err := uc.GetVenue(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if venue.OrganizationID == nil {
+		if venue.UserID != userID {
+			return nil, ErrForbidden
+		}
+		return venue, nil
+	}
+
+	err = uc.authorizer.AuthorizeInOrganization(ctx, *venue.OrganizationID, userID, entities.PermissionManageVenues)
+	if err != nil {
+		return nil, err
+	}
+	return venue, nil
+}
+
+func (uc *VenueUseCase) authorizedRoom(ctx context.Context, userID, venueID, roomID uint) (*entities.VenueRoom, error) {
+	if _, err := uc.authorizedVenue(ctx, userID, venueID); err != nil {
+		return nil, err
+	}
+	return uc.GetRoom(ctx, venueID, roomID)
+}
+
+func applyVenueRequest(venue *entities.Venue, req *entities.VenueRequest) {
+	venue.Name = req.Name
+	venue.Address = req.Address
+	venue.City = req.City
+	venue.Country = req.Country
+	venue.Latitude = req.Latitude
+	venue.Longitude = req.Longitude
+	venue.AccessibilityInfo = req.AccessibilityInfo
+}
+
+func applyVenueRoomRequest(room *entities.VenueRoom, req *entities.VenueRoomRequest) {
+	room.Name = req.Name
+	room.Capacity = req.Capacity
+	room.AccessibilityInfo = req.AccessibilityInfo
+}
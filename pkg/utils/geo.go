@@ -0,0 +1,66 @@
+package utils
+
+import "math"
+
+// EarthRadiusKm is the mean Earth radius used for distances.
+const EarthRadiusKm = 6371.0
+
+// GeoBounds is a latitude/longitude box. When MinLng is greater than MaxLng
+// the box crosses the antimeridian and covers longitudes >= MinLng or <=
+// MaxLng.
+type GeoBounds struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+// CrossesAntimeridian reports whether the box wraps around longitude ±180.
+func (b GeoBounds) CrossesAntimeridian() bool {
+	return b.MinLng > b.MaxLng
+}
+
+// HaversineKm returns the great-circle distance between two points.
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLng := radians(lng2 - lng1)
+	a := math.Pow(math.Sin(dLat/2), 2) + math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BoundingBox returns a box containing every point within radiusKm of the
+// given point, to prefilter candidates before computing exact distances. Boxes
+// reaching a pole span every longitude; boxes crossing the antimeridian wrap.
+func BoundingBox(lat, lng, radiusKm float64) GeoBounds {
+	angular := radiusKm / EarthRadiusKm
+	latDelta := degrees(angular)
+
+	bounds := GeoBounds{MinLat: lat - latDelta, MaxLat: lat + latDelta, MinLng: -180, MaxLng: 180}
+	if bounds.MinLat <= -90 || bounds.MaxLat >= 90 {
+		bounds.MinLat = math.Max(bounds.MinLat, -90)
+		bounds.MaxLat = math.Min(bounds.MaxLat, 90)
+		return bounds
+	}
+
+	ratio := math.Sin(angular) / math.Cos(radians(lat))
+	if ratio >= 1 || angular >= math.Pi/2 {
+		return bounds
+	}
+
+	lngDelta := degrees(math.Asin(ratio))
+	bounds.MinLng = lng - lngDelta
+	bounds.MaxLng = lng + lngDelta
+	if bounds.MinLng < -180 {
+		bounds.MinLng += 360
+	}
+	if bounds.MaxLng > 180 {
+		bounds.MaxLng -= 360
+	}
+	return bounds
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
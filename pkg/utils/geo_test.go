@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{"same point", 40.4168, -3.7038, 40.4168, -3.7038, 0},
+		{"Paris to London", 48.8566, 2.3522, 51.5074, -0.1278, 343.56},
+		{"across the antimeridian", 0, 179, 0, -179, 222.39},
+		{"equator to pole", 0, 0, 90, 0, 10007.54},
+		{"antipodes", 0, 0, 0, 180, 20015.09},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HaversineKm(tt.lat1, tt.lng1, tt.lat2, tt.lng2); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("HaversineKm = %.2f, want %.2f", got, tt.want)
+			}
+			if got := HaversineKm(tt.lat2, tt.lng2, tt.lat1, tt.lng1); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("reversed HaversineKm = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	// oneDegreeKm is the length of one degree of a great circle.
+	const oneDegreeKm = math.Pi * EarthRadiusKm / 180
+
+	tests := []struct {
+		name         string
+		lat, lng     float64
+		radiusKm     float64
+		want         GeoBounds
+		wantCrossing bool
+	}{
+		{
+			name: "regular box", lat: 0, lng: 10, radiusKm: oneDegreeKm,
+			want: GeoBounds{MinLat: -1, MaxLat: 1, MinLng: 9, MaxLng: 11},
+		},
+		{
+			name: "east of the antimeridian", lat: 0, lng: 179.5, radiusKm: oneDegreeKm,
+			want:         GeoBounds{MinLat: -1, MaxLat: 1, MinLng: 178.5, MaxLng: -179.5},
+			wantCrossing: true,
+		},
+		{
+			name: "west of the antimeridian", lat: 0, lng: -179.5, radiusKm: oneDegreeKm,
+			want:         GeoBounds{MinLat: -1, MaxLat: 1, MinLng: 179.5, MaxLng: -178.5},
+			wantCrossing: true,
+		},
+		{
+			name: "reaching the north pole", lat: 89.5, lng: 45, radiusKm: oneDegreeKm,
+			want: GeoBounds{MinLat: 88.5, MaxLat: 90, MinLng: -180, MaxLng: 180},
+		},
+		{
+			name: "reaching the south pole", lat: -89.9, lng: -120, radiusKm: oneDegreeKm,
+			want: GeoBounds{MinLat: -90, MaxLat: -88.9, MinLng: -180, MaxLng: 180},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BoundingBox(tt.lat, tt.lng, tt.radiusKm)
+			if !closeBounds(got, tt.want) {
+				t.Errorf("BoundingBox = %+v, want %+v", got, tt.want)
+			}
+			if got.CrossesAntimeridian() != tt.wantCrossing {
+				t.Errorf("CrossesAntimeridian = %v, want %v", got.CrossesAntimeridian(), tt.wantCrossing)
+			}
+			if got.MinLat < -90 || got.MaxLat > 90 {
+				t.Errorf("latitudes %v..%v are not clamped to the poles", got.MinLat, got.MaxLat)
+			}
+
+			// Every point on the circle of the radius must fall in the box.
+			for bearing := 0.0; bearing < 360; bearing += 5 {
+				lat, lng := destination(tt.lat, tt.lng, bearing, tt.radiusKm*0.999)
+				if !containsPoint(got, lat, lng) {
+					t.Errorf("point (%.4f, %.4f) at bearing %v is outside %+v", lat, lng, bearing, got)
+				}
+			}
+		})
+	}
+}
+
+func closeBounds(a, b GeoBounds) bool {
+	const epsilon = 1e-9
+	return math.Abs(a.MinLat-b.MinLat) < epsilon && math.Abs(a.MaxLat-b.MaxLat) < epsilon &&
+		math.Abs(a.MinLng-b.MinLng) < epsilon && math.Abs(a.MaxLng-b.MaxLng) < epsilon
+}
+
+func containsPoint(b GeoBounds, lat, lng float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return lng >= b.MinLng || lng <= b.MaxLng
+	}
+	return lng >= b.MinLng && lng <= b.MaxLng
+}
+
+// destination returns the point distanceKm away from the given one along the
+// initial bearing, in degrees clockwise from north.
+func destination(lat, lng, bearing, distanceKm float64) (float64, float64) {
+	angular := distanceKm / EarthRadiusKm
+	lat1, lng1, theta := radians(lat), radians(lng), radians(bearing)
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(angular) + math.Cos(lat1)*math.Sin(angular)*math.Cos(theta))
+	lng2 := lng1 + math.Atan2(math.Sin(theta)*math.Sin(angular)*math.Cos(lat1), math.Cos(angular)-math.Sin(lat1)*math.Sin(lat2))
+	return degrees(lat2), math.Remainder(degrees(lng2), 360)
+}
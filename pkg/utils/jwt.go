@@ -1,7 +1,16 @@
 package utils
 
 import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -13,34 +22,220 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID uint, email, secret string, expiration time.Duration) (string, error) {
+// JWTKey is an asymmetric key identified by its "kid". Verification-only keys
+// (e.g. keys that are being rotated out) have a nil PrivateKey.
+type JWTKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// LoadJWTKey reads a PEM encoded RSA or Ed25519 key (private or public) from disk.
+func LoadJWTKey(id, path string) (*JWTKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading JWT key %q: %w", id, err)
+	}
+	return ParseJWTKey(id, data)
+}
+
+// ParseJWTKey parses a PEM encoded RSA or Ed25519 key. The signing algorithm is
+// derived from the key type: RS256 for RSA and EdDSA for Ed25519.
+func ParseJWTKey(id string, pemData []byte) (*JWTKey, error) {
+	if id == "" {
+		return nil, errors.New("JWT key id is required")
+	}
+
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("JWT key %q is not PEM encoded", id)
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("JWT key %q has unsupported PEM type %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing JWT key %q: %w", id, err)
+	}
+
+	return NewJWTKey(id, key)
+}
+
+// NewJWTKey wraps an already parsed RSA or Ed25519 key.
+func NewJWTKey(id string, key any) (*JWTKey, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &JWTKey{ID: id, Method: jwt.SigningMethodRS256, PrivateKey: k, PublicKey: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &JWTKey{ID: id, Method: jwt.SigningMethodRS256, PublicKey: k}, nil
+	case ed25519.PrivateKey:
+		return &JWTKey{ID: id, Method: jwt.SigningMethodEdDSA, PrivateKey: k, PublicKey: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &JWTKey{ID: id, Method: jwt.SigningMethodEdDSA, PublicKey: k}, nil
+	default:
+		return nil, fmt.Errorf("JWT key %q has unsupported type %T", id, key)
+	}
+}
+
+type JWTOptions struct {
+	// SigningKey signs new tokens and must hold a private key.
+	SigningKey *JWTKey
+	// VerificationKeys are additional keys accepted when validating tokens,
+	// typically the previous signing keys during a rotation.
+	VerificationKeys []*JWTKey
+	// Algorithms pins the accepted "alg" header values. Defaults to the
+	// algorithms of the configured keys.
+	Algorithms []string
+	Issuer     string
+	Audience   []string
+	Expiration time.Duration
+}
+
+// JWTManager issues and validates the API access tokens.
+type JWTManager struct {
+	signingKey *JWTKey
+	keys       map[string]*JWTKey
+	keyOrder   []string
+	algorithms []string
+	issuer     string
+	audience   []string
+	expiration time.Duration
+}
+
+func NewJWTManager(opts JWTOptions) (*JWTManager, error) {
+	if opts.SigningKey == nil || opts.SigningKey.PrivateKey == nil {
+		return nil, errors.New("a private JWT signing key is required")
+	}
+
+	m := &JWTManager{
+		signingKey: opts.SigningKey,
+		keys:       make(map[string]*JWTKey),
+		issuer:     opts.Issuer,
+		audience:   opts.Audience,
+		expiration: opts.Expiration,
+	}
+
+	for _, key := range append([]*JWTKey{opts.SigningKey}, opts.VerificationKeys...) {
+		if _, exists := m.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate JWT key id %q", key.ID)
+		}
+		m.keys[key.ID] = key
+		m.keyOrder = append(m.keyOrder, key.ID)
+		if len(opts.Algorithms) == 0 && !slices.Contains(m.algorithms, key.Method.Alg()) {
+			m.algorithms = append(m.algorithms, key.Method.Alg())
+		}
+	}
+
+	if len(opts.Algorithms) > 0 {
+		m.algorithms = opts.Algorithms
+	}
+	for _, key := range m.keys {
+		if !slices.Contains(m.algorithms, key.Method.Alg()) {
+			return nil, fmt.Errorf("JWT key %q uses algorithm %s which is not allowed", key.ID, key.Method.Alg())
+		}
+	}
+
+	return m, nil
+}
+
+func (m *JWTManager) GenerateJWT(userID uint, email string) (string, error) {
+	now := time.Now()
 	claims := &Claims{
 		UserID: userID,
 		Email:  email,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    m.issuer,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			Audience:  m.audience,
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.expiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return m.Sign(claims)
+}
+
+// Sign signs arbitrary claims with the active signing key, setting the "kid"
+// header so verifiers can pick the right key from the JWKS.
+func (m *JWTManager) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(m.signingKey.Method, claims)
+	token.Header["kid"] = m.signingKey.ID
+	return token.SignedString(m.signingKey.PrivateKey)
 }
 
-func ValidateJWT(tokenString, secret string) (*Claims, error) {
+func (m *JWTManager) ValidateJWT(tokenString string) (*Claims, error) {
+	opts := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if m.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(m.issuer))
+	}
+	if len(m.audience) > 0 {
+		opts = append(opts, jwt.WithAudience(m.audience...))
+	}
+
 	claims := &Claims{}
+	if err := m.Parse(tokenString, claims, opts...); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+// Parse verifies the signature of tokenString against the configured keys,
+// pinning the allowed algorithms. Further claim checks are given as opts.
+func (m *JWTManager) Parse(tokenString string, claims jwt.Claims, opts ...jwt.ParserOption) error {
+	opts = append(opts, jwt.WithValidMethods(m.algorithms))
 
+	token, err := jwt.ParseWithClaims(tokenString, claims, m.keyFunc, opts...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if !token.Valid {
-		return nil, errors.New("invalid token")
+		return errors.New("invalid token")
 	}
 
-	return claims, nil
+	return nil
+}
+
+func (m *JWTManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("token has no kid header")
+	}
+
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	// A key is only valid for the algorithm it was created for.
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("signing method %s does not match key %q", token.Method.Alg(), kid)
+	}
+
+	return key.PublicKey, nil
+}
+
+// JWKS returns the public part of every active key.
+func (m *JWTManager) JWKS() (*JWKSet, error) {
+	set := &JWKSet{Keys: make([]JWK, 0, len(m.keyOrder))}
+	for _, kid := range m.keyOrder {
+		jwk, err := NewJWK(m.keys[kid])
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, *jwk)
+	}
+	return set, nil
 }
@@ -0,0 +1,303 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	rsaKeyOnce sync.Once
+	rsaKey     *rsa.PrivateKey
+)
+
+// testRSAKey returns an RSA key shared by the tests, as generating one is
+// slow.
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	rsaKeyOnce.Do(func() {
+		var err error
+		if rsaKey, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return rsaKey
+}
+
+func testEd25519Key(t *testing.T, id string) *JWTKey {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewJWTKey(id, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func testManager(t *testing.T, opts JWTOptions) *JWTManager {
+	t.Helper()
+	if opts.Issuer == "" {
+		opts.Issuer = "https://api.example.com"
+	}
+	if opts.Audience == nil {
+		opts.Audience = []string{"events-api"}
+	}
+	if opts.Expiration == 0 {
+		opts.Expiration = time.Hour
+	}
+	manager, err := NewJWTManager(opts)
+	if err != nil {
+		t.Fatalf("NewJWTManager: %v", err)
+	}
+	return manager
+}
+
+// publicOnly parses the public half of key from PEM, the way verification
+// keys listed in JWT_VERIFICATION_KEYS are loaded.
+func publicOnly(t *testing.T, key *JWTKey) *JWTKey {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := ParseJWTKey(key.ID, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParseJWTKey: %v", err)
+	}
+	if public.PrivateKey != nil {
+		t.Fatal("a public key was parsed with a private key")
+	}
+	return public
+}
+
+func TestJWTManagerRoundTrip(t *testing.T) {
+	rsaSigning, err := NewJWTKey("rsa-1", testRSAKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []*JWTKey{rsaSigning, testEd25519Key(t, "ed-1")} {
+		t.Run(key.Method.Alg(), func(t *testing.T) {
+			manager := testManager(t, JWTOptions{SigningKey: key})
+			token, err := manager.GenerateJWT(42, "ada@example.com")
+			if err != nil {
+				t.Fatalf("GenerateJWT: %v", err)
+			}
+
+			claims, err := manager.ValidateJWT(token)
+			if err != nil {
+				t.Fatalf("ValidateJWT: %v", err)
+			}
+			if claims.UserID != 42 || claims.Email != "ada@example.com" || claims.Subject != "42" {
+				t.Errorf("claims = %+v, want user 42 <ada@example.com>", claims)
+			}
+		})
+	}
+}
+
+func TestJWTManagerKeyRotation(t *testing.T) {
+	oldKey := testEd25519Key(t, "2024")
+	newKey := testEd25519Key(t, "2025")
+	oldToken, err := testManager(t, JWTOptions{SigningKey: oldKey}).GenerateJWT(1, "ada@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := testManager(t, JWTOptions{SigningKey: newKey, VerificationKeys: []*JWTKey{publicOnly(t, oldKey)}})
+	if _, err := rotated.ValidateJWT(oldToken); err != nil {
+		t.Errorf("a token signed with the retired key was rejected: %v", err)
+	}
+	newToken, err := rotated.GenerateJWT(1, "ada@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header := strings.Split(newToken, ".")[0]; !strings.Contains(decodeSegment(t, header), `"kid":"2025"`) {
+		t.Errorf("new tokens are not signed with the new key: header %s", decodeSegment(t, header))
+	}
+
+	jwks, err := rotated.JWKS()
+	if err != nil {
+		t.Fatalf("JWKS: %v", err)
+	}
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "2025" || jwks.Keys[1].Kid != "2024" {
+		t.Errorf("JWKS = %+v, want the signing key then the retired one", jwks.Keys)
+	}
+
+	dropped := testManager(t, JWTOptions{SigningKey: newKey})
+	if _, err := dropped.ValidateJWT(oldToken); err == nil {
+		t.Error("a token signed with a key no longer listed was accepted")
+	}
+}
+
+func TestJWTManagerRejects(t *testing.T) {
+	signing := testEd25519Key(t, "ed-1")
+	rsaVerification, err := NewJWTKey("rsa-1", testRSAKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager := testManager(t, JWTOptions{SigningKey: signing, VerificationKeys: []*JWTKey{rsaVerification}})
+
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"user_id": 1,
+			"iss":     "https://api.example.com",
+			"aud":     "events-api",
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	sign := func(method jwt.SigningMethod, kid any, claims jwt.MapClaims, key any) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, claims)
+		if kid != nil {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	with := func(name string, value any) jwt.MapClaims {
+		c := claims()
+		if value == nil {
+			delete(c, name)
+		} else {
+			c[name] = value
+		}
+		return c
+	}
+	otherKey := testEd25519Key(t, "ed-1")
+	publicDER, err := x509.MarshalPKIXPublicKey(signing.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"none algorithm", sign(jwt.SigningMethodNone, "ed-1", claims(), jwt.UnsafeAllowNoneSignatureType)},
+		{"HS256 keyed with the public key", sign(jwt.SigningMethodHS256, "ed-1", claims(), publicDER)},
+		{"HS256 with an unknown kid", sign(jwt.SigningMethodHS256, "secret", claims(), []byte("secret"))},
+		{"missing kid", sign(signing.Method, nil, claims(), signing.PrivateKey)},
+		{"non-string kid", sign(signing.Method, 1, claims(), signing.PrivateKey)},
+		{"unknown kid", sign(signing.Method, "ed-2", claims(), signing.PrivateKey)},
+		{"algorithm of another key", sign(jwt.SigningMethodRS256, "ed-1", claims(), testRSAKey(t))},
+		{"signed by another key with the same kid", sign(otherKey.Method, "ed-1", claims(), otherKey.PrivateKey)},
+		{"wrong issuer", sign(signing.Method, "ed-1", with("iss", "https://evil.example.com"), signing.PrivateKey)},
+		{"missing issuer", sign(signing.Method, "ed-1", with("iss", nil), signing.PrivateKey)},
+		{"wrong audience", sign(signing.Method, "ed-1", with("aud", "another-api"), signing.PrivateKey)},
+		{"missing audience", sign(signing.Method, "ed-1", with("aud", nil), signing.PrivateKey)},
+		{"expired", sign(signing.Method, "ed-1", with("exp", time.Now().Add(-time.Minute).Unix()), signing.PrivateKey)},
+		{"missing expiry", sign(signing.Method, "ed-1", with("exp", nil), signing.PrivateKey)},
+		{"malformed", "not-a-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if claims, err := manager.ValidateJWT(tt.token); err == nil {
+				t.Errorf("ValidateJWT accepted the token with claims %+v", claims)
+			}
+		})
+	}
+
+	// The RSA verification key is accepted with its own algorithm.
+	if _, err := manager.ValidateJWT(sign(jwt.SigningMethodRS256, "rsa-1", claims(), testRSAKey(t))); err != nil {
+		t.Errorf("a token signed with the RSA verification key was rejected: %v", err)
+	}
+}
+
+func TestJWTManagerPinnedAlgorithms(t *testing.T) {
+	signing := testEd25519Key(t, "ed-1")
+	rsaKey, err := NewJWTKey("rsa-1", testRSAKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewJWTManager(JWTOptions{SigningKey: signing, VerificationKeys: []*JWTKey{rsaKey}, Algorithms: []string{"EdDSA"}})
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("error = %v, want the RSA key refused by the pinned algorithms", err)
+	}
+}
+
+func TestNewJWTManagerErrors(t *testing.T) {
+	signing := testEd25519Key(t, "ed-1")
+	tests := []struct {
+		name string
+		opts JWTOptions
+	}{
+		{"no signing key", JWTOptions{}},
+		{"public signing key", JWTOptions{SigningKey: publicOnly(t, signing)}},
+		{"duplicate kid", JWTOptions{SigningKey: signing, VerificationKeys: []*JWTKey{testEd25519Key(t, "ed-1")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewJWTManager(tt.opts); err == nil {
+				t.Error("NewJWTManager succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestParseJWTKey(t *testing.T) {
+	rsaPrivate := testRSAKey(t)
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		block       *pem.Block
+		wantAlg     string
+		wantPrivate bool
+	}{
+		{"RSA PKCS#1 private key", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaPrivate)}, "RS256", true},
+		{"RSA PKCS#1 public key", &pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaPrivate.PublicKey)}, "RS256", false},
+		{"Ed25519 PKCS#8 private key", &pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}, "EdDSA", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseJWTKey("key", pem.EncodeToMemory(tt.block))
+			if err != nil {
+				t.Fatalf("ParseJWTKey: %v", err)
+			}
+			if key.Method.Alg() != tt.wantAlg || (key.PrivateKey != nil) != tt.wantPrivate {
+				t.Errorf("key = %s private=%v, want %s private=%v", key.Method.Alg(), key.PrivateKey != nil, tt.wantAlg, tt.wantPrivate)
+			}
+		})
+	}
+
+	for name, data := range map[string][]byte{
+		"not PEM":          []byte("secret"),
+		"unsupported type": pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte{1}}),
+	} {
+		if _, err := ParseJWTKey("key", data); err == nil {
+			t.Errorf("%s: ParseJWTKey succeeded, want an error", name)
+		}
+	}
+	if _, err := ParseJWTKey("", pem.EncodeToMemory(tests[0].block)); err == nil {
+		t.Error("ParseJWTKey accepted a key without id")
+	}
+}
+
+func decodeSegment(t *testing.T, segment string) string {
+	t.Helper()
+	data, err := jwt.NewParser().DecodeSegment(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}